@@ -0,0 +1,101 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	iconfig "github.com/luids-io/archive/internal/config"
+	ifactory "github.com/luids-io/archive/internal/factory"
+	"github.com/luids-io/archive/pkg/archive"
+)
+
+// checkConfig validates definitions and references between grpc services
+// and archive services without connecting to backends.
+func checkConfig() []error {
+	errs := make([]error, 0)
+	cfgArchive := cfg.Data("archive").(*iconfig.ArchiverCfg)
+	backends, services, err := ifactory.LoadDefs(cfgArchive)
+	if err != nil {
+		return append(errs, err)
+	}
+	err = archive.ValidateDefs(backends, services)
+	if merr, ok := err.(archive.MultiError); ok {
+		for _, e := range merr {
+			errs = append(errs, e)
+		}
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+	apis := archive.ServiceAPIs(services)
+	eventArchive := cfg.Data("service.event.archive").(*iconfig.APICfg)
+	dnsArchive := cfg.Data("service.dnsutil.archive").(*iconfig.APICfg)
+	tlsArchive := cfg.Data("service.tlsutil.archive").(*iconfig.APICfg)
+	dnsFinder := cfg.Data("service.dnsutil.finder").(*iconfig.APICfg)
+	eventFinder := cfg.Data("service.event.finder").(*iconfig.APICfg)
+	tlsFinder := cfg.Data("service.tlsutil.finder").(*iconfig.APICfg)
+	dnsStats := cfg.Data("service.dnsutil.stats").(*iconfig.APICfg)
+	passiveFinder := cfg.Data("service.dnsutil.passivedns").(*iconfig.APICfg)
+	queryFinder := cfg.Data("service.dnsutil.query").(*iconfig.APICfg)
+	sections := []struct {
+		name    string
+		enable  bool
+		service string
+		api     archive.API
+	}{
+		{"service.event.archive", eventArchive.Enable, eventArchive.Service, archive.EventAPI},
+		{"service.dnsutil.archive", dnsArchive.Enable, dnsArchive.Service, archive.DNSAPI},
+		{"service.tlsutil.archive", tlsArchive.Enable, tlsArchive.Service, archive.TLSAPI},
+		{"service.dnsutil.finder", dnsFinder.Enable, dnsFinder.Service, archive.DNSAPI},
+		{"service.event.finder", eventFinder.Enable, eventFinder.Service, archive.EventAPI},
+		{"service.tlsutil.finder", tlsFinder.Enable, tlsFinder.Service, archive.TLSAPI},
+		{"service.dnsutil.stats", dnsStats.Enable, dnsStats.Service, archive.DNSAPI},
+		{"service.dnsutil.passivedns", passiveFinder.Enable, passiveFinder.Service, archive.DNSAPI},
+		{"service.dnsutil.query", queryFinder.Enable, queryFinder.Service, archive.DNSAPI},
+	}
+	for _, s := range sections {
+		if !s.enable {
+			continue
+		}
+		if s.service == "" {
+			errs = append(errs, fmt.Errorf("%s: service id is empty", s.name))
+			continue
+		}
+		implements, ok := apis[s.service]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: can't find service with id '%s'", s.name, s.service))
+			continue
+		}
+		if !hasAPI(implements, s.api) {
+			errs = append(errs, fmt.Errorf("%s: service '%s' don't implements api", s.name, s.service))
+		}
+	}
+	return errs
+}
+
+func hasAPI(apis []archive.API, api archive.API) bool {
+	for _, v := range apis {
+		if v == api {
+			return true
+		}
+	}
+	return false
+}
+
+// printSchema outputs the json schema of definition files.
+func printSchema(name string) error {
+	var schema map[string]interface{}
+	switch name {
+	case "backends":
+		schema = archive.BackendsSchema()
+	case "services":
+		schema = archive.ServicesSchema()
+	default:
+		return fmt.Errorf("invalid schema '%s': backends or services expected", name)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
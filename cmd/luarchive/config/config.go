@@ -23,25 +23,55 @@ func Default(program string) *goconfig.Config {
 			Name:     "service.event.archive",
 			Required: false,
 			Short:    false,
-			Data:     &iconfig.ArchiveEventAPICfg{Log: true},
+			Data:     &iconfig.APICfg{Name: "event archive", Log: true},
 		},
 		goconfig.Section{
 			Name:     "service.dnsutil.archive",
 			Required: false,
 			Short:    false,
-			Data:     &iconfig.ArchiveDNSAPICfg{Log: true},
+			Data:     &iconfig.APICfg{Name: "dns archive", Log: true},
 		},
 		goconfig.Section{
 			Name:     "service.tlsutil.archive",
 			Required: false,
 			Short:    false,
-			Data:     &iconfig.ArchiveTLSAPICfg{Log: true},
+			Data:     &iconfig.APICfg{Name: "tls archive", Log: true},
 		},
 		goconfig.Section{
 			Name:     "service.dnsutil.finder",
 			Required: false,
 			Short:    false,
-			Data:     &iconfig.FinderDNSAPICfg{Log: true},
+			Data:     &iconfig.APICfg{Name: "dns finder", Log: true},
+		},
+		goconfig.Section{
+			Name:     "service.event.finder",
+			Required: false,
+			Short:    false,
+			Data:     &iconfig.APICfg{Name: "event finder", Log: true},
+		},
+		goconfig.Section{
+			Name:     "service.tlsutil.finder",
+			Required: false,
+			Short:    false,
+			Data:     &iconfig.APICfg{Name: "tls finder", Log: true},
+		},
+		goconfig.Section{
+			Name:     "service.dnsutil.stats",
+			Required: false,
+			Short:    false,
+			Data:     &iconfig.APICfg{Name: "dns stats", Log: true},
+		},
+		goconfig.Section{
+			Name:     "service.dnsutil.passivedns",
+			Required: false,
+			Short:    false,
+			Data:     &iconfig.APICfg{Name: "passive dns finder", Log: true},
+		},
+		goconfig.Section{
+			Name:     "service.dnsutil.query",
+			Required: false,
+			Short:    false,
+			Data:     &iconfig.APICfg{Name: "dns query finder", Log: true},
 		},
 		goconfig.Section{
 			Name:     "server",
@@ -73,7 +103,12 @@ func Default(program string) *goconfig.Config {
 		noDNSA := cfg.Data("service.dnsutil.archive").Empty()
 		noTLSA := cfg.Data("service.tlsutil.archive").Empty()
 		noDNSF := cfg.Data("service.dnsutil.finder").Empty()
-		if noEventA && noDNSA && noTLSA && noDNSF {
+		noEventF := cfg.Data("service.event.finder").Empty()
+		noTLSF := cfg.Data("service.tlsutil.finder").Empty()
+		noDNSS := cfg.Data("service.dnsutil.stats").Empty()
+		noPDNSF := cfg.Data("service.dnsutil.passivedns").Empty()
+		noQDNSF := cfg.Data("service.dnsutil.query").Empty()
+		if noEventA && noDNSA && noTLSA && noDNSF && noEventF && noTLSF && noDNSS && noPDNSF && noQDNSF {
 			return errors.New("enable service is required")
 		}
 		return nil
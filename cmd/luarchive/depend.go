@@ -14,6 +14,11 @@ import (
 	tlsarchive "github.com/luids-io/api/tlsutil/grpc/archive"
 	iconfig "github.com/luids-io/archive/internal/config"
 	ifactory "github.com/luids-io/archive/internal/factory"
+	queryfinder "github.com/luids-io/archive/pkg/dnsquery/grpc/finder"
+	dnsstats "github.com/luids-io/archive/pkg/dnsstats/grpc/stats"
+	eventfinder "github.com/luids-io/archive/pkg/eventfinder/grpc/finder"
+	passivefinder "github.com/luids-io/archive/pkg/passivedns/grpc/finder"
+	tlsfinder "github.com/luids-io/archive/pkg/tlsfinder/grpc/finder"
 	"github.com/luids-io/archive/pkg/archive"
 	cconfig "github.com/luids-io/common/config"
 	cfactory "github.com/luids-io/common/factory"
@@ -81,12 +86,13 @@ func createArchivers(msrv *serverd.Manager, logger yalogi.Logger) (*archive.Buil
 		Start:    builder.Start,
 		Shutdown: func() { builder.Shutdown() },
 		Ping:     func() error { return builder.PingAll() },
+		Reload:   func() error { return ifactory.Reload(cfgArchive, builder, logger) },
 	})
 	return builder, nil
 }
 
 func createArchiveEventAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
-	cfgArchive := cfg.Data("service.event.archive").(*iconfig.ArchiveEventAPICfg)
+	cfgArchive := cfg.Data("service.event.archive").(*iconfig.APICfg)
 	if cfgArchive.Enable {
 		gsvc, err := ifactory.ArchiveEventAPI(cfgArchive, finder, logger)
 		if err != nil {
@@ -99,7 +105,7 @@ func createArchiveEventAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *ser
 }
 
 func createArchiveDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
-	cfgArchive := cfg.Data("service.dnsutil.archive").(*iconfig.ArchiveDNSAPICfg)
+	cfgArchive := cfg.Data("service.dnsutil.archive").(*iconfig.APICfg)
 	if cfgArchive.Enable {
 		gsvc, err := ifactory.ArchiveDNSAPI(cfgArchive, finder, logger)
 		if err != nil {
@@ -112,7 +118,7 @@ func createArchiveDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serve
 }
 
 func createArchiveTLSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
-	cfgArchive := cfg.Data("service.tlsutil.archive").(*iconfig.ArchiveTLSAPICfg)
+	cfgArchive := cfg.Data("service.tlsutil.archive").(*iconfig.APICfg)
 	if cfgArchive.Enable {
 		gsvc, err := ifactory.ArchiveTLSAPI(cfgArchive, finder, logger)
 		if err != nil {
@@ -125,7 +131,7 @@ func createArchiveTLSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serve
 }
 
 func createFinderDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
-	cfgFinder := cfg.Data("service.dnsutil.finder").(*iconfig.FinderDNSAPICfg)
+	cfgFinder := cfg.Data("service.dnsutil.finder").(*iconfig.APICfg)
 	if cfgFinder.Enable {
 		gsvc, err := ifactory.FinderDNSAPI(cfgFinder, finder, logger)
 		if err != nil {
@@ -136,3 +142,68 @@ func createFinderDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *server
 	}
 	return nil
 }
+
+func createFinderEventAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
+	cfgFinder := cfg.Data("service.event.finder").(*iconfig.APICfg)
+	if cfgFinder.Enable {
+		gsvc, err := ifactory.FinderEventAPI(cfgFinder, finder, logger)
+		if err != nil {
+			return err
+		}
+		eventfinder.RegisterServer(gsrv, gsvc)
+		msrv.Register(serverd.Service{Name: "service.event.finder"})
+	}
+	return nil
+}
+
+func createFinderTLSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
+	cfgFinder := cfg.Data("service.tlsutil.finder").(*iconfig.APICfg)
+	if cfgFinder.Enable {
+		gsvc, err := ifactory.FinderTLSAPI(cfgFinder, finder, logger)
+		if err != nil {
+			return err
+		}
+		tlsfinder.RegisterServer(gsrv, gsvc)
+		msrv.Register(serverd.Service{Name: "service.tlsutil.finder"})
+	}
+	return nil
+}
+
+func createStatsDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
+	cfgStats := cfg.Data("service.dnsutil.stats").(*iconfig.APICfg)
+	if cfgStats.Enable {
+		gsvc, err := ifactory.StatsDNSAPI(cfgStats, finder, logger)
+		if err != nil {
+			return err
+		}
+		dnsstats.RegisterServer(gsrv, gsvc)
+		msrv.Register(serverd.Service{Name: "service.dnsutil.stats"})
+	}
+	return nil
+}
+
+func createFinderPassiveDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
+	cfgFinder := cfg.Data("service.dnsutil.passivedns").(*iconfig.APICfg)
+	if cfgFinder.Enable {
+		gsvc, err := ifactory.FinderPassiveDNSAPI(cfgFinder, finder, logger)
+		if err != nil {
+			return err
+		}
+		passivefinder.RegisterServer(gsrv, gsvc)
+		msrv.Register(serverd.Service{Name: "service.dnsutil.passivedns"})
+	}
+	return nil
+}
+
+func createFinderQueryDNSAPI(gsrv *grpc.Server, finder *archive.Builder, msrv *serverd.Manager, logger yalogi.Logger) error {
+	cfgFinder := cfg.Data("service.dnsutil.query").(*iconfig.APICfg)
+	if cfgFinder.Enable {
+		gsvc, err := ifactory.FinderQueryDNSAPI(cfgFinder, finder, logger)
+		if err != nil {
+			return err
+		}
+		queryfinder.RegisterServer(gsrv, gsvc)
+		msrv.Register(serverd.Service{Name: "service.dnsutil.query"})
+	}
+	return nil
+}
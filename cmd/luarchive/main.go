@@ -2,6 +2,9 @@
 
 package main
 
+//go:generate sh -c "go run . --schema backends > ../../configs/schema/backends.json"
+//go:generate sh -c "go run . --schema services > ../../configs/schema/services.json"
+
 import (
 	"fmt"
 	"os"
@@ -27,6 +30,8 @@ var (
 	help       = false
 	debug      = false
 	dryRun     = false
+	check      = false
+	schema     = ""
 )
 
 func init() {
@@ -38,6 +43,8 @@ func init() {
 	pflag.BoolVarP(&help, "help", "h", help, "Show this help.")
 	pflag.BoolVar(&debug, "debug", debug, "Enable debug.")
 	pflag.BoolVar(&dryRun, "dry-run", dryRun, "Check connections but don't start service.")
+	pflag.BoolVar(&check, "check-config", check, "Check configuration and definitions without connecting.")
+	pflag.StringVar(&schema, "schema", schema, "Output JSON schema of definitions (backends or services).")
 	pflag.Parse()
 }
 
@@ -50,6 +57,14 @@ func main() {
 		pflag.Usage()
 		os.Exit(0)
 	}
+	if schema != "" {
+		err := printSchema(schema)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	// load configuration
 	err := cfg.LoadIfFile(configFile)
@@ -58,6 +73,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	if check {
+		errs := checkConfig()
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("configuration seems ok")
+		os.Exit(0)
+	}
+
 	//creates logger
 	logger, err := createLogger(debug)
 	if err != nil {
@@ -107,6 +134,26 @@ func main() {
 	if err != nil {
 		logger.Fatalf("couldn't create dns finder service: %v", err)
 	}
+	err = createFinderEventAPI(gsrv, archivers, msrv, logger)
+	if err != nil {
+		logger.Fatalf("couldn't create event finder service: %v", err)
+	}
+	err = createFinderTLSAPI(gsrv, archivers, msrv, logger)
+	if err != nil {
+		logger.Fatalf("couldn't create tls finder service: %v", err)
+	}
+	err = createStatsDNSAPI(gsrv, archivers, msrv, logger)
+	if err != nil {
+		logger.Fatalf("couldn't create dns stats service: %v", err)
+	}
+	err = createFinderPassiveDNSAPI(gsrv, archivers, msrv, logger)
+	if err != nil {
+		logger.Fatalf("couldn't create passive dns finder service: %v", err)
+	}
+	err = createFinderQueryDNSAPI(gsrv, archivers, msrv, logger)
+	if err != nil {
+		logger.Fatalf("couldn't create dns query finder service: %v", err)
+	}
 
 	// creates health server
 	err = createHealthSrv(msrv, logger)
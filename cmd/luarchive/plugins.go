@@ -4,10 +4,25 @@ package main
 
 import (
 	// backends
+	_ "github.com/luids-io/archive/pkg/archive/backends/boltdb"
+	_ "github.com/luids-io/archive/pkg/archive/backends/file"
 	_ "github.com/luids-io/archive/pkg/archive/backends/mongodb"
+	_ "github.com/luids-io/archive/pkg/archive/backends/mongodriver"
+	_ "github.com/luids-io/archive/pkg/archive/backends/postgres"
 
 	// services
+	_ "github.com/luids-io/archive/pkg/archive/services/dnsbolt"
+	_ "github.com/luids-io/archive/pkg/archive/services/dnsfile"
 	_ "github.com/luids-io/archive/pkg/archive/services/dnsmdb"
+	_ "github.com/luids-io/archive/pkg/archive/services/dnsmongo"
+	_ "github.com/luids-io/archive/pkg/archive/services/dnspg"
+	_ "github.com/luids-io/archive/pkg/archive/services/eventfile"
 	_ "github.com/luids-io/archive/pkg/archive/services/eventmdb"
+	_ "github.com/luids-io/archive/pkg/archive/services/eventmongo"
+	_ "github.com/luids-io/archive/pkg/archive/services/eventpg"
+	_ "github.com/luids-io/archive/pkg/archive/services/multi"
+	_ "github.com/luids-io/archive/pkg/archive/services/tlsfile"
 	_ "github.com/luids-io/archive/pkg/archive/services/tlsmdb"
+	_ "github.com/luids-io/archive/pkg/archive/services/tlsmongo"
+	_ "github.com/luids-io/archive/pkg/archive/services/tlspg"
 )
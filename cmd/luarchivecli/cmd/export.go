@@ -0,0 +1,308 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsfinder "github.com/luids-io/api/dnsutil/grpc/finder"
+)
+
+// DefaultExportPageSize is the number of resolvs requested in each page.
+const DefaultExportPageSize = 1000
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export resolvs",
+	Long: `Export resolvs.
+Resolvs are exported in csv, json lines or parquet format with the selected
+columns.
+If a state file is set, the cursor of the export is stored after each page
+and the export is resumed from it, appending to the output file. Pages are
+written at least once, so some resolvs may be duplicated if the export is
+aborted.
+Parquet files are compressed with snappy and each page is written in a row
+group. They can't be resumed because the schema is written at the end of
+the file, so gzip and state options aren't allowed with this format.
+
+Columns: ` + strings.Join(resolvColumnNames(), ", "),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := dnsfinder.NewClient(grpcClient)
+
+		//prepare args and filter
+		format, _ := cmd.Flags().GetString("format")
+		columns, _ := cmd.Flags().GetString("columns")
+		output, _ := cmd.Flags().GetString("output")
+		compress, _ := cmd.Flags().GetBool("gzip")
+		statePath, _ := cmd.Flags().GetString("state")
+		progress, _ := cmd.Flags().GetDuration("progress")
+		rev, _ := cmd.Flags().GetBool("reverse")
+		pageSize, _ := cmd.Flags().GetInt("maxreq")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if pageSize <= 0 {
+			exitWithErrf("'maxreq' must be greater than zero")
+		}
+		if format == "parquet" && (compress || statePath != "") {
+			exitWithErrf("'gzip' and 'state' can't be used with parquet format")
+		}
+		cols, err := parseColumns(columns)
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		f, err := getFilterFromFlags(cmd.Flags())
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		query, _ := json.Marshal(struct {
+			Format  string
+			Columns []string
+			Gzip    bool
+			Reverse bool
+			Filter  dnsutil.ResolvsFilter
+		}{format, columnNames(cols), compress, rev, f})
+
+		//load state
+		state := exportState{Query: string(query)}
+		if statePath != "" && fileExists(statePath) {
+			state, err = loadExportState(statePath)
+			if err != nil {
+				exitWithErrf("loading state: %v", err)
+			}
+			if state.Query != string(query) {
+				exitWithErrf("state '%s' was created with different arguments", statePath)
+			}
+			if state.Done {
+				fmt.Fprintf(os.Stderr, "export completed, %v resolvs exported\n", state.Count)
+				return
+			}
+		}
+		resume := state.Count > 0 || state.Next != ""
+
+		//open output
+		out, err := newExportOutput(output, resume, compress)
+		if err != nil {
+			exitWithErrf("opening output: %v", err)
+		}
+		w, err := newRecordWriter(format, out, cols)
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		if !resume {
+			err = w.WriteHeader()
+			if err != nil {
+				exitWithErrf("writing output: %v", err)
+			}
+		}
+
+		//stop after the current page on interrupt
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt)
+		defer signal.Stop(stop)
+
+		// do export
+		start := time.Now()
+		lastReport := start
+		exported := 0
+		interrupted := false
+	EXPORTLOOP:
+		for {
+			max := pageSize
+			if limit > 0 && limit-exported < max {
+				max = limit - exported
+			}
+			ctx, cancel := getContextWithTimeout(context.Background())
+			data, next, err := cli.ListResolvs(ctx, []dnsutil.ResolvsFilter{f}, rev, max, state.Next)
+			cancel()
+			if err != nil {
+				out.Close()
+				exitWithErrf("listing resolvs: %v", err)
+			}
+			for i := range data {
+				err = w.Write(&data[i])
+				if err != nil {
+					out.Close()
+					exitWithErrf("writing output: %v", err)
+				}
+			}
+			err = w.Flush()
+			if err == nil {
+				err = out.Flush()
+			}
+			if err != nil {
+				exitWithErrf("writing output: %v", err)
+			}
+			exported += len(data)
+			state.Count += int64(len(data))
+			state.Next = next
+			state.Done = next == ""
+			if statePath != "" {
+				err = saveExportState(statePath, state)
+				if err != nil {
+					out.Close()
+					exitWithErrf("saving state: %v", err)
+				}
+			}
+			if progress > 0 && time.Since(lastReport) >= progress {
+				lastReport = time.Now()
+				fmt.Fprintf(os.Stderr, "exported %v resolvs (%.1f/s)\n", exported, float64(exported)/time.Since(start).Seconds())
+			}
+			if state.Done || (limit > 0 && exported >= limit) {
+				break
+			}
+			select {
+			case <-stop:
+				interrupted = true
+				break EXPORTLOOP
+			default:
+			}
+		}
+		err = w.Close()
+		if err != nil {
+			out.Close()
+			exitWithErrf("writing output: %v", err)
+		}
+		err = out.Close()
+		if err != nil {
+			exitWithErrf("closing output: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "exported %v resolvs in %v, total %v\n", exported, time.Since(start).Round(time.Millisecond), state.Count)
+		if interrupted {
+			exitWithErrf("export interrupted")
+		}
+	},
+}
+
+func columnNames(cols []resolvColumn) []string {
+	names := make([]string, 0, len(cols))
+	for _, c := range cols {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// exportState stores the cursor of an export.
+type exportState struct {
+	Query string `json:"query"`
+	Next  string `json:"next"`
+	Count int64  `json:"count"`
+	Done  bool   `json:"done"`
+}
+
+func loadExportState(path string) (exportState, error) {
+	var s exportState
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// saveExportState writes the state in a temporal file and renames it, so
+// state file is never left incomplete.
+func saveExportState(path string, s exportState) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// exportOutput is a buffered and optionally compressed output. Resumed
+// exports append a new gzip member, which is read as a single stream.
+type exportOutput struct {
+	*bufio.Writer
+	file io.WriteCloser
+	gz   *gzip.Writer
+}
+
+func newExportOutput(path string, resume, compress bool) (*exportOutput, error) {
+	o := &exportOutput{file: nopCloser{os.Stdout}}
+	if path != "" && path != "-" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if resume {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(path, flags, 0644)
+		if err != nil {
+			return nil, err
+		}
+		o.file = f
+	}
+	if compress {
+		o.gz = gzip.NewWriter(o.file)
+		o.Writer = bufio.NewWriter(o.gz)
+	} else {
+		o.Writer = bufio.NewWriter(o.file)
+	}
+	return o, nil
+}
+
+// Flush writes buffered data to the file.
+func (o *exportOutput) Flush() error {
+	err := o.Writer.Flush()
+	if err != nil {
+		return err
+	}
+	if o.gz != nil {
+		return o.gz.Flush()
+	}
+	return nil
+}
+
+func (o *exportOutput) Close() error {
+	err := o.Flush()
+	if err != nil {
+		o.file.Close()
+		return err
+	}
+	if o.gz != nil {
+		err = o.gz.Close()
+		if err != nil {
+			o.file.Close()
+			return err
+		}
+	}
+	return o.file.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().String("format", "csv", "Output format: csv, jsonl, parquet")
+	exportCmd.Flags().String("columns", "", "Comma separated list of columns (default all)")
+	exportCmd.Flags().StringP("output", "o", "-", "Output file ('-' for stdout)")
+	exportCmd.Flags().Bool("gzip", false, "Compress output with gzip")
+	exportCmd.Flags().String("state", "", "State file for resuming the export")
+	exportCmd.Flags().Duration("progress", 5*time.Second, "Interval of progress reports in stderr (0 disables)")
+	exportCmd.Flags().Bool("reverse", false, "Reverse order")
+	exportCmd.Flags().Int("maxreq", DefaultExportPageSize, "Max items per fetch request")
+	exportCmd.Flags().Int("limit", 0, "Max items exported in this run")
+	setResolvsFilterFlags(exportCmd)
+}
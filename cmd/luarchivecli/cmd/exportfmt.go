@@ -0,0 +1,295 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+// resolvColumn defines an exported field of resolvs.
+type resolvColumn struct {
+	name  string
+	value func(r *dnsutil.ResolvData) interface{}
+	// parquet type of the column
+	ptype string
+}
+
+// Parquet types of columns.
+const (
+	pqString    = "type=BYTE_ARRAY, convertedtype=UTF8"
+	pqTimestamp = "type=INT64, convertedtype=TIMESTAMP_MICROS"
+	pqInt64     = "type=INT64"
+	pqInt32     = "type=INT32"
+	pqBool      = "type=BOOLEAN"
+	pqStrings   = "type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"
+)
+
+var resolvColumns = []resolvColumn{
+	{"id", func(r *dnsutil.ResolvData) interface{} { return r.ID.String() }, pqString},
+	{"timestamp", func(r *dnsutil.ResolvData) interface{} { return r.Timestamp }, pqTimestamp},
+	{"duration", func(r *dnsutil.ResolvData) interface{} { return r.Duration }, pqInt64},
+	{"server", func(r *dnsutil.ResolvData) interface{} { return r.Server }, pqString},
+	{"client", func(r *dnsutil.ResolvData) interface{} { return r.Client }, pqString},
+	{"qid", func(r *dnsutil.ResolvData) interface{} { return r.QID }, pqInt32},
+	{"name", func(r *dnsutil.ResolvData) interface{} { return r.Name }, pqString},
+	{"isIPv6", func(r *dnsutil.ResolvData) interface{} { return r.IsIPv6 }, pqBool},
+	{"returnCode", func(r *dnsutil.ResolvData) interface{} { return r.ReturnCode }, pqInt32},
+	{"resolvedIPs", func(r *dnsutil.ResolvData) interface{} { return r.ResolvedIPs }, pqStrings},
+	{"resolvedCNAMEs", func(r *dnsutil.ResolvData) interface{} { return r.ResolvedCNAMEs }, pqStrings},
+	{"tld", func(r *dnsutil.ResolvData) interface{} { return r.TLD }, pqString},
+	{"tldPlusOne", func(r *dnsutil.ResolvData) interface{} { return r.TLDPlusOne }, pqString},
+}
+
+func resolvColumnNames() []string {
+	names := make([]string, 0, len(resolvColumns))
+	for _, c := range resolvColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// parseColumns returns the columns from a comma separated list, all columns
+// if it is empty.
+func parseColumns(s string) ([]resolvColumn, error) {
+	if s == "" {
+		return resolvColumns, nil
+	}
+	cols := make([]resolvColumn, 0)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range resolvColumns {
+			if strings.EqualFold(c.name, name) {
+				cols = append(cols, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid column '%s'", name)
+		}
+	}
+	return cols, nil
+}
+
+// recordWriter writes resolvs in an export format. Flush is called after
+// each page and Close at the end of the export, it doesn't close the
+// underlying writer.
+type recordWriter interface {
+	WriteHeader() error
+	Write(r *dnsutil.ResolvData) error
+	Flush() error
+	Close() error
+}
+
+func newRecordWriter(format string, w io.Writer, cols []resolvColumn) (recordWriter, error) {
+	switch format {
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w), cols: cols}, nil
+	case "jsonl":
+		return &jsonlWriter{w: w, cols: cols}, nil
+	case "parquet":
+		return newParquetWriter(w, cols)
+	}
+	return nil, fmt.Errorf("invalid format '%s'", format)
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	cols []resolvColumn
+}
+
+func (c *csvWriter) WriteHeader() error {
+	header := make([]string, 0, len(c.cols))
+	for _, col := range c.cols {
+		header = append(header, col.name)
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) Write(r *dnsutil.ResolvData) error {
+	row := make([]string, 0, len(c.cols))
+	for _, col := range c.cols {
+		row = append(row, csvValue(col.value(r)))
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+// csvValue formats values, lists are separated by spaces.
+func csvValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case time.Duration:
+		return strconv.FormatInt(int64(t), 10)
+	case net.IP:
+		if t == nil {
+			return ""
+		}
+		return t.String()
+	case []net.IP:
+		s := make([]string, 0, len(t))
+		for _, ip := range t {
+			s = append(s, ip.String())
+		}
+		return strings.Join(s, " ")
+	case []string:
+		return strings.Join(t, " ")
+	}
+	return fmt.Sprint(v)
+}
+
+type jsonlWriter struct {
+	w    io.Writer
+	cols []resolvColumn
+	buf  bytes.Buffer
+}
+
+func (j *jsonlWriter) WriteHeader() error {
+	return nil
+}
+
+// Write encodes the columns in order.
+func (j *jsonlWriter) Write(r *dnsutil.ResolvData) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, col := range j.cols {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(col.name)
+		j.buf.Write(key)
+		j.buf.WriteByte(':')
+		value, err := json.Marshal(col.value(r))
+		if err != nil {
+			return fmt.Errorf("encoding '%s': %v", col.name, err)
+		}
+		j.buf.Write(value)
+	}
+	j.buf.WriteString("}\n")
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonlWriter) Flush() error {
+	return nil
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// parquetWriter writes the columns in a parquet file. Rows are built as
+// structs created at runtime with the selected columns and each page is
+// written in its own row group.
+type parquetWriter struct {
+	pw   *writer.ParquetWriter
+	cols []resolvColumn
+	row  reflect.Type
+}
+
+func newParquetWriter(w io.Writer, cols []resolvColumn) (*parquetWriter, error) {
+	fields := make([]reflect.StructField, 0, len(cols))
+	for i, col := range cols {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: parquetGoType(col.ptype),
+			Tag:  reflect.StructTag(fmt.Sprintf(`parquet:"name=%s, %s"`, col.name, col.ptype)),
+		})
+	}
+	row := reflect.StructOf(fields)
+	pw, err := writer.NewParquetWriterFromWriter(w, reflect.New(row).Interface(), 1)
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer: %v", err)
+	}
+	return &parquetWriter{pw: pw, cols: cols, row: row}, nil
+}
+
+// WriteHeader does nothing, schema is written with the footer.
+func (p *parquetWriter) WriteHeader() error {
+	return nil
+}
+
+func (p *parquetWriter) Write(r *dnsutil.ResolvData) error {
+	row := reflect.New(p.row).Elem()
+	for i, col := range p.cols {
+		row.Field(i).Set(reflect.ValueOf(parquetValue(col.value(r))))
+	}
+	return p.pw.Write(row.Interface())
+}
+
+// Flush writes the rows in a row group.
+func (p *parquetWriter) Flush() error {
+	return p.pw.Flush(true)
+}
+
+// Close writes the footer.
+func (p *parquetWriter) Close() error {
+	return p.pw.WriteStop()
+}
+
+func parquetGoType(ptype string) reflect.Type {
+	switch ptype {
+	case pqTimestamp, pqInt64:
+		return reflect.TypeOf(int64(0))
+	case pqInt32:
+		return reflect.TypeOf(int32(0))
+	case pqBool:
+		return reflect.TypeOf(false)
+	case pqStrings:
+		return reflect.TypeOf([]string{})
+	}
+	return reflect.TypeOf("")
+}
+
+// parquetValue converts values to the go type of its parquet column.
+func parquetValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UnixNano() / int64(time.Microsecond)
+	case time.Duration:
+		return int64(t)
+	case uint16:
+		return int32(t)
+	case int:
+		return int32(t)
+	case net.IP:
+		return csvValue(t)
+	case []net.IP:
+		s := make([]string, 0, len(t))
+		for _, ip := range t {
+			s = append(s, ip.String())
+		}
+		return s
+	case []string:
+		if t == nil {
+			return []string{}
+		}
+		return t
+	}
+	return v
+}
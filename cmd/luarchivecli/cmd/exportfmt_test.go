@@ -0,0 +1,125 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+func TestParquetWriter(t *testing.T) {
+	cols, err := parseColumns("id,timestamp,duration,client,qid,isIPv6,returnCode,resolvedIPs,resolvedCNAMEs")
+	if err != nil {
+		t.Fatalf("parseColumns(): %v", err)
+	}
+	ts := time.Date(2021, 3, 10, 12, 0, 0, 123456000, time.UTC)
+	resolvs := make([]dnsutil.ResolvData, 0)
+	for i := 0; i < 5; i++ {
+		resolvs = append(resolvs, dnsutil.ResolvData{
+			ID:          uuid.New(),
+			Timestamp:   ts.Add(time.Duration(i) * time.Second),
+			Duration:    time.Duration(i) * time.Millisecond,
+			Client:      net.ParseIP("10.0.0.1"),
+			QID:         uint16(1000 + i),
+			IsIPv6:      i%2 == 0,
+			ReturnCode:  i,
+			ResolvedIPs: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+		})
+	}
+	resolvs[1].ResolvedIPs = nil
+	resolvs[2].ResolvedCNAMEs = []string{"a.example.com", "b.example.com"}
+
+	var buf bytes.Buffer
+	w, err := newRecordWriter("parquet", &buf, cols)
+	if err != nil {
+		t.Fatalf("newRecordWriter(): %v", err)
+	}
+	if err := w.WriteHeader(); err != nil {
+		t.Fatalf("WriteHeader(): %v", err)
+	}
+	// two pages
+	for i := range resolvs {
+		if err := w.Write(&resolvs[i]); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+		if i == 2 {
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush(): %v", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	pw := w.(*parquetWriter)
+	pr, err := reader.NewParquetReader(&bufferFile{Reader: bytes.NewReader(buf.Bytes())}, reflect.New(pw.row).Interface(), 1)
+	if err != nil {
+		t.Fatalf("NewParquetReader(): %v", err)
+	}
+	defer pr.ReadStop()
+	if got := len(pr.Footer.RowGroups); got != 2 {
+		t.Errorf("row groups = %v, want 2", got)
+	}
+	if got := pr.GetNumRows(); got != int64(len(resolvs)) {
+		t.Fatalf("rows = %v, want %v", got, len(resolvs))
+	}
+	rows, err := pr.ReadByNumber(len(resolvs))
+	if err != nil {
+		t.Fatalf("ReadByNumber(): %v", err)
+	}
+	// timestamps are stored in microseconds
+	if got := reflect.ValueOf(rows[0]).Field(1).Int(); got != ts.UnixNano()/1000 {
+		t.Errorf("timestamp = %v, want %v", got, ts.UnixNano()/1000)
+	}
+	for i, row := range rows {
+		v := reflect.ValueOf(row)
+		for j, col := range cols {
+			got := v.Field(j).Interface()
+			want := parquetValue(col.value(&resolvs[i]))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("row %v, column %s = %#v, want %#v", i, col.name, got, want)
+			}
+		}
+	}
+}
+
+// bufferFile implements a read only source.ParquetFile.
+type bufferFile struct {
+	*bytes.Reader
+}
+
+func (f *bufferFile) Open(name string) (source.ParquetFile, error) {
+	return &bufferFile{Reader: bytes.NewReader(f.bytes())}, nil
+}
+
+func (f *bufferFile) Create(name string) (source.ParquetFile, error) {
+	return nil, errors.New("read only")
+}
+
+func (f *bufferFile) Write(p []byte) (int, error) {
+	return 0, errors.New("read only")
+}
+
+func (f *bufferFile) Close() error {
+	return nil
+}
+
+func (f *bufferFile) bytes() []byte {
+	data := make([]byte, f.Size())
+	f.ReadAt(data, 0)
+	return data
+}
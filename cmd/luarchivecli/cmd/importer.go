@@ -0,0 +1,150 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// maxLineSize is the max size of a json line, certificates can be large.
+const maxLineSize = 16 * 1024 * 1024
+
+// errInvalid is returned by save functions if line can't be decoded.
+var errInvalid = errors.New("invalid record")
+
+// saveFn stores the record encoded in the line.
+type saveFn func(ctx context.Context, line []byte) error
+
+type importSummary struct {
+	read, accepted, failed, invalid int64
+	elapsed                         time.Duration
+}
+
+func (s importSummary) String() string {
+	rate := float64(0)
+	if s.elapsed > 0 {
+		rate = float64(s.accepted) / s.elapsed.Seconds()
+	}
+	return fmt.Sprintf("read: %v, accepted: %v, failed: %v, invalid: %v, elapsed: %v (%.1f/s)",
+		s.read, s.accepted, s.failed, s.invalid, s.elapsed.Round(time.Millisecond), rate)
+}
+
+type importJob struct {
+	num  int64
+	line []byte
+}
+
+// importLines reads json lines from the input and calls save concurrently.
+func importLines(r io.Reader, save saveFn, workers int, rate int, verbose bool) (importSummary, error) {
+	var s importSummary
+	start := time.Now()
+	if workers <= 0 {
+		workers = 1
+	}
+	var limit <-chan time.Time
+	if rate > 0 {
+		tick := time.NewTicker(time.Second / time.Duration(rate))
+		defer tick.Stop()
+		limit = tick.C
+	}
+	jobs := make(chan importJob, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				ctx, cancel := getContextWithTimeout(context.Background())
+				err := save(ctx, job.line)
+				cancel()
+				switch {
+				case err == nil:
+					atomic.AddInt64(&s.accepted, 1)
+					continue
+				case errors.Is(err, errInvalid):
+					atomic.AddInt64(&s.invalid, 1)
+				default:
+					atomic.AddInt64(&s.failed, 1)
+				}
+				if verbose {
+					fmt.Fprintf(os.Stderr, "line %v: %v\n", job.num, err)
+				}
+			}
+		}()
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	num := int64(0)
+	for scanner.Scan() {
+		num++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		if limit != nil {
+			<-limit
+		}
+		s.read++
+		jobs <- importJob{num: num, line: line}
+	}
+	close(jobs)
+	wg.Wait()
+	s.elapsed = time.Since(start)
+	if err := scanner.Err(); err != nil {
+		return s, fmt.Errorf("reading line %v: %v", num+1, err)
+	}
+	return s, nil
+}
+
+// runImport opens the input from flags, imports the lines, closes the
+// client and prints the summary in stderr.
+func runImport(cmd *cobra.Command, client io.Closer, save saveFn) {
+	file, _ := cmd.Flags().GetString("file")
+	workers, _ := cmd.Flags().GetInt("workers")
+	rate, _ := cmd.Flags().GetInt("rate")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	if workers <= 0 {
+		exitWithErrf("'workers' must be greater than zero")
+	}
+	if rate < 0 {
+		exitWithErrf("'rate' must be positive")
+	}
+	input := os.Stdin
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			exitWithErrf("opening input: %v", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	summary, err := importLines(input, save, workers, rate, verbose)
+	if cerr := client.Close(); cerr != nil {
+		fmt.Fprintf(os.Stderr, "closing client: %v\n", cerr)
+	}
+	fmt.Fprintf(os.Stderr, "%v\n", summary)
+	if err != nil {
+		exitWithErrf("%v", err)
+	}
+	if summary.failed > 0 || summary.invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+func setImportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("file", "f", "-", "Input file in json lines format ('-' for stdin)")
+	cmd.Flags().Int("workers", 4, "Number of concurrent requests")
+	cmd.Flags().Int("rate", 0, "Max records per second (0 unlimited)")
+	cmd.Flags().BoolP("verbose", "v", false, "Print errors of each record")
+}
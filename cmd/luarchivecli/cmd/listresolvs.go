@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,6 +17,8 @@ import (
 
 	"github.com/luids-io/api/dnsutil"
 	dnsfinder "github.com/luids-io/api/dnsutil/grpc/finder"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	queryfinder "github.com/luids-io/archive/pkg/dnsquery/grpc/finder"
 )
 
 // getresolvCmd represents the getresolv command
@@ -25,16 +28,28 @@ var listresolvsCmd = &cobra.Command{
 	Long:  `List resolvs`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		cli := dnsfinder.NewClient(grpcClient)
-		ctx, cancel := getContextWithTimeout(context.Background())
-		defer cancel()
-
 		//prepare args and filter
 		rev, _ := cmd.Flags().GetBool("reverse")
 		maxreq, _ := cmd.Flags().GetInt("maxreq")
 		limit, _ := cmd.Flags().GetInt("limit")
 		jsonFormat, _ := cmd.Flags().GetBool("json")
-		f, err := getFilterFromFlags(cmd.Flags())
+		follow, _ := cmd.Flags().GetBool("follow")
+		if follow {
+			if rev {
+				fmt.Fprintf(os.Stderr, "'follow' can't be used with 'reverse'\n")
+				os.Exit(1)
+			}
+			err := followResolvs(cmd.Flags(), limit, jsonFormat)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(context.Background())
+		defer cancel()
+		list, err := getListFn(cmd.Flags())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -46,18 +61,13 @@ var listresolvsCmd = &cobra.Command{
 		count := 0
 	LISTLOOP:
 		for {
-			data, next, err = cli.ListResolvs(ctx, []dnsutil.ResolvsFilter{f}, rev, maxreq, next)
+			data, next, err = list(ctx, rev, maxreq, next)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
 			}
 			for _, r := range data {
-				if jsonFormat {
-					jsons, _ := json.Marshal(r)
-					fmt.Printf("%s\n", string(jsons))
-				} else {
-					fmt.Printf("%s,%s,%v,%s,%v,%v\n", r.ID, r.Timestamp.Format(time.RFC3339), r.Client, r.Name, r.ReturnCode, r.ResolvedIPs)
-				}
+				printResolvLine(r, jsonFormat)
 				count++
 				if limit > 0 && count >= limit {
 					break LISTLOOP
@@ -70,6 +80,103 @@ var listresolvsCmd = &cobra.Command{
 	},
 }
 
+func printResolvLine(r dnsutil.ResolvData, jsonFormat bool) {
+	if jsonFormat {
+		jsons, _ := json.Marshal(r)
+		fmt.Printf("%s\n", string(jsons))
+		return
+	}
+	fmt.Printf("%s,%s,%v,%s,%v,%v\n", r.ID, r.Timestamp.Format(time.RFC3339), r.Client, r.Name, r.ReturnCode, r.ResolvedIPs)
+}
+
+var errLimitReached = errors.New("limit reached")
+
+// followResolvs prints new resolvs until interrupted or limit is reached.
+func followResolvs(flags *pflag.FlagSet, limit int, jsonFormat bool) error {
+	filters, err := getQueryFilters(flags)
+	if err != nil {
+		return err
+	}
+	if filters == nil {
+		f, err := getFilterFromFlags(flags)
+		if err != nil {
+			return err
+		}
+		filters = dnsquery.Filters([]dnsutil.ResolvsFilter{f})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	cli := queryfinder.NewClient(grpcClient)
+	count := 0
+	err = cli.FollowResolvs(ctx, filters, func(r dnsutil.ResolvData) error {
+		printResolvLine(r, jsonFormat)
+		count++
+		if limit > 0 && count >= limit {
+			return errLimitReached
+		}
+		return nil
+	})
+	if err == errLimitReached || ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// listFn lists a page of resolvs.
+type listFn func(ctx context.Context, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error)
+
+// getListFn returns the function that lists resolvs using the query
+// finder if a query is defined or the finder with the filter flags.
+func getListFn(flags *pflag.FlagSet) (listFn, error) {
+	filters, err := getQueryFilters(flags)
+	if err != nil {
+		return nil, err
+	}
+	if filters == nil {
+		f, err := getFilterFromFlags(flags)
+		if err != nil {
+			return nil, err
+		}
+		cli := dnsfinder.NewClient(grpcClient)
+		return func(ctx context.Context, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+			return cli.ListResolvs(ctx, []dnsutil.ResolvsFilter{f}, rev, max, next)
+		}, nil
+	}
+	cli := queryfinder.NewClient(grpcClient)
+	return func(ctx context.Context, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+		return cli.QueryResolvs(ctx, filters, rev, max, next)
+	}, nil
+}
+
+// getQueryFilters returns the filters of the query, nil if query is not
+// defined.
+func getQueryFilters(flags *pflag.FlagSet) ([]dnsquery.Filter, error) {
+	query, _ := flags.GetString("query")
+	if query == "" {
+		return nil, nil
+	}
+	for _, name := range resolvsFilterFlags {
+		if flags.Changed(name) {
+			return nil, fmt.Errorf("'query' can't be used with '%s'", name)
+		}
+	}
+	filters, err := dnsquery.Parse(query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'query': %v", err)
+	}
+	return filters, nil
+}
+
 func getFilterFromFlags(flags *pflag.FlagSet) (dnsutil.ResolvsFilter, error) {
 	var err error
 	var f dnsutil.ResolvsFilter
@@ -124,16 +231,28 @@ func init() {
 	listresolvsCmd.Flags().Int("maxreq", 0, "Max items per fetch request")
 	listresolvsCmd.Flags().Int("limit", 0, "Max items listed")
 	listresolvsCmd.Flags().Bool("json", false, "Json format")
-	//filter args
-	listresolvsCmd.Flags().String("clientip", "", "Filter by client IP")
-	listresolvsCmd.Flags().String("serverip", "", "Filter by server IP")
-	listresolvsCmd.Flags().String("name", "", "Filter by name")
-	listresolvsCmd.Flags().Int("qid", 0, "Filter by qid")
-	listresolvsCmd.Flags().Int("returncode", 0, "Filter by return code")
-	listresolvsCmd.Flags().String("resolvedip", "", "Filter by resolved IP")
-	listresolvsCmd.Flags().String("resolvedcname", "", "Filter by resolved cname")
-	listresolvsCmd.Flags().String("tld", "", "Filter by tld")
-	listresolvsCmd.Flags().String("tldplusone", "", "Filter by tldplusone")
-	listresolvsCmd.Flags().String("since", "", "Filter since timestamp (format '"+time.RFC3339+"')")
-	listresolvsCmd.Flags().String("to", "", "Filter to timestamp (format '"+time.RFC3339+"')")
+	listresolvsCmd.Flags().Bool("follow", false, "Follow new resolvs until interrupted")
+	listresolvsCmd.Flags().String("query", "", "Filter by query (ex: 'client=10.0.0.0/8 and (tld=ru or returncode=3) and since=-1h')")
+	setResolvsFilterFlags(listresolvsCmd)
+}
+
+// resolvsFilterFlags are the flags added by setResolvsFilterFlags.
+var resolvsFilterFlags = []string{
+	"clientip", "serverip", "name", "qid", "returncode", "resolvedip",
+	"resolvedcname", "tld", "tldplusone", "since", "to",
+}
+
+// setResolvsFilterFlags adds the flags used by getFilterFromFlags.
+func setResolvsFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("clientip", "", "Filter by client IP")
+	cmd.Flags().String("serverip", "", "Filter by server IP")
+	cmd.Flags().String("name", "", "Filter by name")
+	cmd.Flags().Int("qid", 0, "Filter by qid")
+	cmd.Flags().Int("returncode", 0, "Filter by return code")
+	cmd.Flags().String("resolvedip", "", "Filter by resolved IP")
+	cmd.Flags().String("resolvedcname", "", "Filter by resolved cname")
+	cmd.Flags().String("tld", "", "Filter by tld")
+	cmd.Flags().String("tldplusone", "", "Filter by tldplusone")
+	cmd.Flags().String("since", "", "Filter since timestamp (format '"+time.RFC3339+"')")
+	cmd.Flags().String("to", "", "Filter to timestamp (format '"+time.RFC3339+"')")
 }
@@ -0,0 +1,120 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/luids-io/archive/pkg/passivedns"
+	passivefinder "github.com/luids-io/archive/pkg/passivedns/grpc/finder"
+)
+
+// passivednsCmd represents the passivedns command
+var passivednsCmd = &cobra.Command{
+	Use:   "passivedns",
+	Short: "List passive dns records",
+	Long: `List passive dns records.
+Records are the deduplicated mappings between names and resolved ips or
+cnames, with the first and last time seen. One of name, ip or cname is
+required.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := passivefinder.NewClient(grpcClient)
+		ctx, cancel := getContextWithTimeout(context.Background())
+		defer cancel()
+
+		//prepare args and filter
+		maxreq, _ := cmd.Flags().GetInt("maxreq")
+		limit, _ := cmd.Flags().GetInt("limit")
+		jsonFormat, _ := cmd.Flags().GetBool("json")
+		f, err := getPassiveFilterFromFlags(cmd.Flags())
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+
+		// do list
+		var data []passivedns.Record
+		next := ""
+		count := 0
+	LISTLOOP:
+		for {
+			data, next, err = cli.ListRecords(ctx, f, maxreq, next)
+			if err != nil {
+				exitWithErrf("%v", err)
+			}
+			for _, r := range data {
+				if jsonFormat {
+					jsons, _ := json.Marshal(r)
+					fmt.Printf("%s\n", string(jsons))
+				} else {
+					fmt.Printf("%s,%s,%s,%s,%s,%v\n", r.Name, r.Type, r.Value,
+						r.FirstSeen.Format(time.RFC3339), r.LastSeen.Format(time.RFC3339), r.Count)
+				}
+				count++
+				if limit > 0 && count >= limit {
+					break LISTLOOP
+				}
+			}
+			if next == "" {
+				break
+			}
+		}
+	},
+}
+
+func getPassiveFilterFromFlags(flags *pflag.FlagSet) (passivedns.RecordsFilter, error) {
+	var err error
+	var f passivedns.RecordsFilter
+	f.Name, _ = flags.GetString("name")
+	ip, _ := flags.GetString("ip")
+	if ip != "" {
+		f.IP = net.ParseIP(ip)
+		if f.IP == nil {
+			return f, errors.New("invalid 'ip' format")
+		}
+	}
+	f.CNAME, _ = flags.GetString("cname")
+	if f.IP != nil && f.CNAME != "" {
+		return f, errors.New("'ip' and 'cname' can't be used together")
+	}
+	if f.Empty() {
+		return f, errors.New("'name', 'ip' or 'cname' is required")
+	}
+	since, _ := flags.GetString("since")
+	if since != "" {
+		f.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return f, fmt.Errorf("invalid 'since' format: %v", err)
+		}
+	}
+	to, _ := flags.GetString("to")
+	if to != "" {
+		f.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return f, fmt.Errorf("invalid 'to' format: %v", err)
+		}
+	}
+	return f, nil
+}
+
+func init() {
+	rootCmd.AddCommand(passivednsCmd)
+
+	passivednsCmd.Flags().Int("maxreq", 0, "Max items per fetch request")
+	passivednsCmd.Flags().Int("limit", 0, "Max items listed")
+	passivednsCmd.Flags().Bool("json", false, "Json format")
+	//filter args
+	passivednsCmd.Flags().String("name", "", "Filter by name")
+	passivednsCmd.Flags().String("ip", "", "Filter by resolved IP")
+	passivednsCmd.Flags().String("cname", "", "Filter by resolved cname")
+	passivednsCmd.Flags().String("since", "", "Filter seen since timestamp (format '"+time.RFC3339+"')")
+	passivednsCmd.Flags().String("to", "", "Filter seen to timestamp (format '"+time.RFC3339+"')")
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/luids-io/api/event"
+	eventarchive "github.com/luids-io/api/event/grpc/archive"
+)
+
+// saveeventCmd represents the saveevent command
+var saveeventCmd = &cobra.Command{
+	Use:   "saveevent",
+	Short: "Save events",
+	Long: `Save events.
+Reads events in json lines format and saves them in the archive.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := eventarchive.NewClient(grpcClient, eventarchive.CloseConnection(false))
+		runImport(cmd, cli, func(ctx context.Context, line []byte) error {
+			var e event.Event
+			err := json.Unmarshal(line, &e)
+			if err != nil {
+				return fmt.Errorf("%w: %v", errInvalid, err)
+			}
+			_, err = cli.SaveEvent(ctx, e)
+			return err
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(saveeventCmd)
+	setImportFlags(saveeventCmd)
+}
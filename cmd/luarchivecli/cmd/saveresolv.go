@@ -0,0 +1,41 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsarchive "github.com/luids-io/api/dnsutil/grpc/archive"
+)
+
+// saveresolvCmd represents the saveresolv command
+var saveresolvCmd = &cobra.Command{
+	Use:   "saveresolv",
+	Short: "Save resolvs",
+	Long: `Save resolvs.
+Reads resolvs in json lines format, as listed by 'listresolvs --json', and
+saves them in the archive.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := dnsarchive.NewClient(grpcClient, dnsarchive.CloseConnection(false))
+		runImport(cmd, cli, func(ctx context.Context, line []byte) error {
+			var r dnsutil.ResolvData
+			err := json.Unmarshal(line, &r)
+			if err != nil {
+				return fmt.Errorf("%w: %v", errInvalid, err)
+			}
+			_, err = cli.SaveResolv(ctx, r)
+			return err
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(saveresolvCmd)
+	setImportFlags(saveresolvCmd)
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/luids-io/api/tlsutil"
+	tlsarchive "github.com/luids-io/api/tlsutil/grpc/archive"
+)
+
+// savetlsCmd represents the savetls command
+var savetlsCmd = &cobra.Command{
+	Use:   "savetls",
+	Short: "Save tls data",
+	Long: `Save tls data.
+Reads tls connections, certificates or records in json lines format and saves
+them in the archive. Records are sent asynchronously in a stream, so only
+errors sending them to the stream are reported as failed.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		dataType, _ := cmd.Flags().GetString("type")
+		var save func(cli *tlsarchive.Client) saveFn
+		switch dataType {
+		case "connection":
+			save = saveTLSConnection
+		case "certificate":
+			save = saveTLSCertificate
+		case "record":
+			save = saveTLSRecord
+		default:
+			exitWithErrf("invalid 'type' '%s'", dataType)
+		}
+		cli := tlsarchive.NewClient(grpcClient, tlsarchive.CloseConnection(false))
+		runImport(cmd, cli, save(cli))
+	},
+}
+
+func saveTLSConnection(cli *tlsarchive.Client) saveFn {
+	return func(ctx context.Context, line []byte) error {
+		var cn tlsutil.ConnectionData
+		err := json.Unmarshal(line, &cn)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalid, err)
+		}
+		_, err = cli.SaveConnection(ctx, &cn)
+		return err
+	}
+}
+
+// certificates are decoded from the raw data of the certificate.
+type certificateLine struct {
+	ID     string `json:"id"`
+	Digest string `json:"digest"`
+	Data   struct {
+		Raw []byte `json:"Raw"`
+	} `json:"data"`
+}
+
+func saveTLSCertificate(cli *tlsarchive.Client) saveFn {
+	return func(ctx context.Context, line []byte) error {
+		var cl certificateLine
+		err := json.Unmarshal(line, &cl)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalid, err)
+		}
+		cert, err := x509.ParseCertificate(cl.Data.Raw)
+		if err != nil {
+			return fmt.Errorf("%w: parsing certificate: %v", errInvalid, err)
+		}
+		_, err = cli.SaveCertificate(ctx, &tlsutil.CertificateData{ID: cl.ID, Digest: cl.Digest, Data: cert})
+		return err
+	}
+}
+
+func saveTLSRecord(cli *tlsarchive.Client) saveFn {
+	return func(ctx context.Context, line []byte) error {
+		var r tlsutil.RecordData
+		err := json.Unmarshal(line, &r)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalid, err)
+		}
+		return cli.StoreRecord(&r)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(savetlsCmd)
+	setImportFlags(savetlsCmd)
+	savetlsCmd.Flags().String("type", "connection", "Type of data: connection, certificate, record")
+}
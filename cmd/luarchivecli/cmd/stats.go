@@ -0,0 +1,77 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/dnsstats"
+	dnsstatsapi "github.com/luids-io/archive/pkg/dnsstats/grpc/stats"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Aggregated statistics of resolvs",
+	Long: `Aggregated statistics of resolvs.
+Resolvs are grouped by name, tldPlusOne, client or returnCode and the groups
+with more resolvs are listed with the number of nxdomain responses and the
+first and last time seen.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		cli := dnsstatsapi.NewClient(grpcClient)
+		ctx, cancel := getContextWithTimeout(context.Background())
+		defer cancel()
+
+		//prepare args and filter
+		sgroup, _ := cmd.Flags().GetString("groupby")
+		groupBy, err := dnsstats.ParseGroupBy(sgroup)
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		top, _ := cmd.Flags().GetInt("top")
+		jsonFormat, _ := cmd.Flags().GetBool("json")
+		f, err := getFilterFromFlags(cmd.Flags())
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		last, _ := cmd.Flags().GetDuration("last")
+		if last > 0 {
+			if !f.Since.IsZero() {
+				exitWithErrf("'last' and 'since' can't be used together")
+			}
+			f.Since = time.Now().Add(-last)
+		}
+
+		// do aggregation
+		items, err := cli.Aggregate(ctx, []dnsutil.ResolvsFilter{f}, groupBy, top)
+		if err != nil {
+			exitWithErrf("%v", err)
+		}
+		for _, i := range items {
+			if jsonFormat {
+				jsons, _ := json.Marshal(i)
+				fmt.Printf("%s\n", string(jsons))
+			} else {
+				fmt.Printf("%s,%v,%v,%.4f,%s,%s\n", i.Key, i.Count, i.NXDomain, i.NXDomainRate(),
+					i.FirstSeen.Format(time.RFC3339), i.LastSeen.Format(time.RFC3339))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().String("groupby", "name", "Group by field: name, tldPlusOne, client, returnCode")
+	statsCmd.Flags().Int("top", 20, "Max groups listed")
+	statsCmd.Flags().Duration("last", 0, "Filter since duration before now (e.g. '1h')")
+	statsCmd.Flags().Bool("json", false, "Json format")
+	setResolvsFilterFlags(statsCmd)
+}
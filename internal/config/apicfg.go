@@ -11,26 +11,29 @@ import (
 	"github.com/luids-io/common/util"
 )
 
-// FinderDNSAPICfg stores finder service preferences
-type FinderDNSAPICfg struct {
+// APICfg stores preferences of an api served by an archive service. It is
+// used by all the archive, finder and stats apis.
+type APICfg struct {
+	// Name of the api used in help messages, for example "dns finder"
+	Name    string
 	Enable  bool
 	Log     bool
 	Service string
 }
 
 // SetPFlags setups posix flags for commandline configuration
-func (cfg *FinderDNSAPICfg) SetPFlags(short bool, prefix string) {
+func (cfg *APICfg) SetPFlags(short bool, prefix string) {
 	aprefix := ""
 	if prefix != "" {
 		aprefix = prefix + "."
 	}
-	pflag.BoolVar(&cfg.Enable, aprefix+"enable", cfg.Enable, "Enable dns finder api.")
+	pflag.BoolVar(&cfg.Enable, aprefix+"enable", cfg.Enable, fmt.Sprintf("Enable %s api.", cfg.Name))
 	pflag.BoolVar(&cfg.Log, aprefix+"log", cfg.Log, "Enable log in service.")
-	pflag.StringVar(&cfg.Service, aprefix+"service", cfg.Service, "Service id finder dns.")
+	pflag.StringVar(&cfg.Service, aprefix+"service", cfg.Service, fmt.Sprintf("Service id %s.", cfg.Name))
 }
 
 // BindViper setups posix flags for commandline configuration and bind to viper
-func (cfg *FinderDNSAPICfg) BindViper(v *viper.Viper, prefix string) {
+func (cfg *APICfg) BindViper(v *viper.Viper, prefix string) {
 	aprefix := ""
 	if prefix != "" {
 		aprefix = prefix + "."
@@ -41,7 +44,7 @@ func (cfg *FinderDNSAPICfg) BindViper(v *viper.Viper, prefix string) {
 }
 
 // FromViper fill values from viper
-func (cfg *FinderDNSAPICfg) FromViper(v *viper.Viper, prefix string) {
+func (cfg *APICfg) FromViper(v *viper.Viper, prefix string) {
 	aprefix := ""
 	if prefix != "" {
 		aprefix = prefix + "."
@@ -52,12 +55,12 @@ func (cfg *FinderDNSAPICfg) FromViper(v *viper.Viper, prefix string) {
 }
 
 // Empty returns true if configuration is empty
-func (cfg FinderDNSAPICfg) Empty() bool {
+func (cfg APICfg) Empty() bool {
 	return !cfg.Enable
 }
 
 // Validate checks that configuration is ok
-func (cfg FinderDNSAPICfg) Validate() error {
+func (cfg APICfg) Validate() error {
 	if cfg.Service == "" {
 		return fmt.Errorf("service must be defined")
 	}
@@ -65,6 +68,6 @@ func (cfg FinderDNSAPICfg) Validate() error {
 }
 
 // Dump configuration
-func (cfg FinderDNSAPICfg) Dump() string {
+func (cfg APICfg) Dump() string {
 	return fmt.Sprintf("%+v", cfg)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/luids-io/archive/pkg/archive"
 	"github.com/luids-io/common/util"
 )
 
@@ -91,8 +92,8 @@ func (cfg ArchiverCfg) Validate() error {
 		return errors.New("config service required")
 	}
 	for _, file := range cfg.BackendFiles {
-		if !strings.HasSuffix(file, ".json") {
-			return fmt.Errorf("config file '%s' without .json extension", file)
+		if !archive.IsDefsFile(file) {
+			return fmt.Errorf("config file '%s' without %s extension", file, strings.Join(archive.DefsExtensions, ","))
 		}
 		if !util.FileExists(file) {
 			return fmt.Errorf("config file '%v' doesn't exists", file)
@@ -104,8 +105,8 @@ func (cfg ArchiverCfg) Validate() error {
 		}
 	}
 	for _, file := range cfg.ServiceFiles {
-		if !strings.HasSuffix(file, ".json") {
-			return fmt.Errorf("config file '%s' without .json extension", file)
+		if !archive.IsDefsFile(file) {
+			return fmt.Errorf("config file '%s' without %s extension", file, strings.Join(archive.DefsExtensions, ","))
 		}
 		if !util.FileExists(file) {
 			return fmt.Errorf("config file '%v' doesn't exists", file)
@@ -5,6 +5,8 @@ package factory
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/luids-io/archive/internal/config"
 	"github.com/luids-io/archive/pkg/archive"
@@ -28,7 +30,7 @@ func Backends(cfg *config.ArchiverCfg, b *archive.Builder, logger yalogi.Logger)
 	if err != nil {
 		return fmt.Errorf("bad config: %v", err)
 	}
-	dbfiles, err := util.GetFilesDB("json", cfg.BackendFiles, cfg.BackendDirs)
+	dbfiles, err := getDefsFiles(cfg.BackendFiles, cfg.BackendDirs)
 	if err != nil {
 		return fmt.Errorf("loading dbfiles: %v", err)
 	}
@@ -48,6 +50,63 @@ func Backends(cfg *config.ArchiverCfg, b *archive.Builder, logger yalogi.Logger)
 	return nil
 }
 
+//Reload rebuilds backends and services from configuration files
+func Reload(cfg *config.ArchiverCfg, b *archive.Builder, logger yalogi.Logger) error {
+	backends, services, err := LoadDefs(cfg)
+	if err != nil {
+		return err
+	}
+	return b.Reload(backends, services)
+}
+
+//LoadDefs loads backend and service definitions from configuration files
+func LoadDefs(cfg *config.ArchiverCfg) ([]archive.BackendDef, []archive.ServiceDef, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("bad config: %v", err)
+	}
+	dbfiles, err := getDefsFiles(cfg.BackendFiles, cfg.BackendDirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading dbfiles: %v", err)
+	}
+	backends, err := loadBackendDefs(dbfiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading dbfiles: %v", err)
+	}
+	dbfiles, err = getDefsFiles(cfg.ServiceFiles, cfg.ServiceDirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading dbfiles: %v", err)
+	}
+	services, err := loadServiceDefs(dbfiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading dbfiles: %v", err)
+	}
+	return backends, services, nil
+}
+
+// getDefsFiles returns the definition files from the lists of files and
+// dirs, dirs are read in order of extension.
+func getDefsFiles(files []string, dirs []string) ([]string, error) {
+	defsFiles := make([]string, 0)
+	for _, ext := range archive.DefsExtensions {
+		dbfiles, err := util.GetFilesDB(strings.TrimPrefix(ext, "."), nil, dirs)
+		if err != nil {
+			return nil, err
+		}
+		defsFiles = append(defsFiles, dbfiles...)
+	}
+	for _, file := range files {
+		if !archive.IsDefsFile(file) {
+			return nil, fmt.Errorf("file '%s' with unsupported extension", file)
+		}
+		if !util.FileExists(file) {
+			return nil, fmt.Errorf("file '%s' doesn't exists", file)
+		}
+		defsFiles = append(defsFiles, filepath.Clean(file))
+	}
+	return defsFiles, nil
+}
+
 func loadBackendDefs(dbFiles []string) ([]archive.BackendDef, error) {
 	loadedDB := make([]archive.BackendDef, 0)
 	for _, file := range dbFiles {
@@ -66,7 +125,7 @@ func Services(cfg *config.ArchiverCfg, b *archive.Builder, logger yalogi.Logger)
 	if err != nil {
 		return fmt.Errorf("bad config: %v", err)
 	}
-	dbfiles, err := util.GetFilesDB("json", cfg.ServiceFiles, cfg.ServiceDirs)
+	dbfiles, err := getDefsFiles(cfg.ServiceFiles, cfg.ServiceDirs)
 	if err != nil {
 		return fmt.Errorf("loading dbfiles: %v", err)
 	}
@@ -18,7 +18,7 @@ import (
 )
 
 // ArchiveEventAPI creates grpc service
-func ArchiveEventAPI(cfg *config.ArchiveEventAPICfg, finder *archive.Builder, logger yalogi.Logger) (*eventapi.Service, error) {
+func ArchiveEventAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*eventapi.Service, error) {
 	if !cfg.Enable {
 		return nil, errors.New("event api disabled")
 	}
@@ -30,10 +30,11 @@ func ArchiveEventAPI(cfg *config.ArchiveEventAPICfg, finder *archive.Builder, lo
 	if err != nil {
 		return nil, fmt.Errorf("'eventapi' service: %v", err)
 	}
-	c, ok := svc.(event.Archiver)
+	_, ok := svc.(event.Archiver)
 	if !ok {
 		return nil, fmt.Errorf("can't cast id '%s' to event.Archiver", cfg.Service)
 	}
+	c := eventArchiver{b: finder, id: cfg.Service}
 	if !cfg.Log {
 		logger = yalogi.LogNull
 	}
@@ -41,7 +42,7 @@ func ArchiveEventAPI(cfg *config.ArchiveEventAPICfg, finder *archive.Builder, lo
 }
 
 // ArchiveDNSAPI creates grpc service
-func ArchiveDNSAPI(cfg *config.ArchiveDNSAPICfg, finder *archive.Builder, logger yalogi.Logger) (*dnsapi.Service, error) {
+func ArchiveDNSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*dnsapi.Service, error) {
 	if !cfg.Enable {
 		return nil, errors.New("event api disabled")
 	}
@@ -53,10 +54,11 @@ func ArchiveDNSAPI(cfg *config.ArchiveDNSAPICfg, finder *archive.Builder, logger
 	if err != nil {
 		return nil, fmt.Errorf("'dnsapi' service: %v", err)
 	}
-	c, ok := svc.(dnsutil.Archiver)
+	_, ok := svc.(dnsutil.Archiver)
 	if !ok {
 		return nil, fmt.Errorf("can't cast id '%s' to dnsutil.Archiver", cfg.Service)
 	}
+	c := dnsArchiver{b: finder, id: cfg.Service}
 	if !cfg.Log {
 		logger = yalogi.LogNull
 	}
@@ -64,7 +66,7 @@ func ArchiveDNSAPI(cfg *config.ArchiveDNSAPICfg, finder *archive.Builder, logger
 }
 
 // ArchiveTLSAPI creates grpc service
-func ArchiveTLSAPI(cfg *config.ArchiveTLSAPICfg, finder *archive.Builder, logger yalogi.Logger) (*tlsapi.Service, error) {
+func ArchiveTLSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*tlsapi.Service, error) {
 	if !cfg.Enable {
 		return nil, errors.New("event api disabled")
 	}
@@ -76,10 +78,11 @@ func ArchiveTLSAPI(cfg *config.ArchiveTLSAPICfg, finder *archive.Builder, logger
 	if err != nil {
 		return nil, fmt.Errorf("'tlsapi' service: %v", err)
 	}
-	c, ok := svc.(tlsutil.Archiver)
+	_, ok := svc.(tlsutil.Archiver)
 	if !ok {
 		return nil, fmt.Errorf("can't cast id '%s' to tlsutil.Archiver", cfg.Service)
 	}
+	c := tlsArchiver{b: finder, id: cfg.Service}
 	if !cfg.Log {
 		logger = yalogi.LogNull
 	}
@@ -10,11 +10,19 @@ import (
 	dnsapi "github.com/luids-io/api/dnsutil/grpc/finder"
 	"github.com/luids-io/archive/internal/config"
 	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	eventapi "github.com/luids-io/archive/pkg/eventfinder/grpc/finder"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	queryapi "github.com/luids-io/archive/pkg/dnsquery/grpc/finder"
+	"github.com/luids-io/archive/pkg/passivedns"
+	passiveapi "github.com/luids-io/archive/pkg/passivedns/grpc/finder"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+	tlsapi "github.com/luids-io/archive/pkg/tlsfinder/grpc/finder"
 	"github.com/luids-io/core/yalogi"
 )
 
 // FinderDNSAPI creates grpc service
-func FinderDNSAPI(cfg *config.FinderDNSAPICfg, finder *archive.Builder, logger yalogi.Logger) (*dnsapi.Service, error) {
+func FinderDNSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*dnsapi.Service, error) {
 	if !cfg.Enable {
 		return nil, errors.New("dns finder api disabled")
 	}
@@ -26,12 +34,109 @@ func FinderDNSAPI(cfg *config.FinderDNSAPICfg, finder *archive.Builder, logger y
 	if err != nil {
 		return nil, fmt.Errorf("'dnsapi' service: %v", err)
 	}
-	f, ok := svc.(dnsutil.Finder)
+	_, ok := svc.(dnsutil.Finder)
 	if !ok {
 		return nil, fmt.Errorf("can't cast id '%s' to dnsutil.Finder", cfg.Service)
 	}
+	f := dnsFinder{b: finder, id: cfg.Service}
 	if !cfg.Log {
 		logger = yalogi.LogNull
 	}
 	return dnsapi.NewService(f, dnsapi.SetServiceLogger(logger)), nil
 }
+
+// FinderEventAPI creates grpc service
+func FinderEventAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*eventapi.Service, error) {
+	if !cfg.Enable {
+		return nil, errors.New("event finder api disabled")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("bad config: %v", err)
+	}
+	svc, err := getService(cfg.Service, archive.EventAPI, finder)
+	if err != nil {
+		return nil, fmt.Errorf("'eventapi' service: %v", err)
+	}
+	_, ok := svc.(eventfinder.Finder)
+	if !ok {
+		return nil, fmt.Errorf("can't cast id '%s' to eventfinder.Finder", cfg.Service)
+	}
+	f := eventFinder{b: finder, id: cfg.Service}
+	if !cfg.Log {
+		logger = yalogi.LogNull
+	}
+	return eventapi.NewService(f, eventapi.SetServiceLogger(logger)), nil
+}
+
+// FinderTLSAPI creates grpc service
+func FinderTLSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*tlsapi.Service, error) {
+	if !cfg.Enable {
+		return nil, errors.New("tls finder api disabled")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("bad config: %v", err)
+	}
+	svc, err := getService(cfg.Service, archive.TLSAPI, finder)
+	if err != nil {
+		return nil, fmt.Errorf("'tlsapi' service: %v", err)
+	}
+	_, ok := svc.(tlsfinder.Finder)
+	if !ok {
+		return nil, fmt.Errorf("can't cast id '%s' to tlsfinder.Finder", cfg.Service)
+	}
+	f := tlsFinder{b: finder, id: cfg.Service}
+	if !cfg.Log {
+		logger = yalogi.LogNull
+	}
+	return tlsapi.NewService(f, tlsapi.SetServiceLogger(logger)), nil
+}
+
+// FinderPassiveDNSAPI creates grpc service
+func FinderPassiveDNSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*passiveapi.Service, error) {
+	if !cfg.Enable {
+		return nil, errors.New("passive dns finder api disabled")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("bad config: %v", err)
+	}
+	svc, err := getService(cfg.Service, archive.DNSAPI, finder)
+	if err != nil {
+		return nil, fmt.Errorf("'passivednsapi' service: %v", err)
+	}
+	_, ok := svc.(passivedns.Finder)
+	if !ok {
+		return nil, fmt.Errorf("can't cast id '%s' to passivedns.Finder", cfg.Service)
+	}
+	f := passiveFinder{b: finder, id: cfg.Service}
+	if !cfg.Log {
+		logger = yalogi.LogNull
+	}
+	return passiveapi.NewService(f, passiveapi.SetServiceLogger(logger)), nil
+}
+
+// FinderQueryDNSAPI creates grpc service
+func FinderQueryDNSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*queryapi.Service, error) {
+	if !cfg.Enable {
+		return nil, errors.New("dns query finder api disabled")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("bad config: %v", err)
+	}
+	svc, err := getService(cfg.Service, archive.DNSAPI, finder)
+	if err != nil {
+		return nil, fmt.Errorf("'querydnsapi' service: %v", err)
+	}
+	_, ok := svc.(dnsquery.Finder)
+	if !ok {
+		return nil, fmt.Errorf("can't cast id '%s' to dnsquery.Finder", cfg.Service)
+	}
+	f := queryFinder{b: finder, id: cfg.Service}
+	if !cfg.Log {
+		logger = yalogi.LogNull
+	}
+	return queryapi.NewService(f, queryapi.SetServiceLogger(logger)), nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package factory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/dnsstats"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	"github.com/luids-io/archive/pkg/passivedns"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+)
+
+// proxies resolve the service in each call, so grpc services always use
+// the services running after a reload of the builder.
+
+type dnsArchiver struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p dnsArchiver) SaveResolv(ctx context.Context, r dnsutil.ResolvData) (uuid.UUID, error) {
+	svc, _ := p.b.Service(p.id)
+	a, ok := svc.(dnsutil.Archiver)
+	if !ok {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	return a.SaveResolv(ctx, r)
+}
+
+type dnsFinder struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p dnsFinder) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(dnsutil.Finder)
+	if !ok {
+		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
+	}
+	return f.GetResolv(ctx, id)
+}
+
+func (p dnsFinder) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(dnsutil.Finder)
+	if !ok {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	return f.ListResolvs(ctx, filters, rev, max, next)
+}
+
+type dnsAggregator struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p dnsAggregator) Aggregate(ctx context.Context, filters []dnsutil.ResolvsFilter, groupBy dnsstats.GroupBy, max int) ([]dnsstats.Item, error) {
+	svc, _ := p.b.Service(p.id)
+	a, ok := svc.(dnsstats.Aggregator)
+	if !ok {
+		return nil, dnsutil.ErrUnavailable
+	}
+	return a.Aggregate(ctx, filters, groupBy, max)
+}
+
+type passiveFinder struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p passiveFinder) ListRecords(ctx context.Context, filter passivedns.RecordsFilter, max int, next string) ([]passivedns.Record, string, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(passivedns.Finder)
+	if !ok {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	return f.ListRecords(ctx, filter, max, next)
+}
+
+type queryFinder struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p queryFinder) QueryResolvs(ctx context.Context, filters []dnsquery.Filter, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(dnsquery.Finder)
+	if !ok {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	return f.QueryResolvs(ctx, filters, rev, max, next)
+}
+
+func (p queryFinder) FollowResolvs(ctx context.Context, filters []dnsquery.Filter, fn func(dnsutil.ResolvData) error) error {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(dnsquery.Follower)
+	if !ok {
+		return dnsutil.ErrNotSupported
+	}
+	return f.FollowResolvs(ctx, filters, fn)
+}
+
+type eventArchiver struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p eventArchiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	svc, _ := p.b.Service(p.id)
+	a, ok := svc.(event.Archiver)
+	if !ok {
+		return "", event.ErrUnavailable
+	}
+	return a.SaveEvent(ctx, e)
+}
+
+type eventFinder struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p eventFinder) GetEvent(ctx context.Context, id string) (event.Event, bool, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(eventfinder.Finder)
+	if !ok {
+		return event.Event{}, false, event.ErrUnavailable
+	}
+	return f.GetEvent(ctx, id)
+}
+
+func (p eventFinder) ListEvents(ctx context.Context, filters []eventfinder.EventsFilter, rev bool, max int, next string) ([]event.Event, string, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(eventfinder.Finder)
+	if !ok {
+		return nil, "", event.ErrUnavailable
+	}
+	return f.ListEvents(ctx, filters, rev, max, next)
+}
+
+type tlsArchiver struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p tlsArchiver) get() (tlsutil.Archiver, error) {
+	svc, _ := p.b.Service(p.id)
+	a, ok := svc.(tlsutil.Archiver)
+	if !ok {
+		return nil, tlsutil.ErrUnavailable
+	}
+	return a, nil
+}
+
+func (p tlsArchiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	a, err := p.get()
+	if err != nil {
+		return "", err
+	}
+	return a.SaveConnection(ctx, cn)
+}
+
+func (p tlsArchiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	a, err := p.get()
+	if err != nil {
+		return "", err
+	}
+	return a.SaveCertificate(ctx, cert)
+}
+
+func (p tlsArchiver) StoreRecord(r *tlsutil.RecordData) error {
+	a, err := p.get()
+	if err != nil {
+		return err
+	}
+	return a.StoreRecord(r)
+}
+
+type tlsFinder struct {
+	b  *archive.Builder
+	id string
+}
+
+func (p tlsFinder) get() (tlsfinder.Finder, error) {
+	svc, _ := p.b.Service(p.id)
+	f, ok := svc.(tlsfinder.Finder)
+	if !ok {
+		return nil, tlsutil.ErrUnavailable
+	}
+	return f, nil
+}
+
+func (p tlsFinder) GetConnection(ctx context.Context, id string) (*tlsutil.ConnectionData, bool, error) {
+	f, err := p.get()
+	if err != nil {
+		return nil, false, err
+	}
+	return f.GetConnection(ctx, id)
+}
+
+func (p tlsFinder) ListConnections(ctx context.Context, filters []tlsfinder.ConnectionsFilter, rev bool, max int, next string) ([]*tlsutil.ConnectionData, string, error) {
+	f, err := p.get()
+	if err != nil {
+		return nil, "", err
+	}
+	return f.ListConnections(ctx, filters, rev, max, next)
+}
+
+func (p tlsFinder) GetCertificate(ctx context.Context, digest string) (*tlsutil.CertificateData, bool, error) {
+	f, err := p.get()
+	if err != nil {
+		return nil, false, err
+	}
+	return f.GetCertificate(ctx, digest)
+}
+
+func (p tlsFinder) ListRecords(ctx context.Context, connID string, max int, next string) ([]*tlsutil.RecordData, string, error) {
+	f, err := p.get()
+	if err != nil {
+		return nil, "", err
+	}
+	return f.ListRecords(ctx, connID, max, next)
+}
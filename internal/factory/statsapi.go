@@ -0,0 +1,38 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luids-io/archive/internal/config"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/dnsstats"
+	statsapi "github.com/luids-io/archive/pkg/dnsstats/grpc/stats"
+	"github.com/luids-io/core/yalogi"
+)
+
+// StatsDNSAPI creates grpc service
+func StatsDNSAPI(cfg *config.APICfg, finder *archive.Builder, logger yalogi.Logger) (*statsapi.Service, error) {
+	if !cfg.Enable {
+		return nil, errors.New("dns stats api disabled")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("bad config: %v", err)
+	}
+	svc, err := getService(cfg.Service, archive.DNSAPI, finder)
+	if err != nil {
+		return nil, fmt.Errorf("'statsapi' service: %v", err)
+	}
+	_, ok := svc.(dnsstats.Aggregator)
+	if !ok {
+		return nil, fmt.Errorf("can't cast id '%s' to dnsstats.Aggregator", cfg.Service)
+	}
+	a := dnsAggregator{b: finder, id: cfg.Service}
+	if !cfg.Log {
+		logger = yalogi.LogNull
+	}
+	return statsapi.NewService(a, statsapi.SetServiceLogger(logger)), nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package grpcdns provides the code shared by the grpc clients and services
+// of the dns apis implemented by the archive: stats, passive dns and query.
+//
+// This package is a work in progress and makes no API stability promises.
+package grpcdns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/core/apiservice"
+	"github.com/luids-io/core/grpctls"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceName returns the full name of a grpc service.
+func ServiceName(api, version, service string) string {
+	return fmt.Sprintf("%s.%s.%s", api, version, service)
+}
+
+// ClientOption encapsules options for clients.
+type ClientOption func(*ClientOpts)
+
+// ClientOpts stores the options of clients.
+type ClientOpts struct {
+	Logger    yalogi.Logger
+	CloseConn bool
+}
+
+// NewClientOpts returns the options of a client.
+func NewClientOpts(opt ...ClientOption) ClientOpts {
+	opts := ClientOpts{Logger: yalogi.LogNull, CloseConn: true}
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return func(o *ClientOpts) {
+		o.CloseConn = b
+	}
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return func(o *ClientOpts) {
+		if l != nil {
+			o.Logger = l
+		}
+	}
+}
+
+// Conn wraps the grpc connection of a client.
+type Conn struct {
+	conn      *grpc.ClientConn
+	closeConn bool
+	closed    bool
+}
+
+// NewConn returns a new connection wrapper.
+func NewConn(conn *grpc.ClientConn, opts ClientOpts) *Conn {
+	return &Conn{conn: conn, closeConn: opts.CloseConn}
+}
+
+// Closed returns true if the client is closed.
+func (c *Conn) Closed() bool {
+	return c.closed
+}
+
+// Close closes the client, the grpc connection is closed if CloseConn
+// option is set.
+func (c *Conn) Close() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	c.closed = true
+	if c.closeConn {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// Ping checks connectivity with the api.
+func (c *Conn) Ping() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	st := c.conn.GetState()
+	switch st {
+	case connectivity.TransientFailure:
+		return fmt.Errorf("connection state: %v", st)
+	case connectivity.Shutdown:
+		return fmt.Errorf("connection state: %v", st)
+	}
+	return nil
+}
+
+// ClientError maps grpc errors to dnsutil errors.
+func ClientError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Canceled:
+		return dnsutil.ErrCanceledRequest
+	case codes.InvalidArgument:
+		return dnsutil.ErrBadRequest
+	case codes.Unimplemented:
+		return dnsutil.ErrNotSupported
+	case codes.Internal:
+		return dnsutil.ErrInternal
+	case codes.Unavailable:
+		return dnsutil.ErrUnavailable
+	default:
+		return dnsutil.ErrUnavailable
+	}
+}
+
+// ClientBuilder returns a builder function for the apiservice. Function
+// newClient is called with the logger of the apiservice if log is enabled
+// in the definition, nil otherwise.
+func ClientBuilder(newClient func(conn *grpc.ClientConn, logger yalogi.Logger) apiservice.Service) apiservice.BuildFn {
+	return func(def apiservice.ServiceDef, logger yalogi.Logger) (apiservice.Service, error) {
+		//validates definition
+		err := def.Validate()
+		if err != nil {
+			return nil, err
+		}
+		opts := make([]grpc.DialOption, 0)
+		if def.Metrics {
+			opts = append(opts, grpc.WithUnaryInterceptor(grpc_prometheus.UnaryClientInterceptor))
+			opts = append(opts, grpc.WithStreamInterceptor(grpc_prometheus.StreamClientInterceptor))
+		}
+		//dial grpc
+		dial, err := grpctls.Dial(def.Endpoint, def.ClientCfg(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		if !def.Log {
+			logger = nil
+		}
+		//creates client
+		return newClient(dial, logger), nil
+	}
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption func(*ServiceOpts)
+
+// ServiceOpts stores the options of services.
+type ServiceOpts struct {
+	Logger yalogi.Logger
+}
+
+// NewServiceOpts returns the options of a service.
+func NewServiceOpts(opt ...ServiceOption) ServiceOpts {
+	opts := ServiceOpts{Logger: yalogi.LogNull}
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return func(o *ServiceOpts) {
+		if l != nil {
+			o.Logger = l
+		}
+	}
+}
+
+// ServiceError maps dnsutil errors to grpc errors.
+func ServiceError(err error) error {
+	switch err {
+	case dnsutil.ErrCanceledRequest:
+		return status.Error(codes.Canceled, err.Error())
+	case dnsutil.ErrBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case dnsutil.ErrNotSupported:
+		return status.Error(codes.Unimplemented, err.Error())
+	case dnsutil.ErrUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, dnsutil.ErrInternal.Error())
+	}
+}
+
+// PeerAddr returns the address of the peer of the request.
+func PeerAddr(ctx context.Context) (paddr string) {
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		paddr = p.Addr.String()
+	}
+	return
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package boltdb
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// BackendClass registered.
+const BackendClass = "boltdb"
+
+//boltBackend implements archive.Backend interface
+type boltBackend struct {
+	id string
+	db *bolt.DB
+}
+
+func (b *boltBackend) ID() string {
+	return b.id
+}
+
+func (b *boltBackend) Class() string {
+	return BackendClass
+}
+
+// Session returns the embedded database handler.
+func (b *boltBackend) Session() interface{} {
+	return b.db
+}
+
+// Ping checks that database is open.
+func (b *boltBackend) Ping() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package boltdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/core/option"
+)
+
+// DefaultTimeout for getting the file lock.
+const DefaultTimeout = 5 * time.Second
+
+// Builder returns a builder function.
+func Builder() archive.BuildBackendFn {
+	return func(b *archive.Builder, def archive.BackendDef) (archive.Backend, error) {
+		if def.URL == "" {
+			return nil, errors.New("'url' is required")
+		}
+		// url is a database file, scheme is optional
+		path := strings.TrimPrefix(def.URL, "file://")
+		err := os.MkdirAll(filepath.Dir(path), 0750)
+		if err != nil {
+			return nil, fmt.Errorf("creating dir '%s': %v", filepath.Dir(path), err)
+		}
+		// parse options
+		bopts := &bolt.Options{Timeout: DefaultTimeout}
+		nosync := false
+		if def.Opts != nil {
+			timeout, ok, err := option.Int(def.Opts, "timeout")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				bopts.Timeout = time.Duration(timeout) * time.Second
+			}
+			nosyncOpt, ok, err := option.Bool(def.Opts, "nosync")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				nosync = nosyncOpt
+			}
+		}
+		// open database
+		db, err := bolt.Open(path, 0600, bopts)
+		if err != nil {
+			return nil, fmt.Errorf("opening boltdb '%s': %v", path, err)
+		}
+		db.NoSync = nosync
+		backend := &boltBackend{id: def.ID, db: db}
+		b.OnShutdown(func() error {
+			return db.Close()
+		})
+		return backend, nil
+	}
+}
+
+func init() {
+	archive.RegisterBackendBuilder(BackendClass, Builder())
+	archive.RegisterBackendSpec(BackendClass, archive.BackendSpec{
+		URL:       true,
+		Exclusive: true,
+		Options: []archive.OptionSpec{
+			{Name: "timeout", Type: archive.OptInt, Description: "timeout in seconds to get the lock"},
+			{Name: "nosync", Type: archive.OptBool, Description: "don't sync after commits"},
+		},
+	})
+}
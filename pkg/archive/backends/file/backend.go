@@ -0,0 +1,49 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// BackendClass registered.
+const BackendClass = "file"
+
+//fileBackend implements archive.Backend interface
+type fileBackend struct {
+	id  string
+	dir string
+}
+
+func (b *fileBackend) ID() string {
+	return b.id
+}
+
+func (b *fileBackend) Class() string {
+	return BackendClass
+}
+
+// Session returns base directory.
+func (b *fileBackend) Session() interface{} {
+	return b.dir
+}
+
+// Ping checks that base directory is writable.
+func (b *fileBackend) Ping() error {
+	info, err := os.Stat(b.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("not a directory")
+	}
+	f, err := ioutil.TempFile(b.dir, ".ping")
+	if err != nil {
+		return fmt.Errorf("dir not writable: %v", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/luids-io/archive/pkg/archive"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildBackendFn {
+	return func(b *archive.Builder, def archive.BackendDef) (archive.Backend, error) {
+		if def.URL == "" {
+			return nil, errors.New("'url' is required")
+		}
+		// url is a base directory, scheme is optional
+		dir := strings.TrimPrefix(def.URL, "file://")
+		err := os.MkdirAll(dir, 0750)
+		if err != nil {
+			return nil, fmt.Errorf("creating dir '%s': %v", dir, err)
+		}
+		backend := &fileBackend{id: def.ID, dir: dir}
+		return backend, nil
+	}
+}
+
+func init() {
+	archive.RegisterBackendBuilder(BackendClass, Builder())
+	archive.RegisterBackendSpec(BackendClass, archive.BackendSpec{
+		URL: true,
+	})
+}
@@ -4,6 +4,8 @@ package mongodb
 
 import (
 	"github.com/globalsign/mgo"
+
+	"github.com/luids-io/archive/pkg/mongoutil"
 )
 
 // BackendClass registered.
@@ -13,6 +15,7 @@ const BackendClass = "mongodb"
 type mdbBackend struct {
 	id      string
 	session *mgo.Session
+	monitor *mongoutil.Monitor
 }
 
 func (b *mdbBackend) ID() string {
@@ -30,3 +33,13 @@ func (b *mdbBackend) Session() interface{} {
 func (b *mdbBackend) Ping() error {
 	return b.session.Ping()
 }
+
+// Available implements mongoutil.Health interface.
+func (b *mdbBackend) Available() bool {
+	return b.monitor.Available()
+}
+
+// Report implements mongoutil.Health interface.
+func (b *mdbBackend) Report(err error) {
+	b.monitor.Report(err)
+}
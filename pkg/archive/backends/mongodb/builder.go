@@ -3,27 +3,72 @@
 package mongodb
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/globalsign/mgo"
 
 	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/core/option"
 )
 
+// DefaultDialTimeout is used if dial timeout is not set.
+const DefaultDialTimeout = 10 * time.Second
+
 // Builder returns a builder function.
 func Builder() archive.BuildBackendFn {
 	return func(b *archive.Builder, def archive.BackendDef) (archive.Backend, error) {
 		if def.URL == "" {
 			return nil, errors.New("'url' is required")
 		}
+		info, err := mgo.ParseURL(def.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing url: %v", err)
+		}
+		copts, err := ParseConnOpts(def)
+		if err != nil {
+			return nil, err
+		}
+		setDialInfo(info, copts)
 		// create session with mgo
-		session, err := mgo.Dial(def.URL)
+		session, err := mgo.DialWithInfo(info)
 		if err != nil {
 			return nil, fmt.Errorf("dialing with mongodb '%s': %v", def.URL, err)
 		}
-		backend := &mdbBackend{id: def.ID, session: session}
+		// health monitor
+		interval, _, err := option.Int(def.Opts, "healthcheck")
+		if err != nil {
+			session.Close()
+			return nil, err
+		}
+		maxBackoff, _, err := option.Int(def.Opts, "maxbackoff")
+		if err != nil {
+			session.Close()
+			return nil, err
+		}
+		logger := b.Logger()
+		up := metrics.BackendUp.WithLabelValues(def.ID, BackendClass)
+		up.Set(1)
+		monitor := mongoutil.NewMonitor(session,
+			time.Duration(interval)*time.Second, time.Duration(maxBackoff)*time.Second,
+			func(available bool, err error) {
+				if available {
+					logger.Infof("%s: connection restored", def.ID)
+					up.Set(1)
+					return
+				}
+				logger.Warnf("%s: connection lost: %v", def.ID, err)
+				up.Set(0)
+			})
+		backend := &mdbBackend{id: def.ID, session: session, monitor: monitor}
 		b.OnShutdown(func() error {
+			monitor.Close()
 			session.Close()
 			return nil
 		})
@@ -31,6 +76,85 @@ func Builder() archive.BuildBackendFn {
 	}
 }
 
+// setDialInfo sets connection options in dial info, values in options
+// override values in url.
+func setDialInfo(info *mgo.DialInfo, c ConnOpts) {
+	info.Timeout = DefaultDialTimeout
+	if c.DialTimeout > 0 {
+		info.Timeout = c.DialTimeout
+	}
+	if c.SocketTimeout > 0 {
+		info.ReadTimeout = c.SocketTimeout
+		info.WriteTimeout = c.SocketTimeout
+	}
+	if c.Username != "" {
+		info.Username = c.Username
+	}
+	if c.Password != "" {
+		info.Password = c.Password
+	}
+	if c.AuthSource != "" {
+		info.Source = c.AuthSource
+	}
+	if c.AuthMechanism != "" {
+		info.Mechanism = c.AuthMechanism
+	}
+	if c.ReplicaSet != "" {
+		info.ReplicaSetName = c.ReplicaSet
+	}
+	if c.PoolSize > 0 {
+		info.PoolLimit = c.PoolSize
+	}
+	if c.MinPoolSize > 0 {
+		info.MinPoolSize = c.MinPoolSize
+	}
+	if c.ReadPreference != "" {
+		info.ReadPreference = &mgo.ReadPreference{Mode: readModes[c.ReadPreference]}
+	}
+	if c.WriteConcern == "majority" {
+		info.Safe.WMode = "majority"
+	} else if c.WriteConcern != "" {
+		info.Safe.W, _ = strconv.Atoi(c.WriteConcern)
+	}
+	if c.Journal {
+		info.Safe.J = true
+	}
+	if c.WTimeout > 0 {
+		info.Safe.WTimeout = int(c.WTimeout / time.Millisecond)
+	}
+	if c.TLS != nil {
+		timeout := info.Timeout
+		info.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			cfg := c.TLS.Clone()
+			if cfg.ServerName == "" {
+				host, _, err := net.SplitHostPort(addr.String())
+				if err != nil {
+					return nil, err
+				}
+				cfg.ServerName = host
+			}
+			dialer := &net.Dialer{Timeout: timeout}
+			return tls.DialWithDialer(dialer, "tcp", addr.String(), cfg)
+		}
+	}
+}
+
+var readModes = map[string]mgo.Mode{
+	"primary":            mgo.Primary,
+	"primarypreferred":   mgo.PrimaryPreferred,
+	"secondary":          mgo.Secondary,
+	"secondarypreferred": mgo.SecondaryPreferred,
+	"nearest":            mgo.Nearest,
+}
+
 func init() {
 	archive.RegisterBackendBuilder(BackendClass, Builder())
+	archive.RegisterBackendSpec(BackendClass, archive.BackendSpec{
+		URL:     true,
+		TLS:     true,
+		Options: append([]archive.OptionSpec{
+			{Name: "healthcheck", Type: archive.OptInt, Description: "interval in seconds of health checks"},
+			{Name: "maxbackoff", Type: archive.OptInt, Description: "max interval in seconds between reconnections"},
+		}, OptionSpecs...),
+	})
 }
@@ -0,0 +1,195 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package mongodb
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/core/grpctls"
+	"github.com/luids-io/core/option"
+)
+
+// ConnOpts stores connection options of mongodb backends.
+type ConnOpts struct {
+	Username      string
+	Password      string
+	AuthSource    string
+	AuthMechanism string
+	ReplicaSet    string
+	// ReadPreference: primary, primarypreferred, secondary,
+	// secondarypreferred or nearest
+	ReadPreference string
+	// WriteConcern: majority or number of servers
+	WriteConcern  string
+	Journal       bool
+	WTimeout      time.Duration
+	PoolSize      int
+	MinPoolSize   int
+	DialTimeout   time.Duration
+	SocketTimeout time.Duration
+	// TLS is nil if tls is not used
+	TLS *tls.Config
+}
+
+// OptionSpecs declares connection options of mongodb backends.
+var OptionSpecs = []archive.OptionSpec{
+	{Name: "username", Type: archive.OptString, Description: "user for authentication"},
+	{Name: "password", Type: archive.OptString, Description: "password for authentication"},
+	{Name: "passwordfile", Type: archive.OptString, Description: "file with the password for authentication"},
+	{Name: "authsource", Type: archive.OptString, Description: "database used for authentication"},
+	{Name: "authmechanism", Type: archive.OptString, Description: "authentication mechanism"},
+	{Name: "replicaset", Type: archive.OptString, Description: "name of the replica set"},
+	{Name: "readpreference", Type: archive.OptString, Description: "read preference",
+		Enum: []string{"primary", "primarypreferred", "secondary", "secondarypreferred", "nearest"}},
+	{Name: "writeconcern", Type: archive.OptString, Description: "write concern: majority or number of servers"},
+	{Name: "journal", Type: archive.OptBool, Description: "wait for journal commit"},
+	{Name: "wtimeout", Type: archive.OptInt, Description: "write concern timeout in seconds"},
+	{Name: "poolsize", Type: archive.OptInt, Description: "max connections per server"},
+	{Name: "minpoolsize", Type: archive.OptInt, Description: "min connections per server"},
+	{Name: "dialtimeout", Type: archive.OptInt, Description: "dial timeout in seconds"},
+	{Name: "sockettimeout", Type: archive.OptInt, Description: "socket timeout in seconds"},
+}
+
+// ParseConnOpts returns the connection options from a backend definition.
+func ParseConnOpts(def archive.BackendDef) (ConnOpts, error) {
+	var c ConnOpts
+	var err error
+	strOpts := []struct {
+		name  string
+		value *string
+	}{
+		{"username", &c.Username},
+		{"password", &c.Password},
+		{"authsource", &c.AuthSource},
+		{"authmechanism", &c.AuthMechanism},
+		{"replicaset", &c.ReplicaSet},
+		{"readpreference", &c.ReadPreference},
+		{"writeconcern", &c.WriteConcern},
+	}
+	for _, o := range strOpts {
+		*o.value, _, err = option.String(def.Opts, o.name)
+		if err != nil {
+			return c, err
+		}
+	}
+	passwordFile, ok, err := option.String(def.Opts, "passwordfile")
+	if err != nil {
+		return c, err
+	}
+	if ok {
+		if c.Password != "" {
+			return c, errors.New("'password' and 'passwordfile' are exclusive")
+		}
+		data, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return c, fmt.Errorf("'passwordfile': %v", err)
+		}
+		c.Password = strings.TrimSpace(string(data))
+	}
+	if c.ReadPreference != "" {
+		c.ReadPreference = strings.ToLower(c.ReadPreference)
+		switch c.ReadPreference {
+		case "primary", "primarypreferred", "secondary", "secondarypreferred", "nearest":
+		default:
+			return c, fmt.Errorf("'readpreference': invalid value '%s'", c.ReadPreference)
+		}
+	}
+	if c.WriteConcern != "" && c.WriteConcern != "majority" {
+		w, err := strconv.Atoi(c.WriteConcern)
+		if err != nil || w < 0 {
+			return c, fmt.Errorf("'writeconcern': invalid value '%s'", c.WriteConcern)
+		}
+	}
+	c.Journal, _, err = option.Bool(def.Opts, "journal")
+	if err != nil {
+		return c, err
+	}
+	intOpts := []struct {
+		name  string
+		value *int
+	}{
+		{"poolsize", &c.PoolSize},
+		{"minpoolsize", &c.MinPoolSize},
+	}
+	for _, o := range intOpts {
+		*o.value, _, err = option.Int(def.Opts, o.name)
+		if err != nil {
+			return c, err
+		}
+		if *o.value < 0 {
+			return c, fmt.Errorf("'%s': invalid value", o.name)
+		}
+	}
+	durOpts := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"wtimeout", &c.WTimeout},
+		{"dialtimeout", &c.DialTimeout},
+		{"sockettimeout", &c.SocketTimeout},
+	}
+	for _, o := range durOpts {
+		secs, _, err := option.Int(def.Opts, o.name)
+		if err != nil {
+			return c, err
+		}
+		if secs < 0 {
+			return c, fmt.Errorf("'%s': invalid value", o.name)
+		}
+		*o.value = time.Duration(secs) * time.Second
+	}
+	if def.Client != nil && !def.Client.Empty() {
+		c.TLS, err = TLSConfig(*def.Client)
+		if err != nil {
+			return c, fmt.Errorf("'tls': %v", err)
+		}
+	}
+	return c, nil
+}
+
+// TLSConfig returns a tls configuration from a client configuration.
+// If server name is empty, it must be set by the dialer.
+func TLSConfig(cfg grpctls.ClientCfg) (*tls.Config, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var certPool *x509.CertPool
+	if cfg.UseSystemCAs {
+		certPool, err = x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("can't get system cert pool: %v", err)
+		}
+	} else {
+		certPool = x509.NewCertPool()
+	}
+	for _, file := range []string{cfg.CACert, cfg.ServerCert} {
+		if file == "" {
+			continue
+		}
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read certificate '%s': %v", file, err)
+		}
+		if ok := certPool.AppendCertsFromPEM(pem); !ok {
+			return nil, fmt.Errorf("failed to append certificate '%s'", file)
+		}
+	}
+	tlsConfig := &tls.Config{RootCAs: certPool, ServerName: cfg.ServerName}
+	if cfg.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package mongodriver implements a mongodb backend using the official
+// driver. It accepts the same options of mongodb backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package mongodriver
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+	"github.com/luids-io/archive/pkg/mongoutil"
+)
+
+// BackendClass registered.
+const BackendClass = "mongodriver"
+
+//mdBackend implements archive.Backend interface
+type mdBackend struct {
+	id      string
+	client  *mongo.Client
+	monitor *mongoutil.Monitor
+}
+
+func (b *mdBackend) ID() string {
+	return b.id
+}
+
+func (b *mdBackend) Class() string {
+	return BackendClass
+}
+
+func (b *mdBackend) Session() interface{} {
+	return b.client
+}
+
+func (b *mdBackend) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	return b.client.Ping(ctx, nil)
+}
+
+// Available implements mongoutil.Health interface.
+func (b *mdBackend) Available() bool {
+	return b.monitor.Available()
+}
+
+// Report implements mongoutil.Health interface.
+func (b *mdBackend) Report(err error) {
+	b.monitor.Report(err)
+}
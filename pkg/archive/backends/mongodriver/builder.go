@@ -0,0 +1,186 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package mongodriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/backends/mongodb"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+	"github.com/luids-io/core/option"
+)
+
+// DefaultDialTimeout is used if dial timeout is not set.
+const DefaultDialTimeout = mongodb.DefaultDialTimeout
+
+// Builder returns a builder function.
+func Builder() archive.BuildBackendFn {
+	return func(b *archive.Builder, def archive.BackendDef) (archive.Backend, error) {
+		if def.URL == "" {
+			return nil, errors.New("'url' is required")
+		}
+		copts, err := mongodb.ParseConnOpts(def)
+		if err != nil {
+			return nil, err
+		}
+		interval, _, err := option.Int(def.Opts, "healthcheck")
+		if err != nil {
+			return nil, err
+		}
+		maxBackoff, _, err := option.Int(def.Opts, "maxbackoff")
+		if err != nil {
+			return nil, err
+		}
+		clientOpts := options.Client().ApplyURI(def.URL)
+		err = setClientOpts(clientOpts, copts)
+		if err != nil {
+			return nil, err
+		}
+		err = clientOpts.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("parsing url: %v", err)
+		}
+		// connect and check server like mgo dial does
+		ctx, cancel := context.WithTimeout(context.Background(), *clientOpts.ConnectTimeout)
+		defer cancel()
+		client, err := mongo.Connect(ctx, clientOpts)
+		if err != nil {
+			return nil, fmt.Errorf("dialing with mongodb '%s': %v", def.URL, err)
+		}
+		err = client.Ping(ctx, nil)
+		if err != nil {
+			client.Disconnect(context.Background())
+			return nil, fmt.Errorf("dialing with mongodb '%s': %v", def.URL, err)
+		}
+		// health monitor
+		logger := b.Logger()
+		up := metrics.BackendUp.WithLabelValues(def.ID, BackendClass)
+		up.Set(1)
+		monitor := mongodriverutil.NewMonitor(client,
+			time.Duration(interval)*time.Second, time.Duration(maxBackoff)*time.Second,
+			func(available bool, err error) {
+				if available {
+					logger.Infof("%s: connection restored", def.ID)
+					up.Set(1)
+					return
+				}
+				logger.Warnf("%s: connection lost: %v", def.ID, err)
+				up.Set(0)
+			})
+		backend := &mdBackend{id: def.ID, client: client, monitor: monitor}
+		b.OnShutdown(func() error {
+			monitor.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+			defer cancel()
+			return client.Disconnect(ctx)
+		})
+		return backend, nil
+	}
+}
+
+// setClientOpts sets connection options in client options, values in
+// options override values in url.
+func setClientOpts(o *options.ClientOptions, c mongodb.ConnOpts) error {
+	timeout := DefaultDialTimeout
+	if c.DialTimeout > 0 {
+		timeout = c.DialTimeout
+	}
+	o.SetConnectTimeout(timeout)
+	o.SetServerSelectionTimeout(timeout)
+	if c.SocketTimeout > 0 {
+		o.SetSocketTimeout(c.SocketTimeout)
+	}
+	if c.Username != "" || c.Password != "" || c.AuthSource != "" || c.AuthMechanism != "" {
+		var cred options.Credential
+		if o.Auth != nil {
+			cred = *o.Auth
+		}
+		if c.Username != "" {
+			cred.Username = c.Username
+		}
+		if c.Password != "" {
+			cred.Password = c.Password
+			cred.PasswordSet = true
+		}
+		if c.AuthSource != "" {
+			cred.AuthSource = c.AuthSource
+		}
+		if c.AuthMechanism != "" {
+			cred.AuthMechanism = c.AuthMechanism
+		}
+		o.SetAuth(cred)
+	}
+	if c.ReplicaSet != "" {
+		o.SetReplicaSet(c.ReplicaSet)
+	}
+	if c.PoolSize > 0 {
+		o.SetMaxPoolSize(uint64(c.PoolSize))
+	}
+	if c.MinPoolSize > 0 {
+		o.SetMinPoolSize(uint64(c.MinPoolSize))
+	}
+	if c.ReadPreference != "" {
+		rp, err := readpref.New(readModes[c.ReadPreference])
+		if err != nil {
+			return fmt.Errorf("'readpreference': %v", err)
+		}
+		o.SetReadPreference(rp)
+	}
+	if c.WriteConcern != "" || c.Journal || c.WTimeout > 0 {
+		wc := &writeconcern.WriteConcern{}
+		if o.WriteConcern != nil {
+			*wc = *o.WriteConcern
+		}
+		if c.WriteConcern == "majority" {
+			wc.W = "majority"
+		} else if c.WriteConcern != "" {
+			wc.W, _ = strconv.Atoi(c.WriteConcern)
+		}
+		if c.Journal {
+			j := true
+			wc.Journal = &j
+		}
+		if c.WTimeout > 0 {
+			wc.WTimeout = c.WTimeout
+		}
+		o.SetWriteConcern(wc)
+	}
+	if c.TLS != nil {
+		// driver sets server name from host if it is empty
+		o.SetTLSConfig(c.TLS)
+	}
+	// documents are decoded in maps as mgo does
+	o.SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return nil
+}
+
+var readModes = map[string]readpref.Mode{
+	"primary":            readpref.PrimaryMode,
+	"primarypreferred":   readpref.PrimaryPreferredMode,
+	"secondary":          readpref.SecondaryMode,
+	"secondarypreferred": readpref.SecondaryPreferredMode,
+	"nearest":            readpref.NearestMode,
+}
+
+func init() {
+	archive.RegisterBackendBuilder(BackendClass, Builder())
+	archive.RegisterBackendSpec(BackendClass, archive.BackendSpec{
+		URL: true,
+		TLS: true,
+		Options: append([]archive.OptionSpec{
+			{Name: "healthcheck", Type: archive.OptInt, Description: "interval in seconds of health checks"},
+			{Name: "maxbackoff", Type: archive.OptInt, Description: "max interval in seconds between reconnections"},
+		}, mongodb.OptionSpecs...),
+	})
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package postgres
+
+import (
+	"database/sql"
+)
+
+// BackendClass registered.
+const BackendClass = "postgres"
+
+//pgBackend implements archive.Backend interface
+type pgBackend struct {
+	id string
+	db *sql.DB
+}
+
+func (b *pgBackend) ID() string {
+	return b.id
+}
+
+func (b *pgBackend) Class() string {
+	return BackendClass
+}
+
+// Session returns the *sql.DB pool.
+func (b *pgBackend) Session() interface{} {
+	return b.db
+}
+
+func (b *pgBackend) Ping() error {
+	return b.db.Ping()
+}
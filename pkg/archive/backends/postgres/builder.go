@@ -0,0 +1,78 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	// postgresql driver
+	_ "github.com/lib/pq"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/core/option"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildBackendFn {
+	return func(b *archive.Builder, def archive.BackendDef) (archive.Backend, error) {
+		if def.URL == "" {
+			return nil, errors.New("'url' is required")
+		}
+		// create pool with lib/pq
+		db, err := sql.Open("postgres", def.URL)
+		if err != nil {
+			return nil, fmt.Errorf("opening postgres: %v", err)
+		}
+		if def.Opts != nil {
+			maxopen, ok, err := option.Int(def.Opts, "maxopen")
+			if err != nil {
+				db.Close()
+				return nil, err
+			}
+			if ok {
+				db.SetMaxOpenConns(maxopen)
+			}
+			maxidle, ok, err := option.Int(def.Opts, "maxidle")
+			if err != nil {
+				db.Close()
+				return nil, err
+			}
+			if ok {
+				db.SetMaxIdleConns(maxidle)
+			}
+			lifetime, ok, err := option.Int(def.Opts, "maxlifetime")
+			if err != nil {
+				db.Close()
+				return nil, err
+			}
+			if ok {
+				db.SetConnMaxLifetime(time.Duration(lifetime) * time.Second)
+			}
+		}
+		err = db.Ping()
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("connecting with postgres: %v", err)
+		}
+		backend := &pgBackend{id: def.ID, db: db}
+		b.OnShutdown(func() error {
+			return db.Close()
+		})
+		return backend, nil
+	}
+}
+
+func init() {
+	archive.RegisterBackendBuilder(BackendClass, Builder())
+	archive.RegisterBackendSpec(BackendClass, archive.BackendSpec{
+		URL: true,
+		Options: []archive.OptionSpec{
+			{Name: "maxopen", Type: archive.OptInt, Description: "max open connections"},
+			{Name: "maxidle", Type: archive.OptInt, Description: "max idle connections"},
+			{Name: "maxlifetime", Type: archive.OptInt, Description: "max lifetime of connections in seconds"},
+		},
+	})
+}
@@ -5,19 +5,46 @@ package archive
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 
+	"github.com/luids-io/archive/pkg/archive/metrics"
 	"github.com/luids-io/core/yalogi"
 )
 
 // Builder constructs backends and services from definitions.
 type Builder struct {
-	opts     buildOpts
-	logger   yalogi.Logger
-	backends map[string]Backend
-	services map[string]Service
-	startup  []func() error
-	shutdown []func() error
+	opts   buildOpts
+	logger yalogi.Logger
+
+	mu          sync.RWMutex
+	backends    map[string]Backend
+	services    map[string]Service
+	backendDefs map[string]BackendDef
+	serviceDefs map[string]ServiceDef
+	startup     []hook
+	shutdown    []hook
+	started     bool
+	// building stores the item in construction
+	building ref
+	// refs stores the items used by each item when it was built
+	refsMu sync.Mutex
+	refs   map[ref][]ref
+
+	reloadMu sync.Mutex
+}
+
+// ref identifies a backend or a service.
+type ref struct {
+	service bool
+	id      string
+}
+
+// hook is a function registered by the item id.
+type hook struct {
+	owner ref
+	fn    func() error
 }
 
 // BuilderOption is used for builder configuration.
@@ -43,27 +70,58 @@ func NewBuilder(opt ...BuilderOption) *Builder {
 		o(&opts)
 	}
 	return &Builder{
-		opts:     opts,
-		logger:   opts.logger,
-		backends: make(map[string]Backend),
-		services: make(map[string]Service),
-		startup:  make([]func() error, 0),
-		shutdown: make([]func() error, 0),
+		opts:        opts,
+		logger:      opts.logger,
+		backends:    make(map[string]Backend),
+		services:    make(map[string]Service),
+		backendDefs: make(map[string]BackendDef),
+		serviceDefs: make(map[string]ServiceDef),
+		startup:     make([]hook, 0),
+		shutdown:    make([]hook, 0),
+		refs:        make(map[ref][]ref),
 	}
 }
 
 // Backend returns the Backend with the id.
 func (b *Builder) Backend(id string) (Backend, bool) {
+	b.mu.RLock()
 	ba, ok := b.backends[id]
+	building := b.building
+	b.mu.RUnlock()
+	if ok {
+		b.addRef(building, ref{id: id})
+	}
 	return ba, ok
 }
 
 // Service returns the Service with the id.
 func (b *Builder) Service(id string) (Service, bool) {
+	b.mu.RLock()
 	svc, ok := b.services[id]
+	building := b.building
+	b.mu.RUnlock()
+	if ok {
+		b.addRef(building, ref{service: true, id: id})
+	}
 	return svc, ok
 }
 
+// addRef registers that the item in construction uses other item.
+func (b *Builder) addRef(from, to ref) {
+	if from.id == "" {
+		return
+	}
+	b.refsMu.Lock()
+	b.refs[from] = append(b.refs[from], to)
+	b.refsMu.Unlock()
+}
+
+func (b *Builder) setBuilding(r ref) {
+	b.mu.Lock()
+	b.building = r
+	b.mu.Unlock()
+}
+
 // BuildBackend creates a Backend using the definition passed as param.
 func (b *Builder) BuildBackend(def BackendDef) (Backend, error) {
 	b.logger.Debugf("building '%s' class '%s'", def.ID, def.Class)
@@ -71,7 +129,7 @@ func (b *Builder) BuildBackend(def BackendDef) (Backend, error) {
 		return nil, errors.New("id field is required")
 	}
 	//check if exists
-	_, ok := b.backends[def.ID]
+	_, ok := b.Backend(def.ID)
 	if ok {
 		return nil, errors.New("'%s' exists")
 	}
@@ -84,12 +142,17 @@ func (b *Builder) BuildBackend(def BackendDef) (Backend, error) {
 	if !ok {
 		return nil, fmt.Errorf("can't find a builder for '%s' in '%s'", def.Class, def.ID)
 	}
+	b.setBuilding(ref{id: def.ID})
 	n, err := customb(b, def) //builds
+	b.setBuilding(ref{})
 	if err != nil {
 		return nil, fmt.Errorf("building '%s': %v", def.ID, err)
 	}
 	//register
+	b.mu.Lock()
 	b.backends[def.ID] = n
+	b.backendDefs[def.ID] = def
+	b.mu.Unlock()
 	return n, nil
 }
 
@@ -100,7 +163,7 @@ func (b *Builder) BuildService(def ServiceDef) (Service, error) {
 		return nil, errors.New("id field is required")
 	}
 	//check if exists
-	_, ok := b.services[def.ID]
+	_, ok := b.Service(def.ID)
 	if ok {
 		return nil, errors.New("'%s' exists")
 	}
@@ -113,64 +176,327 @@ func (b *Builder) BuildService(def ServiceDef) (Service, error) {
 	if !ok {
 		return nil, fmt.Errorf("can't find a builder for '%s' in '%s'", def.Class, def.ID)
 	}
+	b.setBuilding(ref{service: true, id: def.ID})
 	n, err := customb(b, def) //builds
+	b.setBuilding(ref{})
 	if err != nil {
 		return nil, fmt.Errorf("building '%s': %v", def.ID, err)
 	}
 	//register
+	b.mu.Lock()
 	b.services[def.ID] = n
+	b.serviceDefs[def.ID] = def
+	b.mu.Unlock()
 	return n, nil
 }
 
 // OnStartup registers the functions that will be executed during startup.
 func (b *Builder) OnStartup(f func() error) {
-	b.startup = append(b.startup, f)
+	b.mu.Lock()
+	b.startup = append(b.startup, hook{owner: b.building, fn: f})
+	b.mu.Unlock()
 }
 
 // OnShutdown registers the functions that will be executed during shutdown.
 func (b *Builder) OnShutdown(f func() error) {
-	b.shutdown = append(b.shutdown, f)
+	b.mu.Lock()
+	b.shutdown = append(b.shutdown, hook{owner: b.building, fn: f})
+	b.mu.Unlock()
 }
 
-// Start executes all registered functions.
+// Start executes all registered functions. Items are started after the
+// items they depend on. If an item fails, the items already started are
+// shut down and a MultiError is returned.
 func (b *Builder) Start() error {
 	b.logger.Infof("starting archive services")
-	var ret error
-	for _, f := range b.startup {
-		err := f()
-		if err != nil {
-			return err
-		}
+	b.mu.RLock()
+	startup := b.sortHooks(b.startup)
+	shutdown := b.sortHooks(b.shutdown)
+	b.mu.RUnlock()
+	err := runStartup(startup, shutdown)
+	if err != nil {
+		return err
 	}
-	return ret
+	b.mu.Lock()
+	b.started = true
+	b.mu.Unlock()
+	return nil
 }
 
-// Shutdown executes all registered functions.
+// Shutdown executes all registered functions. Items are shut down before
+// the items they depend on. It returns a MultiError with the errors.
 func (b *Builder) Shutdown() error {
 	b.logger.Infof("shutting down archive services")
-	errs := make([]string, 0, len(b.shutdown))
-	for i := len(b.shutdown) - 1; i >= 0; i-- {
-		fn := b.shutdown[i]
-		err := fn()
-		if err != nil {
-			errs = append(errs, err.Error())
+	b.mu.Lock()
+	hooks := b.sortHooks(b.shutdown)
+	b.started = false
+	b.mu.Unlock()
+	return runShutdown("shutdown", hooks).errorOrNil()
+}
+
+// Reload rebuilds backends and services from the definitions passed as
+// params. Items are compared by id: unchanged ones keep running, new ones
+// are built and started, and removed or changed ones are replaced and shut
+// down. Services that use a replaced item are also rebuilt. If something
+// fails, the running items are kept.
+//
+// Replaced items of exclusive classes, and the items that use them, are
+// shut down before building the new ones. If something fails, these items
+// are removed and they will be built in the next reload.
+func (b *Builder) Reload(backends []BackendDef, services []ServiceDef) error {
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
+	b.logger.Infof("reloading archive services")
+
+	b.mu.RLock()
+	started := b.started
+	// computes changed items
+	dirty := make(map[ref]bool)
+	newIDs := make(map[ref]bool)
+	for _, def := range backends {
+		if def.Disabled {
+			continue
+		}
+		r := ref{id: def.ID}
+		newIDs[r] = true
+		old, ok := b.backendDefs[def.ID]
+		if ok && !reflect.DeepEqual(old, def) {
+			dirty[r] = true
+		}
+	}
+	for _, def := range services {
+		if def.Disabled {
+			continue
+		}
+		r := ref{service: true, id: def.ID}
+		newIDs[r] = true
+		old, ok := b.serviceDefs[def.ID]
+		if ok && !reflect.DeepEqual(old, def) {
+			dirty[r] = true
+		}
+	}
+	for id := range b.backendDefs {
+		if r := (ref{id: id}); !newIDs[r] {
+			dirty[r] = true
+		}
+	}
+	for id := range b.serviceDefs {
+		if r := (ref{service: true, id: id}); !newIDs[r] {
+			dirty[r] = true
+		}
+	}
+	b.refsMu.Lock()
+	for changed := true; changed; {
+		changed = false
+		for from, tos := range b.refs {
+			if dirty[from] {
+				continue
+			}
+			for _, to := range tos {
+				if dirty[to] {
+					dirty[from] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	// computes replaced items that must be shut down before building
+	early := make(map[ref]bool)
+	for r := range dirty {
+		if b.exclusive(r) {
+			early[r] = true
+		}
+	}
+	for changed := len(early) > 0; changed; {
+		changed = false
+		for from, tos := range b.refs {
+			if early[from] {
+				continue
+			}
+			for _, to := range tos {
+				if early[to] {
+					early[from] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	b.refsMu.Unlock()
+	// prepares a builder with the items that are kept
+	nb := NewBuilder(SetLogger(b.logger))
+	for id, ba := range b.backends {
+		if !dirty[ref{id: id}] {
+			nb.backends[id] = ba
+			nb.backendDefs[id] = b.backendDefs[id]
+		}
+	}
+	for id, svc := range b.services {
+		if !dirty[ref{service: true, id: id}] {
+			nb.services[id] = svc
+			nb.serviceDefs[id] = b.serviceDefs[id]
 		}
 	}
+	b.mu.RUnlock()
+
+	// shuts down exclusive items
+	if len(early) > 0 {
+		b.mu.Lock()
+		hooks := make([]hook, 0)
+		shutdown := make([]hook, 0, len(b.shutdown))
+		for _, h := range b.sortHooks(b.shutdown) {
+			if early[h.owner] {
+				hooks = append(hooks, h)
+				continue
+			}
+			shutdown = append(shutdown, h)
+		}
+		b.shutdown = shutdown
+		b.mu.Unlock()
+		errs := runShutdown("shutdown", hooks)
+		if len(errs) > 0 {
+			b.logger.Warnf("shutting down replaced items: %v", errs)
+		}
+	}
+
+	// builds new and changed items
+	err := nb.buildDefs(backends, services)
+	if err == nil && started {
+		err = nb.Start()
+	}
+	if err != nil {
+		nb.Shutdown()
+		b.removeItems(early)
+		return err
+	}
+
+	// swaps the items
+	b.mu.Lock()
+	old := make([]hook, 0)
+	startup := make([]hook, 0, len(b.startup))
+	for _, h := range b.startup {
+		if !dirty[h.owner] {
+			startup = append(startup, h)
+		}
+	}
+	shutdown := make([]hook, 0, len(b.shutdown))
+	for _, h := range b.sortHooks(b.shutdown) {
+		if dirty[h.owner] {
+			old = append(old, h)
+			continue
+		}
+		shutdown = append(shutdown, h)
+	}
+	b.backends, b.backendDefs = nb.backends, nb.backendDefs
+	b.services, b.serviceDefs = nb.services, nb.serviceDefs
+	b.startup = append(startup, nb.startup...)
+	b.shutdown = append(shutdown, nb.shutdown...)
+	b.mu.Unlock()
+
+	b.refsMu.Lock()
+	for r := range dirty {
+		delete(b.refs, r)
+	}
+	for from, tos := range nb.refs {
+		b.refs[from] = tos
+	}
+	b.refsMu.Unlock()
+
+	// drains replaced items
+	errs := runShutdown("shutdown", old)
 	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, ";"))
+		b.logger.Warnf("shutting down replaced items: %v", errs)
 	}
+	b.logger.Infof("archive services reloaded")
 	return nil
 }
 
+// buildDefs builds the items that are not in the builder.
+func (b *Builder) buildDefs(backends []BackendDef, services []ServiceDef) error {
+	for _, def := range backends {
+		if def.Disabled {
+			continue
+		}
+		if _, ok := b.backends[def.ID]; ok {
+			continue
+		}
+		_, err := b.BuildBackend(def)
+		if err != nil {
+			return fmt.Errorf("creating '%s': %v", def.ID, err)
+		}
+	}
+	for _, def := range services {
+		if def.Disabled {
+			continue
+		}
+		if _, ok := b.services[def.ID]; ok {
+			continue
+		}
+		_, err := b.BuildService(def)
+		if err != nil {
+			return fmt.Errorf("creating '%s': %v", def.ID, err)
+		}
+	}
+	return nil
+}
+
+// exclusive returns true if the class of the item is exclusive. It must be
+// called holding the lock.
+func (b *Builder) exclusive(r ref) bool {
+	if r.service {
+		def, ok := b.serviceDefs[r.id]
+		return ok && regServiceSpec[def.Class].Exclusive
+	}
+	def, ok := b.backendDefs[r.id]
+	return ok && regBackendSpec[def.Class].Exclusive
+}
+
+// removeItems removes items already shut down.
+func (b *Builder) removeItems(items map[ref]bool) {
+	if len(items) == 0 {
+		return
+	}
+	b.mu.Lock()
+	for r := range items {
+		if r.service {
+			delete(b.services, r.id)
+			delete(b.serviceDefs, r.id)
+		} else {
+			delete(b.backends, r.id)
+			delete(b.backendDefs, r.id)
+		}
+		b.logger.Warnf("'%s' removed: it was shut down and reload failed", r.id)
+	}
+	startup := make([]hook, 0, len(b.startup))
+	for _, h := range b.startup {
+		if !items[h.owner] {
+			startup = append(startup, h)
+		}
+	}
+	b.startup = startup
+	b.mu.Unlock()
+	b.refsMu.Lock()
+	for r := range items {
+		delete(b.refs, r)
+	}
+	b.refsMu.Unlock()
+}
+
 // PingAll backends.
 func (b *Builder) PingAll() error {
 	b.logger.Debugf("PingAll()")
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	errs := make([]string, 0, len(b.backends))
 	for k, v := range b.backends {
 		err := v.Ping()
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("backend '%s': %v", k, err))
+			metrics.BackendUp.WithLabelValues(k, v.Class()).Set(0)
+			continue
 		}
+		metrics.BackendUp.WithLabelValues(k, v.Class()).Set(1)
 	}
 	if len(errs) > 0 {
 		retErr := errors.New(strings.Join(errs, ";"))
@@ -181,6 +507,6 @@ func (b *Builder) PingAll() error {
 }
 
 // Logger returns logger.
-func (b Builder) Logger() yalogi.Logger {
+func (b *Builder) Logger() yalogi.Logger {
 	return b.logger
 }
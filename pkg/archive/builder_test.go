@@ -0,0 +1,237 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testLocks simulates resources that only one instance can hold, like the
+// flock of a boltdb file.
+var testLocks = struct {
+	sync.Mutex
+	held map[string]bool
+}{held: make(map[string]bool)}
+
+type testItem struct {
+	id, class string
+}
+
+func (t testItem) ID() string           { return t.id }
+func (t testItem) Class() string        { return t.class }
+func (t testItem) Session() interface{} { return nil }
+func (t testItem) Ping() error          { return nil }
+func (t testItem) Implements() []API    { return nil }
+
+func init() {
+	RegisterBackendBuilder("test-lock", func(b *Builder, def BackendDef) (Backend, error) {
+		testLocks.Lock()
+		defer testLocks.Unlock()
+		if testLocks.held[def.URL] {
+			return nil, fmt.Errorf("'%s' is locked", def.URL)
+		}
+		testLocks.held[def.URL] = true
+		b.OnShutdown(func() error {
+			testLocks.Lock()
+			delete(testLocks.held, def.URL)
+			testLocks.Unlock()
+			return nil
+		})
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+	RegisterBackendSpec("test-lock", BackendSpec{URL: true, Exclusive: true})
+	RegisterServiceBuilder("test-svc", func(b *Builder, def ServiceDef) (Service, error) {
+		if def.Backend != "" {
+			if _, ok := b.Backend(def.Backend); !ok {
+				return nil, fmt.Errorf("backend '%s' not found", def.Backend)
+			}
+		}
+		for _, id := range toStrings(def.Opts["services"]) {
+			if _, ok := b.Service(id); !ok {
+				return nil, fmt.Errorf("service '%s' not found", id)
+			}
+		}
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+}
+
+func TestSortHooks(t *testing.T) {
+	b := NewBuilder()
+	a, s1, s2 := ref{id: "a"}, ref{service: true, id: "s1"}, ref{service: true, id: "s2"}
+	b.serviceDefs["s1"] = ServiceDef{ID: "s1", Backend: "a"}
+	b.refs[s2] = []ref{s1}
+
+	var got []string
+	mk := func(owner ref, name string) hook {
+		return hook{owner: owner, fn: func() error {
+			got = append(got, name)
+			return nil
+		}}
+	}
+	// registered in reverse order of dependencies
+	hooks := []hook{mk(s2, "s2"), mk(s1, "s1"), mk(ref{}, "global"), mk(a, "a"), mk(s2, "s2-bis")}
+	sorted := b.sortHooks(hooks)
+	for _, h := range sorted {
+		h.fn()
+	}
+	want := []string{"global", "a", "s1", "s2", "s2-bis"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortHooks() = %v, want %v", got, want)
+	}
+
+	got = nil
+	runShutdown("shutdown", sorted)
+	want = []string{"s2-bis", "s2", "s1", "a", "global"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runShutdown() = %v, want %v", got, want)
+	}
+}
+
+func TestSortHooksCycle(t *testing.T) {
+	b := NewBuilder()
+	x, y := ref{service: true, id: "x"}, ref{service: true, id: "y"}
+	b.refs[x] = []ref{y}
+	b.refs[y] = []ref{x}
+	hooks := []hook{{owner: x, fn: func() error { return nil }}, {owner: y, fn: func() error { return nil }}}
+	if got := b.sortHooks(hooks); len(got) != 2 {
+		t.Errorf("sortHooks() returned %v hooks, want 2", len(got))
+	}
+}
+
+func TestRunStartupRollback(t *testing.T) {
+	var got []string
+	mk := func(id string, fail bool) hook {
+		return hook{owner: ref{id: id}, fn: func() error {
+			got = append(got, id)
+			if fail {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		}}
+	}
+	startup := []hook{mk("a", false), mk("b", true), mk("c", false)}
+	shutdown := []hook{mk("a-down", false), mk("b-down", false), mk("c-down", false)}
+	// shutdown hooks are matched by owner
+	shutdown[0].owner, shutdown[1].owner, shutdown[2].owner = ref{id: "a"}, ref{id: "b"}, ref{id: "c"}
+	err := runStartup(startup, shutdown)
+	if err == nil {
+		t.Fatal("runStartup() expected error")
+	}
+	want := []string{"a", "b", "a-down"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runStartup() = %v, want %v", got, want)
+	}
+}
+
+func TestRunStartupRollbackOwnerless(t *testing.T) {
+	var got []string
+	mk := func(owner, name string, fail bool) hook {
+		return hook{owner: ref{id: owner}, fn: func() error {
+			got = append(got, name)
+			if fail {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		}}
+	}
+	tests := []struct {
+		name    string
+		startup []hook
+		want    []string
+	}{
+		{"owned fails", []hook{mk("", "global", false), mk("a", "a", true)},
+			[]string{"global", "a", "global-down"}},
+		{"ownerless fails", []hook{mk("", "global", false), mk("", "global-bis", true), mk("a", "a", false)},
+			[]string{"global", "global-bis", "global-down"}},
+		{"first ownerless fails", []hook{mk("", "global", true), mk("a", "a", false)},
+			[]string{"global"}},
+	}
+	for _, tt := range tests {
+		got = nil
+		shutdown := []hook{mk("", "global-down", false), mk("a", "a-down", false)}
+		if err := runStartup(tt.startup, shutdown); err == nil {
+			t.Fatalf("%s: runStartup() expected error", tt.name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: runStartup() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReloadExclusive(t *testing.T) {
+	backends := []BackendDef{{ID: "db", Class: "test-lock", URL: "file1"}}
+	services := []ServiceDef{
+		{ID: "svc", Class: "test-svc", Backend: "db"},
+		{ID: "comp", Class: "test-svc", Opts: map[string]interface{}{"services": []string{"svc"}}},
+		{ID: "other", Class: "test-svc"},
+	}
+	b := NewBuilder()
+	if err := b.buildDefs(backends, services); err != nil {
+		t.Fatalf("buildDefs(): %v", err)
+	}
+	if err := b.Start(); err != nil {
+		t.Fatalf("Start(): %v", err)
+	}
+	other, _ := b.Service("other")
+
+	// changes an option of the exclusive backend, the resource is the same
+	backends[0].Opts = map[string]interface{}{"changed": true}
+	if err := b.Reload(backends, services); err != nil {
+		t.Fatalf("Reload(): %v", err)
+	}
+	for _, id := range []string{"svc", "comp", "other"} {
+		if _, ok := b.Service(id); !ok {
+			t.Errorf("Reload(): service '%s' not found", id)
+		}
+	}
+	if _, ok := b.Backend("db"); !ok {
+		t.Error("Reload(): backend 'db' not found")
+	}
+	if got, _ := b.Service("other"); got != other {
+		t.Error("Reload(): unchanged service 'other' was rebuilt")
+	}
+
+	// a failed reload removes the exclusive items shut down
+	testLocks.Lock()
+	testLocks.held["file2"] = true
+	testLocks.Unlock()
+	backends[0].URL = "file2"
+	if err := b.Reload(backends, services); err == nil {
+		t.Fatal("Reload(): expected error")
+	}
+	for _, id := range []string{"svc", "comp"} {
+		if _, ok := b.Service(id); ok {
+			t.Errorf("Reload(): service '%s' wasn't removed", id)
+		}
+	}
+	if _, ok := b.Service("other"); !ok {
+		t.Error("Reload(): service 'other' was removed")
+	}
+	testLocks.Lock()
+	delete(testLocks.held, "file2")
+	file1 := testLocks.held["file1"]
+	testLocks.Unlock()
+	if file1 {
+		t.Error("Reload(): 'file1' is still locked")
+	}
+
+	// next reload builds them again
+	if err := b.Reload(backends, services); err != nil {
+		t.Fatalf("Reload(): %v", err)
+	}
+	if _, ok := b.Service("comp"); !ok {
+		t.Error("Reload(): service 'comp' not found")
+	}
+	if err := b.Shutdown(); err != nil {
+		t.Fatalf("Shutdown(): %v", err)
+	}
+	testLocks.Lock()
+	n := len(testLocks.held)
+	testLocks.Unlock()
+	if n != 0 {
+		t.Errorf("Shutdown(): %v resources held", n)
+	}
+}
@@ -3,11 +3,6 @@
 package archive
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"os"
-
 	"github.com/luids-io/core/grpctls"
 )
 
@@ -60,40 +55,24 @@ type ServiceDef struct {
 	Opts map[string]interface{} `json:"opts,omitempty"`
 }
 
-// BackendDefsFromFile creates a slice from a file in json format.
+// BackendDefsFromFile creates a slice from a file in json, yaml or toml
+// format. In toml files definitions are stored in "backends" tables.
 func BackendDefsFromFile(path string) ([]BackendDef, error) {
 	var defs []BackendDef
-	f, err := os.Open(path)
-	defer f.Close()
-	if err != nil {
-		return nil, fmt.Errorf("opening file '%s': %v", path, err)
-	}
-	byteValue, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("reading file '%s': %v", path, err)
-	}
-	err = json.Unmarshal(byteValue, &defs)
+	err := decodeDefsFile(path, "backends", &defs)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling from json file '%s': %v", path, err)
+		return nil, err
 	}
 	return defs, nil
 }
 
-// ServiceDefsFromFile creates a slice from a file in json format.
+// ServiceDefsFromFile creates a slice from a file in json, yaml or toml
+// format. In toml files definitions are stored in "services" tables.
 func ServiceDefsFromFile(path string) ([]ServiceDef, error) {
 	var defs []ServiceDef
-	f, err := os.Open(path)
-	defer f.Close()
-	if err != nil {
-		return nil, fmt.Errorf("opening file '%s': %v", path, err)
-	}
-	byteValue, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("reading file '%s': %v", path, err)
-	}
-	err = json.Unmarshal(byteValue, &defs)
+	err := decodeDefsFile(path, "services", &defs)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling from json file '%s': %v", path, err)
+		return nil, err
 	}
 	return defs, nil
 }
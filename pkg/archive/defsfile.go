@@ -0,0 +1,150 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// DefsExtensions stores the extensions of the supported definition files.
+var DefsExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// IsDefsFile returns true if the extension of the file is supported.
+func IsDefsFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range DefsExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeDefsFile decodes a definition file in json, yaml or toml format
+// and stores the result in the value pointed by v. Definitions are a list
+// in json and yaml, and a list of tables with the name of the key in toml.
+// Environment variables in strings are expanded.
+func decodeDefsFile(path, key string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file '%s': %v", path, err)
+	}
+	defer f.Close()
+	byteValue, err := ioutil.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("reading file '%s': %v", path, err)
+	}
+	var data interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(byteValue, &data)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(byteValue, &data)
+	case ".toml":
+		var tree *toml.Tree
+		tree, err = toml.LoadBytes(byteValue)
+		if err == nil {
+			data = tree.ToMap()[key]
+		}
+	default:
+		return fmt.Errorf("file '%s' with unsupported extension", path)
+	}
+	if err != nil {
+		return fmt.Errorf("unmarshalling file '%s': %v", path, err)
+	}
+	data, err = normalize(data)
+	if err != nil {
+		return fmt.Errorf("file '%s': %v", path, err)
+	}
+	// decodes using json tags of the definitions
+	byteValue, err = json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("file '%s': %v", path, err)
+	}
+	err = json.Unmarshal(byteValue, v)
+	if err != nil {
+		return fmt.Errorf("unmarshalling from file '%s': %v", path, err)
+	}
+	return nil
+}
+
+// normalize converts maps to json objects and expands environment
+// variables in strings.
+func normalize(data interface{}) (interface{}, error) {
+	switch v := data.(type) {
+	case string:
+		return expandEnv(v)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			n, err := normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(key)] = n
+		}
+		return m, nil
+	case map[string]interface{}:
+		for key, value := range v {
+			n, err := normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = n
+		}
+		return v, nil
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			n, err := normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, n)
+		}
+		return list, nil
+	case []interface{}:
+		for i, value := range v {
+			n, err := normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		return v, nil
+	}
+	return data, nil
+}
+
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} and ${VAR:-default} with the value of the
+// environment variable. It returns an error if a variable without default
+// value is not defined.
+func expandEnv(s string) (string, error) {
+	var err error
+	ret := envRegexp.ReplaceAllStringFunc(s, func(match string) string {
+		groups := envRegexp.FindStringSubmatch(match)
+		value, ok := os.LookupEnv(groups[1])
+		if ok {
+			return value
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable '%s' not defined", groups[1])
+		}
+		return match
+	})
+	return ret, err
+}
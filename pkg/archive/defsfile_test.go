@@ -0,0 +1,123 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// unsetenv unsets the variable until the end of the test.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestBackendDefsFromFile(t *testing.T) {
+	t.Setenv("TEST_DEFS_HOST", "db.example.com")
+	t.Setenv("TEST_DEFS_PASSWORD", "secret")
+	t.Setenv("TEST_DEFS_TAG", "c")
+	unsetenv(t, "TEST_DEFS_USER")
+	// all formats decode the same definitions, numbers are decoded as json
+	want := []BackendDef{
+		{
+			ID:    "mongo1",
+			Class: "mongodb",
+			URL:   "mongodb://db.example.com:27017",
+			Opts: map[string]interface{}{
+				"user":     "luids",
+				"password": "secret",
+				"pool": map[string]interface{}{
+					"size": float64(10),
+					"tags": []interface{}{"a", "c"},
+				},
+			},
+		},
+		{ID: "bolt1", Class: "boltdb", Disabled: true, URL: "/var/lib/luids/archive.db"},
+	}
+	for _, name := range []string{"backends.json", "backends.yaml", "backends.toml"} {
+		got, err := BackendDefsFromFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestBackendDefsFromFileUndefined(t *testing.T) {
+	t.Setenv("TEST_DEFS_HOST", "db.example.com")
+	unsetenv(t, "TEST_DEFS_PASSWORD")
+	for _, name := range []string{"backends.json", "backends.yaml", "backends.toml"} {
+		_, err := BackendDefsFromFile(filepath.Join("testdata", name))
+		if err == nil || !strings.Contains(err.Error(), "'TEST_DEFS_PASSWORD' not defined") {
+			t.Errorf("%s: err = %v, want undefined variable", name, err)
+		}
+	}
+}
+
+func TestServiceDefsFromFileTOML(t *testing.T) {
+	// only the tables of the key are decoded
+	got, err := ServiceDefsFromFile(filepath.Join("testdata", "services.toml"))
+	if err != nil {
+		t.Fatalf("ServiceDefsFromFile(): %v", err)
+	}
+	want := []ServiceDef{
+		{ID: "dns", Class: "dnsmdb", Backend: "mongo1"},
+		{ID: "event", Class: "eventmdb", Backend: "mongo1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDefsFileUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.xml")
+	if err := os.WriteFile(path, []byte("<backends/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BackendDefsFromFile(path); err == nil {
+		t.Error("BackendDefsFromFile() expected error")
+	}
+	if IsDefsFile(path) || !IsDefsFile("backends.YML") {
+		t.Error("IsDefsFile() unexpected result")
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TEST_DEFS_VAR", "value")
+	t.Setenv("TEST_DEFS_EMPTY", "")
+	unsetenv(t, "TEST_DEFS_UNDEF")
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"plain", "plain", false},
+		{"${TEST_DEFS_VAR}", "value", false},
+		{"a-${TEST_DEFS_VAR}-${TEST_DEFS_VAR}", "a-value-value", false},
+		{"${TEST_DEFS_VAR:-default}", "value", false},
+		{"${TEST_DEFS_UNDEF:-default}", "default", false},
+		{"${TEST_DEFS_UNDEF:-}", "", false},
+		{"${TEST_DEFS_EMPTY:-default}", "", false},
+		{"$TEST_DEFS_VAR", "$TEST_DEFS_VAR", false},
+		{"${TEST_DEFS_UNDEF}", "", true},
+	}
+	for _, tt := range tests {
+		got, err := expandEnv(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandEnv(%q) expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComponentError stores an error returned by a backend or a service during
+// an operation of the lifecycle.
+type ComponentError struct {
+	// Op is the operation: start, shutdown or rollback
+	Op string
+	// Kind of component: backend or service
+	Kind string
+	// ID of the component
+	ID  string
+	Err error
+}
+
+func (e ComponentError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s '%s': %v", e.Op, e.Kind, e.ID, e.Err)
+}
+
+// Unwrap returns the error of the component.
+func (e ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError groups the errors returned by several components.
+type MultiError []ComponentError
+
+func (m MultiError) Error() string {
+	errs := make([]string, 0, len(m))
+	for _, e := range m {
+		errs = append(errs, e.Error())
+	}
+	return strings.Join(errs, "; ")
+}
+
+// errorOrNil avoids returning a non nil interface with an empty slice.
+func (m MultiError) errorOrNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func newComponentError(op string, r ref, err error) ComponentError {
+	kind := "backend"
+	if r.service {
+		kind = "service"
+	}
+	return ComponentError{Op: op, Kind: kind, ID: r.id, Err: err}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import "sort"
+
+// deps returns the items used by an item: the backend of the service
+// definition and the items requested to the builder during its construction
+// (for example, the services of a composite service).
+// It must be called with the read lock.
+func (b *Builder) deps(r ref) []ref {
+	deps := make([]ref, 0)
+	if r.service {
+		if def, ok := b.serviceDefs[r.id]; ok && def.Backend != "" {
+			deps = append(deps, ref{id: def.Backend})
+		}
+	}
+	b.refsMu.Lock()
+	deps = append(deps, b.refs[r]...)
+	b.refsMu.Unlock()
+	return deps
+}
+
+// sortHooks returns a copy of the hooks sorted in topological order: hooks
+// of an item come after the hooks of the items it depends on. The
+// registration order is kept between independent items.
+// It must be called with the read lock.
+func (b *Builder) sortHooks(hooks []hook) []hook {
+	rank := make(map[ref]int)
+	visiting := make(map[ref]bool)
+	var visit func(r ref)
+	visit = func(r ref) {
+		if _, ok := rank[r]; ok || visiting[r] {
+			return
+		}
+		visiting[r] = true
+		for _, dep := range b.deps(r) {
+			visit(dep)
+		}
+		visiting[r] = false
+		rank[r] = len(rank)
+	}
+	for _, h := range hooks {
+		if h.owner.id != "" {
+			visit(h.owner)
+		}
+	}
+	sorted := make([]hook, len(hooks))
+	copy(sorted, hooks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return hookRank(rank, sorted[i]) < hookRank(rank, sorted[j])
+	})
+	return sorted
+}
+
+// hooks registered out of a construction go first.
+func hookRank(rank map[ref]int, h hook) int {
+	if h.owner.id == "" {
+		return -1
+	}
+	return rank[h.owner]
+}
+
+// runStartup executes the startup hooks in order. If one fails, the
+// shutdown hooks of the items already running are executed.
+func runStartup(startup, shutdown []hook) error {
+	for i, h := range startup {
+		err := h.fn()
+		if err != nil {
+			errs := MultiError{newComponentError("start", h.owner, err)}
+			// items whose first startup hook is pending are not rolled
+			// back, the index is used because ownerless hooks share the
+			// empty owner
+			first := make(map[ref]int)
+			for j, p := range startup {
+				if _, ok := first[p.owner]; !ok {
+					first[p.owner] = j
+				}
+			}
+			running := make([]hook, 0, len(shutdown))
+			for _, sh := range shutdown {
+				if j, ok := first[sh.owner]; ok && j >= i {
+					continue
+				}
+				running = append(running, sh)
+			}
+			for _, rerr := range runShutdown("rollback", running) {
+				errs = append(errs, rerr)
+			}
+			return errs
+		}
+	}
+	return nil
+}
+
+// runShutdown executes the shutdown hooks in reverse order.
+func runShutdown(op string, hooks []hook) MultiError {
+	var errs MultiError
+	for i := len(hooks) - 1; i >= 0; i-- {
+		err := hooks[i].fn()
+		if err != nil {
+			errs = append(errs, newComponentError(op, hooks[i].owner, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package metrics defines the prometheus metrics of the archive services.
+// Metrics are registered in the default registry, so they are exported in
+// the metrics endpoint of the health server.
+//
+// This package is a work in progress and makes no API stability promises.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "luids"
+	subsystem = "archive"
+)
+
+// Archive metrics.
+var (
+	// Documents stored by services. Collection is the logical name, so
+	// partitions are counted in the same collection.
+	Documents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "documents_total",
+		Help:      "Documents stored by archive services.",
+	}, []string{"service", "collection"})
+
+	// PurgedDocuments removed by retention.
+	PurgedDocuments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "purged_documents_total",
+		Help:      "Documents removed because retention expired.",
+	}, []string{"service", "collection"})
+
+	// BulkFlushDuration of bulks.
+	BulkFlushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "bulk_flush_duration_seconds",
+		Help:      "Duration of bulk flushes.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"collection"})
+
+	// BulkFlushSize of bulks.
+	BulkFlushSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "bulk_flush_size_documents",
+		Help:      "Documents inserted in bulk flushes.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"collection"})
+
+	// BulkFlushErrors of bulks.
+	BulkFlushErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "bulk_flush_errors_total",
+		Help:      "Failed bulk flushes.",
+	}, []string{"collection"})
+
+	// SpoolQuarantined segments that couldn't be replayed.
+	SpoolQuarantined = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "spool_quarantined_total",
+		Help:      "Spool segments moved to quarantine because they couldn't be inserted.",
+	})
+
+	// QueueDepth of insert pipelines.
+	QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "queue_depth",
+		Help:      "Documents waiting in insert queues.",
+	}, []string{"collection"})
+
+	// QueueDropped documents of insert pipelines.
+	QueueDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "queue_dropped_total",
+		Help:      "Documents dropped because insert queue was full or spool was full on close.",
+	}, []string{"collection"})
+
+	// PassiveDropped records because passive dns cache was full.
+	PassiveDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "passive_dropped_total",
+		Help:      "Passive dns records dropped because the cache was full.",
+	}, []string{"service"})
+
+	// CacheRequests of service caches, result is "hit" or "miss".
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "cache_requests_total",
+		Help:      "Requests to service caches.",
+	}, []string{"service", "cache", "result"})
+
+	// BackendUp is the result of the last ping to backends.
+	BackendUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "backend_up",
+		Help:      "Result of the last ping to the backend (1 = up).",
+	}, []string{"backend", "class"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		Documents,
+		PurgedDocuments,
+		BulkFlushDuration,
+		BulkFlushSize,
+		BulkFlushErrors,
+		SpoolQuarantined,
+		QueueDepth,
+		QueueDropped,
+		PassiveDropped,
+		CacheRequests,
+		BackendUp,
+	)
+}
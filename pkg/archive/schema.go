@@ -0,0 +1,123 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import "sort"
+
+// BackendsSchema returns a JSON Schema of the backend definition files
+// generated from the registered classes.
+func BackendsSchema() map[string]interface{} {
+	classes := make([]string, 0, len(regBackendBuilder))
+	for class := range regBackendBuilder {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	items := make([]interface{}, 0, len(classes))
+	for _, class := range classes {
+		props := map[string]interface{}{
+			"id":       map[string]interface{}{"type": "string"},
+			"class":    map[string]interface{}{"const": class},
+			"disabled": map[string]interface{}{"type": "boolean"},
+			"name":     map[string]interface{}{"type": "string"},
+			"url":      map[string]interface{}{"type": "string"},
+		}
+		required := []string{"id", "class"}
+		spec, ok := regBackendSpec[class]
+		if ok {
+			if spec.URL {
+				required = append(required, "url")
+			}
+			if spec.TLS {
+				props["tls"] = map[string]interface{}{"type": "object"}
+			}
+			props["opts"] = optsSchema(spec.Options)
+		} else {
+			props["tls"] = map[string]interface{}{"type": "object"}
+			props["opts"] = map[string]interface{}{"type": "object"}
+		}
+		items = append(items, objectSchema(props, required, ok))
+	}
+	return arraySchema("luarchive backend definitions", items)
+}
+
+// ServicesSchema returns a JSON Schema of the service definition files
+// generated from the registered classes.
+func ServicesSchema() map[string]interface{} {
+	classes := make([]string, 0, len(regServiceBuilder))
+	for class := range regServiceBuilder {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	items := make([]interface{}, 0, len(classes))
+	for _, class := range classes {
+		props := map[string]interface{}{
+			"id":       map[string]interface{}{"type": "string"},
+			"class":    map[string]interface{}{"const": class},
+			"disabled": map[string]interface{}{"type": "boolean"},
+			"name":     map[string]interface{}{"type": "string"},
+		}
+		required := []string{"id", "class"}
+		spec, ok := regServiceSpec[class]
+		if ok {
+			if len(spec.Backends) > 0 {
+				props["backend"] = map[string]interface{}{"type": "string"}
+				required = append(required, "backend")
+			}
+			props["opts"] = optsSchema(spec.Options)
+		} else {
+			props["backend"] = map[string]interface{}{"type": "string"}
+			props["opts"] = map[string]interface{}{"type": "object"}
+		}
+		items = append(items, objectSchema(props, required, ok))
+	}
+	return arraySchema("luarchive service definitions", items)
+}
+
+func arraySchema(title string, items []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   title,
+		"type":    "array",
+		"items":   map[string]interface{}{"oneOf": items},
+	}
+}
+
+func objectSchema(props map[string]interface{}, required []string, strict bool) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": !strict,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func optsSchema(specs []OptionSpec) map[string]interface{} {
+	props := make(map[string]interface{}, len(specs))
+	required := make([]string, 0)
+	for _, s := range specs {
+		var p map[string]interface{}
+		switch s.Type {
+		case OptStrings, OptServices:
+			p = map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			}
+		default:
+			p = map[string]interface{}{"type": string(s.Type)}
+		}
+		if len(s.Enum) > 0 {
+			p["enum"] = s.Enum
+		}
+		if s.Description != "" {
+			p["description"] = s.Description
+		}
+		props[s.Name] = p
+		if s.Required {
+			required = append(required, s.Name)
+		}
+	}
+	return objectSchema(props, required, true)
+}
@@ -0,0 +1,312 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package dnsbolt implements dnsutil.Archive and dnsutil.Finder using
+// boltdb backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsbolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/dnsfilter"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "dnsbolt"
+
+// Bucket names.
+const (
+	ResolvBucketName = "resolvs"
+)
+
+// Default values.
+const (
+	DefaultMaxSize = 100
+)
+
+// Archiver implements dns archive backend using an embedded bolt database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	db *bolt.DB
+	//control
+	mu      sync.Mutex
+	started bool
+}
+
+// New creates a new storage.
+func New(id string, db *bolt.DB, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		db:     db,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger yalogi.Logger
+	prefix string
+}
+
+var defaultOptions = options{
+	logger: yalogi.LogNull,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to bucket names.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting boltdb dns archiver", a.id)
+	err := a.createBuckets()
+	if err != nil {
+		return fmt.Errorf("creating buckets: %v", err)
+	}
+	a.started = true
+	return nil
+}
+
+// SaveResolv implements dnsutil.Archiver interface.
+func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	if !a.started {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	// create new uuid if not set
+	if rd.ID == uuid.Nil {
+		newid, err := uuid.NewRandom()
+		if err != nil {
+			a.logger.Warnf("%s: saveresolv(): generating new id: %v", a.id, err)
+			return uuid.Nil, dnsutil.ErrInternal
+		}
+		rd.ID = newid
+	}
+	// compute fields
+	rd.TLD, _ = publicsuffix.PublicSuffix(rd.Name)
+	rd.TLDPlusOne, _ = publicsuffix.EffectiveTLDPlusOne(rd.Name)
+	// encode data
+	data, err := json.Marshal(rd)
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): encoding: %v", a.id, rd.ID, err)
+		return uuid.Nil, dnsutil.ErrBadRequest
+	}
+	// store data, batch groups concurrent writes in one transaction
+	err = a.db.Batch(func(tx *bolt.Tx) error {
+		root := tx.Bucket(a.getBucketName(ResolvBucketName))
+		if root == nil {
+			return errors.New("bucket not found")
+		}
+		if root.Bucket(idxID).Get(rd.ID[:]) != nil {
+			return errDuplicatedID
+		}
+		seq, err := root.NextSequence()
+		if err != nil {
+			return err
+		}
+		err = root.Bucket(dataBucket).Put(itob(seq), data)
+		if err != nil {
+			return err
+		}
+		return putIndexes(root, seq, &rd)
+	})
+	if err == errDuplicatedID {
+		a.logger.Warnf("%s: saveresolv(%s): %v", a.id, rd.ID, err)
+		return uuid.Nil, dnsutil.ErrBadRequest
+	}
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): storing: %v", a.id, rd.ID, err)
+		return uuid.Nil, dnsutil.ErrInternal
+	}
+	return rd.ID, nil
+}
+
+// GetResolv implements dnsutil.Finder interface.
+func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
+	if !a.started {
+		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == uuid.Nil {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	//do find
+	var r dnsutil.ResolvData
+	var found bool
+	err := a.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(a.getBucketName(ResolvBucketName))
+		if root == nil {
+			return errors.New("bucket not found")
+		}
+		seq := root.Bucket(idxID).Get(id[:])
+		if seq == nil {
+			return nil
+		}
+		data := root.Bucket(dataBucket).Get(seq)
+		if data == nil {
+			return fmt.Errorf("data for index '%v' not found", btoi(seq))
+		}
+		found = true
+		return json.Unmarshal(data, &r)
+	})
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): %v", a.id, id, err)
+		return dnsutil.ResolvData{}, false, dnsutil.ErrInternal
+	}
+	return r, found, nil
+}
+
+// ListResolvs implements dnsutil.Finder interface. Results are sorted by
+// insertion order and next is the sequence of the last item returned.
+func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if !a.started {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	var last uint64
+	if next != "" {
+		var err error
+		last, err = strconv.ParseUint(next, 10, 64)
+		if err != nil {
+			a.logger.Warnf("%s: listresolvs(): invalid next '%s'", a.id, next)
+			return nil, "", dnsutil.ErrBadRequest
+		}
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	result := make([]dnsutil.ResolvData, 0)
+	err := a.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(a.getBucketName(ResolvBucketName))
+		if root == nil {
+			return errors.New("bucket not found")
+		}
+		data := root.Bucket(dataBucket)
+		// iterate over candidates
+		it := newIterator(root, filters, rev, last)
+		for seq, ok := it.next(); ok; seq, ok = it.next() {
+			if ctx.Err() != nil {
+				return dnsutil.ErrCanceledRequest
+			}
+			v := data.Get(itob(seq))
+			if v == nil {
+				continue
+			}
+			var r dnsutil.ResolvData
+			err := json.Unmarshal(v, &r)
+			if err != nil {
+				return fmt.Errorf("decoding '%v': %v", seq, err)
+			}
+			if !dnsfilter.Match(r, filters) {
+				continue
+			}
+			result = append(result, r)
+			if max > 0 && len(result) == max {
+				last = seq
+				return nil
+			}
+		}
+		last = 0
+		return nil
+	})
+	if err == dnsutil.ErrCanceledRequest {
+		return nil, "", err
+	}
+	if err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", dnsutil.ErrInternal
+	}
+	if max > 0 && len(result) == max && last > 0 {
+		return result, strconv.FormatUint(last, 10), nil
+	}
+	return result, "", nil
+}
+
+// Shutdown the archiver, database is closed by the backend.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down dns archiver", a.id)
+		a.started = false
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(a.getBucketName(ResolvBucketName)) == nil {
+			return errors.New("bucket not found")
+		}
+		return nil
+	})
+}
+
+func (a *Archiver) getBucketName(name string) []byte {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return []byte(name)
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.DNSAPI}
+}
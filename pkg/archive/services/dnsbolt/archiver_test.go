@@ -0,0 +1,119 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsbolt
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+func TestListResolvsIndexes(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer db.Close()
+	a := New("test", db)
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start(): %v", err)
+	}
+	defer a.Shutdown()
+
+	start := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	var saved []dnsutil.ResolvData
+	for i := 0; i < 30; i++ {
+		rd := dnsutil.ResolvData{
+			Timestamp:   start.Add(time.Duration(i) * time.Minute),
+			Server:      net.ParseIP("10.0.0.1"),
+			Client:      net.ParseIP(fmt.Sprintf("10.0.1.%v", i%3)),
+			QID:         uint16(i),
+			Name:        fmt.Sprintf("www%v.example%v.com", i%5, i%2),
+			ResolvedIPs: []net.IP{net.ParseIP(fmt.Sprintf("192.0.2.%v", i%4))},
+		}
+		id, err := a.SaveResolv(context.Background(), rd)
+		if err != nil {
+			t.Fatalf("SaveResolv(): %v", err)
+		}
+		rd.ID = id
+		saved = append(saved, rd)
+	}
+	_, err = a.SaveResolv(context.Background(), saved[0])
+	if err != dnsutil.ErrBadRequest {
+		t.Errorf("SaveResolv() duplicated id err = %v, want %v", err, dnsutil.ErrBadRequest)
+	}
+
+	tests := []struct {
+		name    string
+		filters []dnsutil.ResolvsFilter
+		match   func(i int) bool
+	}{
+		{"all", nil, func(i int) bool { return true }},
+		{"name", []dnsutil.ResolvsFilter{{Name: "www1.example1.com"}},
+			func(i int) bool { return i%5 == 1 && i%2 == 1 }},
+		{"client", []dnsutil.ResolvsFilter{{Client: net.ParseIP("10.0.1.2")}},
+			func(i int) bool { return i%3 == 2 }},
+		{"resolved ip", []dnsutil.ResolvsFilter{{ResolvedIP: net.ParseIP("192.0.2.3")}},
+			func(i int) bool { return i%4 == 3 }},
+		{"tld plus one", []dnsutil.ResolvsFilter{{TLDPlusOne: "example0.com"}},
+			func(i int) bool { return i%2 == 0 }},
+		// time limits are exclusive
+		{"time range", []dnsutil.ResolvsFilter{{Since: start.Add(5 * time.Minute), To: start.Add(20 * time.Minute)}},
+			func(i int) bool { return i > 5 && i < 20 }},
+		{"index and filter", []dnsutil.ResolvsFilter{{Client: net.ParseIP("10.0.1.0"), TLDPlusOne: "example1.com"}},
+			func(i int) bool { return i%3 == 0 && i%2 == 1 }},
+		{"or", []dnsutil.ResolvsFilter{{Client: net.ParseIP("10.0.1.0")}, {ResolvedIP: net.ParseIP("192.0.2.0")}},
+			func(i int) bool { return i%3 == 0 || i%4 == 0 }},
+		{"no index", []dnsutil.ResolvsFilter{{QID: 7}},
+			func(i int) bool { return i == 7 }},
+		{"or without index", []dnsutil.ResolvsFilter{{Name: "www0.example0.com"}, {QID: 7}},
+			func(i int) bool { return i%10 == 0 || i == 7 }},
+	}
+	for _, tt := range tests {
+		for _, rev := range []bool{false, true} {
+			var want []uuid.UUID
+			for i := range saved {
+				j := i
+				if rev {
+					j = len(saved) - 1 - i
+				}
+				if tt.match(j) {
+					want = append(want, saved[j].ID)
+				}
+			}
+			var got []uuid.UUID
+			next := ""
+			for pages := 0; pages < 20; pages++ {
+				list, n, err := a.ListResolvs(context.Background(), tt.filters, rev, 4, next)
+				if err != nil {
+					t.Fatalf("%s: ListResolvs(): %v", tt.name, err)
+				}
+				for _, r := range list {
+					got = append(got, r.ID)
+				}
+				if n == "" {
+					break
+				}
+				next = n
+			}
+			if len(got) != len(want) {
+				t.Errorf("%s: ListResolvs(rev=%v) returned %v resolvs, want %v", tt.name, rev, len(got), len(want))
+				continue
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("%s: ListResolvs(rev=%v)[%v] = %v, want %v", tt.name, rev, i, got[i], want[i])
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package dnsbolt
+
+import (
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/backends/boltdb"
+	"github.com/luids-io/core/option"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildServiceFn {
+	return func(b *archive.Builder, def archive.ServiceDef) (archive.Service, error) {
+		if def.Backend == "" {
+			return nil, errors.New("'backend' is required")
+		}
+		//get boltdb backend
+		back, ok := b.Backend(def.Backend)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		if back.Class() != boltdb.BackendClass {
+			return nil, fmt.Errorf("'backend' class '%s' not suported in service", back.Class())
+		}
+		// get database from backend container
+		db, ok := back.Session().(*bolt.DB)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		// parse options
+		bopt := make([]Option, 0)
+		bopt = append(bopt, SetLogger(b.Logger()))
+		if def.Opts != nil {
+			prefixOpt, ok, err := option.String(def.Opts, "prefix")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				bopt = append(bopt, SetPrefix(prefixOpt))
+			}
+		}
+		//create archive service
+		archiver := New(def.ID, db, bopt...)
+		b.OnStartup(func() error {
+			return archiver.Start()
+		})
+		b.OnShutdown(func() error {
+			archiver.Shutdown()
+			return nil
+		})
+		return archiver, nil
+	}
+}
+
+func init() {
+	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Backends:   []string{boltdb.BackendClass},
+		Implements: []archive.API{archive.DNSAPI},
+		Options: []archive.OptionSpec{
+			{Name: "prefix", Type: archive.OptString, Description: "prefix for bucket names"},
+		},
+	})
+}
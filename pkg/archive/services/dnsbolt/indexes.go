@@ -0,0 +1,272 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsbolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+// Nested buckets in resolvs bucket. Data is stored by sequence and indexes
+// keys are composed by value and sequence, equivalent to dnsmdb indexes.
+var (
+	dataBucket       = []byte("data")
+	idxID            = []byte("id")
+	idxTimestamp     = []byte("timestamp")
+	idxServerIP      = []byte("serverIP")
+	idxClientIP      = []byte("clientIP")
+	idxName          = []byte("name")
+	idxResolvedIPs   = []byte("resolvedIPs")
+	idxResolvedCNAME = []byte("resolvedCNAMEs")
+	idxTLDPlusOne    = []byte("tldPlusOne")
+)
+
+var errDuplicatedID = errors.New("duplicated id")
+
+func (a *Archiver) createBuckets() error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(a.getBucketName(ResolvBucketName))
+		if err != nil {
+			return err
+		}
+		buckets := [][]byte{dataBucket, idxID, idxTimestamp, idxServerIP, idxClientIP,
+			idxName, idxResolvedIPs, idxResolvedCNAME, idxTLDPlusOne}
+		for _, name := range buckets {
+			_, err := root.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type idxEntry struct {
+	bucket []byte
+	value  []byte
+}
+
+func putIndexes(root *bolt.Bucket, seq uint64, rd *dnsutil.ResolvData) error {
+	err := root.Bucket(idxID).Put(rd.ID[:], itob(seq))
+	if err != nil {
+		return err
+	}
+	keys := []idxEntry{
+		{idxTimestamp, tsKey(rd.Timestamp)},
+		{idxServerIP, ipKey(rd.Server)},
+		{idxClientIP, ipKey(rd.Client)},
+		{idxName, []byte(rd.Name)},
+		{idxTLDPlusOne, []byte(rd.TLDPlusOne)},
+	}
+	for _, ip := range rd.ResolvedIPs {
+		keys = append(keys, idxEntry{idxResolvedIPs, ipKey(ip)})
+	}
+	for _, cname := range rd.ResolvedCNAMEs {
+		keys = append(keys, idxEntry{idxResolvedCNAME, []byte(cname)})
+	}
+	for _, k := range keys {
+		if len(k.value) == 0 {
+			continue
+		}
+		err := root.Bucket(k.bucket).Put(idxKey(k.value, seq), nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// candidates returns the sequences of the index that matches better the
+// filter. If no index can be used, it returns false.
+func candidates(root *bolt.Bucket, f dnsutil.ResolvsFilter) ([]uint64, bool) {
+	switch {
+	case f.Name != "":
+		return prefixScan(root.Bucket(idxName), []byte(f.Name)), true
+	case f.Client != nil:
+		return prefixScan(root.Bucket(idxClientIP), ipKey(f.Client)), true
+	case f.ResolvedIP != nil:
+		return prefixScan(root.Bucket(idxResolvedIPs), ipKey(f.ResolvedIP)), true
+	case f.ResolvedCNAME != "":
+		return prefixScan(root.Bucket(idxResolvedCNAME), []byte(f.ResolvedCNAME)), true
+	case f.Server != nil:
+		return prefixScan(root.Bucket(idxServerIP), ipKey(f.Server)), true
+	case f.TLDPlusOne != "":
+		return prefixScan(root.Bucket(idxTLDPlusOne), []byte(f.TLDPlusOne)), true
+	case !f.Since.IsZero() || !f.To.IsZero():
+		return rangeScan(root.Bucket(idxTimestamp), f.Since, f.To), true
+	}
+	return nil, false
+}
+
+func prefixScan(b *bolt.Bucket, value []byte) []uint64 {
+	prefix := append(append([]byte{}, value...), 0)
+	seqs := make([]uint64, 0)
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		seqs = append(seqs, btoi(k[len(k)-8:]))
+	}
+	return seqs
+}
+
+func rangeScan(b *bolt.Bucket, since, to time.Time) []uint64 {
+	seqs := make([]uint64, 0)
+	c := b.Cursor()
+	k, _ := c.First()
+	if !since.IsZero() {
+		k, _ = c.Seek(tsKey(since))
+	}
+	var max []byte
+	if !to.IsZero() {
+		max = tsKey(to)
+	}
+	for ; k != nil; k, _ = c.Next() {
+		if max != nil && bytes.Compare(k[:8], max) > 0 {
+			break
+		}
+		seqs = append(seqs, btoi(k[len(k)-8:]))
+	}
+	return seqs
+}
+
+// iterator returns sequences of candidate resolvs.
+type iterator interface {
+	next() (uint64, bool)
+}
+
+func newIterator(root *bolt.Bucket, filters []dnsutil.ResolvsFilter, rev bool, last uint64) iterator {
+	if len(filters) == 0 {
+		return &scanIterator{c: root.Bucket(dataBucket).Cursor(), rev: rev, last: last}
+	}
+	seen := make(map[uint64]struct{})
+	seqs := make([]uint64, 0)
+	for _, f := range filters {
+		fseqs, ok := candidates(root, f)
+		if !ok {
+			return &scanIterator{c: root.Bucket(dataBucket).Cursor(), rev: rev, last: last}
+		}
+		for _, seq := range fseqs {
+			if _, ok := seen[seq]; !ok {
+				seen[seq] = struct{}{}
+				seqs = append(seqs, seq)
+			}
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	// skip sequences before the cursor
+	it := &listIterator{seqs: seqs, rev: rev}
+	if rev {
+		it.pos = len(seqs) - 1
+		if last > 0 {
+			it.pos = sort.Search(len(seqs), func(i int) bool { return seqs[i] >= last }) - 1
+		}
+	} else if last > 0 {
+		it.pos = sort.Search(len(seqs), func(i int) bool { return seqs[i] > last })
+	}
+	return it
+}
+
+type listIterator struct {
+	seqs []uint64
+	rev  bool
+	pos  int
+}
+
+func (it *listIterator) next() (uint64, bool) {
+	if it.pos < 0 || it.pos >= len(it.seqs) {
+		return 0, false
+	}
+	seq := it.seqs[it.pos]
+	if it.rev {
+		it.pos--
+	} else {
+		it.pos++
+	}
+	return seq, true
+}
+
+type scanIterator struct {
+	c       *bolt.Cursor
+	rev     bool
+	last    uint64
+	started bool
+}
+
+func (it *scanIterator) next() (uint64, bool) {
+	var k []byte
+	if !it.started {
+		it.started = true
+		k = it.first()
+	} else if it.rev {
+		k, _ = it.c.Prev()
+	} else {
+		k, _ = it.c.Next()
+	}
+	if k == nil {
+		return 0, false
+	}
+	return btoi(k), true
+}
+
+func (it *scanIterator) first() []byte {
+	if it.last == 0 {
+		if it.rev {
+			k, _ := it.c.Last()
+			return k
+		}
+		k, _ := it.c.First()
+		return k
+	}
+	k, _ := it.c.Seek(itob(it.last))
+	if it.rev {
+		if k == nil {
+			k, _ = it.c.Last()
+			return k
+		}
+		k, _ = it.c.Prev()
+		return k
+	}
+	if k != nil && btoi(k) == it.last {
+		k, _ = it.c.Next()
+	}
+	return k
+}
+
+func idxKey(value []byte, seq uint64) []byte {
+	key := make([]byte, 0, len(value)+9)
+	key = append(key, value...)
+	key = append(key, 0)
+	return append(key, itob(seq)...)
+}
+
+func ipKey(ip net.IP) []byte {
+	if ip == nil {
+		return nil
+	}
+	return ip.To16()
+}
+
+func tsKey(t time.Time) []byte {
+	ns := t.UnixNano()
+	if ns < 0 {
+		ns = 0
+	}
+	return itob(uint64(ns))
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
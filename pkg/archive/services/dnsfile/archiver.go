@@ -0,0 +1,458 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package dnsfile implements dnsutil.Archive and dnsutil.Finder using
+// file backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/dnsfilter"
+	"github.com/luids-io/archive/pkg/fileutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "dnsfile"
+
+// File names.
+const (
+	ResolvFileName = "resolvs"
+)
+
+// Default values.
+const (
+	DefaultRotatePeriod = time.Hour
+	DefaultMaxFileSize  = 0
+	DefaultSyncSeconds  = 5
+	DefaultMaxSize      = 100
+)
+
+// Archiver implements dns archive backend using json lines files.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//files
+	dir string
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	//writers
+	wResolvs *fileutil.Writer
+}
+
+// New creates a new storage.
+func New(id string, dir string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		dir:    dir,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger   yalogi.Logger
+	rotate   time.Duration
+	maxSize  int64
+	compress bool
+	syncSecs int
+	prefix   string
+}
+
+var defaultOptions = options{
+	logger:   yalogi.LogNull,
+	rotate:   DefaultRotatePeriod,
+	maxSize:  DefaultMaxFileSize,
+	compress: true,
+	syncSecs: DefaultSyncSeconds,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to file names.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetRotate option sets the period of file rotation.
+func SetRotate(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.rotate = d
+		}
+	}
+}
+
+// SetMaxFileSize option sets the max size in bytes of files.
+func SetMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// Compress option allows compress rotated files.
+func Compress(b bool) Option {
+	return func(o *options) {
+		o.compress = b
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting file dns archiver", a.id)
+	//init writers
+	a.wResolvs = fileutil.NewWriter(a.dir, a.getFileName(ResolvFileName),
+		a.opts.rotate, a.opts.maxSize, a.opts.compress)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	a.started = true
+	return nil
+}
+
+// SaveResolv implements dnsutil.Archiver interface.
+func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	if !a.started {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	// create new uuid if not set
+	if rd.ID == uuid.Nil {
+		newid, err := uuid.NewRandom()
+		if err != nil {
+			a.logger.Warnf("%s: saveresolv(): generating new id: %v", a.id, err)
+			return uuid.Nil, dnsutil.ErrInternal
+		}
+		rd.ID = newid
+	}
+	// compute fields
+	rd.TLD, _ = publicsuffix.PublicSuffix(rd.Name)
+	rd.TLDPlusOne, _ = publicsuffix.EffectiveTLDPlusOne(rd.Name)
+	// store data
+	err := a.wResolvs.Write(rd)
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): writing: %v", a.id, rd.ID, err)
+		return uuid.Nil, dnsutil.ErrInternal
+	}
+	return rd.ID, nil
+}
+
+// GetResolv implements dnsutil.Finder interface. It scans all files, so it
+// can be very slow.
+func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
+	if !a.started {
+		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
+	}
+	if id == uuid.Nil {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	files, err := a.files()
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): %v", a.id, id, err)
+		return dnsutil.ResolvData{}, false, dnsutil.ErrInternal
+	}
+	// search from newest files
+	sid := []byte(id.String())
+	for i := len(files) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return dnsutil.ResolvData{}, false, dnsutil.ErrCanceledRequest
+		}
+		var found *dnsutil.ResolvData
+		err := fileutil.ScanLines(files[i].Path, func(_ int, line []byte) bool {
+			if !bytes.Contains(line, sid) {
+				return true
+			}
+			var r dnsutil.ResolvData
+			if json.Unmarshal(line, &r) == nil && r.ID == id {
+				found = &r
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			a.logger.Warnf("%s: getresolv(%s): reading '%s': %v", a.id, id, files[i].Path, err)
+			return dnsutil.ResolvData{}, false, dnsutil.ErrInternal
+		}
+		if found != nil {
+			return *found, true, nil
+		}
+	}
+	return dnsutil.ResolvData{}, false, nil
+}
+
+// ListResolvs implements dnsutil.Finder interface. Next is composed by the
+// file name and the line.
+func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if !a.started {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	nextFile, nextLine, err := parseNext(next)
+	if err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	files, err := a.files()
+	if err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", dnsutil.ErrInternal
+	}
+	files = a.filterFiles(files, filters)
+	if rev {
+		for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+			files[i], files[j] = files[j], files[i]
+		}
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	//scan files
+	result := make([]dnsutil.ResolvData, 0)
+	for _, file := range files {
+		if nextFile != "" {
+			if (!rev && file.Name < nextFile) || (rev && file.Name > nextFile) {
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, "", dnsutil.ErrCanceledRequest
+		}
+		var matches []scanMatch
+		var err error
+		if rev {
+			matches, err = scanFileRev(file, filters, nextFile, nextLine, max-len(result))
+		} else {
+			matches, err = scanFile(file, filters, nextFile, nextLine, max-len(result))
+		}
+		if err != nil {
+			a.logger.Warnf("%s: listresolvs(): reading '%s': %v", a.id, file.Path, err)
+			return nil, "", dnsutil.ErrInternal
+		}
+		for _, m := range matches {
+			result = append(result, m.resolv)
+			if max > 0 && len(result) == max {
+				return result, formatNext(file.Name, m.line), nil
+			}
+		}
+	}
+	return result, "", nil
+}
+
+type scanMatch struct {
+	line   int
+	resolv dnsutil.ResolvData
+}
+
+// scanFile returns the first resolvs of the file that match filters, after
+// the line of next if file is the file of next. If need is zero, all
+// matches are returned.
+func scanFile(file fileutil.FileInfo, filters []dnsutil.ResolvsFilter, nextFile string, nextLine, need int) ([]scanMatch, error) {
+	matches := make([]scanMatch, 0)
+	err := fileutil.ScanLines(file.Path, func(n int, line []byte) bool {
+		if file.Name == nextFile && n <= nextLine {
+			return true
+		}
+		var r dnsutil.ResolvData
+		if json.Unmarshal(line, &r) != nil {
+			// last line of current file can be incomplete
+			return true
+		}
+		if !dnsfilter.Match(r, filters) {
+			return true
+		}
+		matches = append(matches, scanMatch{line: n, resolv: r})
+		return need <= 0 || len(matches) < need
+	})
+	return matches, err
+}
+
+// scanFileRev returns the last resolvs of the file that match filters in
+// reverse order, before the line of next if file is the file of next. The
+// file is read forward keeping only the last need matches. If need is zero,
+// all matches are returned.
+func scanFileRev(file fileutil.FileInfo, filters []dnsutil.ResolvsFilter, nextFile string, nextLine, need int) ([]scanMatch, error) {
+	matches := make([]scanMatch, 0)
+	err := fileutil.ScanLines(file.Path, func(n int, line []byte) bool {
+		if file.Name == nextFile && n >= nextLine {
+			return false
+		}
+		var r dnsutil.ResolvData
+		if json.Unmarshal(line, &r) != nil {
+			return true
+		}
+		if !dnsfilter.Match(r, filters) {
+			return true
+		}
+		if need > 0 && len(matches) == 2*need {
+			matches = append(matches[:0], matches[need:]...)
+		}
+		matches = append(matches, scanMatch{line: n, resolv: r})
+		return true
+	})
+	if need > 0 && len(matches) > need {
+		matches = matches[len(matches)-need:]
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, err
+}
+
+// Shutdown closes the files.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down dns archiver", a.id)
+		a.started = false
+		close(a.close)
+		err := a.wResolvs.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	_, err := os.Stat(a.dir)
+	return err
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			err := a.wResolvs.Flush()
+			if err != nil {
+				a.logger.Warnf("%s: sync resolvs: %v", a.id, err)
+			}
+		case <-a.close:
+			return
+		}
+	}
+}
+
+func (a *Archiver) getFileName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+func (a *Archiver) files() ([]fileutil.FileInfo, error) {
+	return a.wResolvs.Files()
+}
+
+// filterFiles removes files out of the time range of filters. Files are
+// partitioned by write time, so a margin of one period is added.
+func (a *Archiver) filterFiles(files []fileutil.FileInfo, filters []dnsutil.ResolvsFilter) []fileutil.FileInfo {
+	var since, to time.Time
+	for i, f := range filters {
+		if f.Since.IsZero() || (i > 0 && since.IsZero()) {
+			since = time.Time{}
+		} else if i == 0 || f.Since.Before(since) {
+			since = f.Since
+		}
+		if f.To.IsZero() || (i > 0 && to.IsZero()) {
+			to = time.Time{}
+		} else if i == 0 || f.To.After(to) {
+			to = f.To
+		}
+	}
+	result := make([]fileutil.FileInfo, 0, len(files))
+	for _, file := range files {
+		if !since.IsZero() && file.Start.Add(2*a.opts.rotate).Before(since) {
+			continue
+		}
+		if !to.IsZero() && file.Start.Add(-a.opts.rotate).After(to) {
+			continue
+		}
+		result = append(result, file)
+	}
+	return result
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.DNSAPI}
+}
+
+func formatNext(name string, line int) string {
+	return fmt.Sprintf("%s:%d", name, line)
+}
+
+func parseNext(next string) (string, int, error) {
+	if next == "" {
+		return "", 0, nil
+	}
+	idx := strings.LastIndex(next, ":")
+	if idx <= 0 || filepath.Base(next[:idx]) != next[:idx] {
+		return "", 0, fmt.Errorf("invalid next '%s'", next)
+	}
+	line, err := strconv.Atoi(next[idx+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid next '%s': %v", next, err)
+	}
+	return next[:idx], line, nil
+}
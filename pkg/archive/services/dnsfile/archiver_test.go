@@ -0,0 +1,62 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsfile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+func TestListResolvsPages(t *testing.T) {
+	a := New("test", t.TempDir(), SetMaxFileSize(600), Compress(true))
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start(): %v", err)
+	}
+	defer a.Shutdown()
+	var ids []uuid.UUID
+	for i := 0; i < 20; i++ {
+		id, err := a.SaveResolv(context.Background(), dnsutil.ResolvData{Timestamp: time.Now(), Name: "www.example.com"})
+		if err != nil {
+			t.Fatalf("SaveResolv(): %v", err)
+		}
+		ids = append(ids, id)
+	}
+	for _, rev := range []bool{false, true} {
+		var got []uuid.UUID
+		next := ""
+		for pages := 0; pages < 10; pages++ {
+			list, n, err := a.ListResolvs(context.Background(), nil, rev, 3, next)
+			if err != nil {
+				t.Fatalf("ListResolvs(): %v", err)
+			}
+			for _, r := range list {
+				got = append(got, r.ID)
+			}
+			if n == "" {
+				break
+			}
+			next = n
+		}
+		if len(got) != len(ids) {
+			t.Fatalf("ListResolvs(rev=%v) returned %v resolvs, want %v", rev, len(got), len(ids))
+		}
+		for i := range ids {
+			want := ids[i]
+			if rev {
+				want = ids[len(ids)-1-i]
+			}
+			if got[i] != want {
+				t.Errorf("ListResolvs(rev=%v)[%v] = %v, want %v", rev, i, got[i], want)
+			}
+		}
+	}
+	r, ok, err := a.GetResolv(context.Background(), ids[4])
+	if err != nil || !ok || r.ID != ids[4] {
+		t.Errorf("GetResolv() = %v, %v, %v", r.ID, ok, err)
+	}
+}
@@ -1,6 +1,8 @@
 // Copyright 2019 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
 
-// Package dnsmdb implements dnsutil.Archive using mongodb backend.
+// Package dnsmdb implements dnsutil.Archive, dnsutil.Finder,
+// dnsquery.Finder, dnsstats.Aggregator and passivedns.Finder using mongodb
+// backend.
 //
 // This package is a work in progress and makes no API stability promises.
 package dnsmdb
@@ -19,6 +21,8 @@ import (
 
 	"github.com/luids-io/api/dnsutil"
 	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/dnsquery"
 	"github.com/luids-io/archive/pkg/mongoutil"
 	"github.com/luids-io/core/yalogi"
 )
@@ -28,7 +32,8 @@ const ServiceClass = "dnsmdb"
 
 // Collection names.
 const (
-	ResolvColName = "resolvs"
+	ResolvColName  = "resolvs"
+	PassiveColName = "pdns"
 )
 
 // Default values.
@@ -37,6 +42,9 @@ const (
 	DefaultResolvBulkSize = 1024
 	DefaultSyncSeconds    = 5
 	DefaultMaxSize        = 100
+	DefaultFollowSize     = 1000
+	DefaultWorkers        = 2
+	DefaultQueueDepth     = 4096
 )
 
 // Archiver implements dns archive backend using a mongo database.
@@ -51,8 +59,19 @@ type Archiver struct {
 	mu      sync.Mutex
 	started bool
 	close   chan struct{}
+	purger  *mongoutil.Purger
+	closeWg sync.WaitGroup
 	//bulks & caches
-	bulkResolvs *mongoutil.Bulk
+	bulkResolvs mongoutil.Writer
+	passive     *passiveCache
+	//partitions
+	pmu        sync.RWMutex
+	partitions map[string]mongoutil.Writer
+	noIndexes  map[string]bool
+	//cache of collection names
+	cmu          sync.Mutex
+	colNames     []string
+	colNamesTime time.Time
 }
 
 // New creates a new storage.
@@ -76,16 +95,29 @@ type Option func(*options)
 
 type options struct {
 	logger         yalogi.Logger
+	health         mongoutil.Health
 	closeSession   bool
 	resolvBulkSize int
 	syncSecs       int
 	prefix         string
+	retention      time.Duration
+	spoolDir       string
+	spoolSize      int64
+	workers        int
+	queueDepth     int
+	queuePolicy    mongoutil.QueuePolicy
+	partition      Partition
+	passive        bool
+	passiveSize    int
 }
 
 var defaultOptions = options{
 	logger:         yalogi.LogNull,
 	resolvBulkSize: DefaultResolvBulkSize,
 	syncSecs:       DefaultSyncSeconds,
+	workers:        DefaultWorkers,
+	queueDepth:     DefaultQueueDepth,
+	passiveSize:    DefaultPassiveSize,
 	closeSession:   false,
 }
 
@@ -112,6 +144,77 @@ func SetPrefix(s string) Option {
 	}
 }
 
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
+// SetSpool option enables a spool in dir where batches are stored when
+// database is unavailable. If maxSize is zero, spool size is unlimited.
+func SetSpool(dir string, maxSize int64) Option {
+	return func(o *options) {
+		o.spoolDir = dir
+		o.spoolSize = maxSize
+	}
+}
+
+// SetWorkers option sets the number of workers that insert documents
+// asynchronously, by default DefaultWorkers. If n is zero, documents are
+// inserted by the callers and bulks are flushed inside their requests, this
+// mode is kept only for compatibility.
+func SetWorkers(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.workers = n
+		}
+	}
+}
+
+// SetQueue option sets the depth and the policy of the insert queues.
+func SetQueue(depth int, policy mongoutil.QueuePolicy) Option {
+	return func(o *options) {
+		if depth > 0 {
+			o.queueDepth = depth
+		}
+		o.queuePolicy = policy
+	}
+}
+
+// SetPartition option stores resolvs in a collection by period of time.
+// Retention drops whole partitions. Aggregations over several partitions
+// require MongoDB 4.4 or later.
+func SetPartition(p Partition) Option {
+	return func(o *options) {
+		o.partition = p
+	}
+}
+
+// SetPassiveDNS option maintains deduplicated records of the names and
+// the resolved values for passive dns queries.
+func SetPassiveDNS(b bool) Option {
+	return func(o *options) {
+		o.passive = b
+	}
+}
+
+// SetPassiveSize sets the max number of passive dns records cached between
+// syncs, zero means no limit.
+func SetPassiveSize(n int) Option {
+	return func(o *options) {
+		o.passiveSize = n
+	}
+}
+
 // Start the archiver.
 func (a *Archiver) Start() error {
 	a.mu.Lock()
@@ -127,13 +230,41 @@ func (a *Archiver) Start() error {
 		return err
 	}
 	//init bulks & caches
-	a.bulkResolvs = mongoutil.NewBulk(
-		a.getCollection(ResolvColName),
-		a.opts.resolvBulkSize,
-	)
+	if a.opts.partition == NoPartition {
+		a.bulkResolvs = a.newWriter(ResolvColName, a.opts.resolvBulkSize)
+		if a.opts.spoolDir != "" {
+			spool, err := mongoutil.NewSpool(a.opts.spoolDir, a.getCollection(ResolvColName).Name, a.opts.spoolSize)
+			if err != nil {
+				return err
+			}
+			a.bulkResolvs.SetSpool(spool)
+		}
+	} else {
+		a.partitions = make(map[string]mongoutil.Writer)
+		a.noIndexes = make(map[string]bool)
+		if a.opts.spoolDir != "" {
+			err := a.openSpooledPartitions()
+			if err != nil {
+				a.closeWriters()
+				return err
+			}
+		}
+	}
+	if a.opts.passive {
+		err := a.createIdxPassive()
+		if err != nil {
+			a.closeWriters()
+			return err
+		}
+		a.passive = newPassiveCache(a.opts.passiveSize)
+	}
 	//init control
 	a.close = make(chan struct{})
+	a.closeWg.Add(1)
 	go a.doSync()
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
 	a.started = true
 	return nil
 }
@@ -143,6 +274,10 @@ func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.
 	if !a.started {
 		return uuid.Nil, dnsutil.ErrUnavailable
 	}
+	// with spool, data is stored while database is down
+	if !a.available() && a.opts.spoolDir == "" {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
 	// create new uuid if not set
 	sid := rd.ID.String()
 	if sid == "" {
@@ -164,19 +299,25 @@ func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.
 		a.logger.Warnf("%s: saveresolv(%s): converting to mongo: %v", a.id, sid, err)
 		return uuid.Nil, dnsutil.ErrBadRequest
 	}
-	// store data
-	m.StorageID = bson.NewObjectId()
-	err = a.bulkResolvs.Insert(m)
+	// store data, storage id is set by the writer
+	err = a.insertResolv(m)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		a.logger.Warnf("%s: saveresolv(%s): %v", a.id, sid, err)
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
 	if err != nil {
 		a.logger.Warnf("%s: saveresolv(%s): inserting in bulk: %v", a.id, sid, err)
-		return uuid.Nil, dnsutil.ErrInternal
+		return uuid.Nil, a.dbError(err)
+	}
+	if a.passive != nil {
+		a.passive.add(m)
 	}
 	return rd.ID, nil
 }
 
 // GetResolv implements dnsutil.Finder interface.
 func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
-	if !a.started {
+	if !a.started || !a.available() {
 		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
 	}
 	//if invalid id, then returns not found
@@ -184,16 +325,19 @@ func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvD
 	if sid == "" {
 		return dnsutil.ResolvData{}, false, nil
 	}
-	//do find
-	var m mdbResolvData
-	c := a.getCollection(ResolvColName)
-	err := c.Find(bson.M{"id": sid}).One(&m)
-	if err == mgo.ErrNotFound {
-		return dnsutil.ResolvData{}, false, nil
+	//do find in collections, newest partitions first
+	names, err := a.resolvCollections(nil)
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): %v", a.id, sid, err)
+		return dnsutil.ResolvData{}, false, a.dbError(err)
 	}
+	m, found, err := a.getResolv(names, sid)
 	if err != nil {
 		a.logger.Warnf("%s: getresolv(%s): %v", a.id, sid, err)
-		return dnsutil.ResolvData{}, false, dnsutil.ErrInternal
+		return dnsutil.ResolvData{}, false, a.dbError(err)
+	}
+	if !found {
+		return dnsutil.ResolvData{}, false, nil
 	}
 	//encode response
 	var r dnsutil.ResolvData
@@ -208,10 +352,14 @@ func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvD
 // ListResolvs implements dnsutil.Finder interface.
 func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter,
 	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
-	if !a.started {
+	if !a.started || !a.available() {
 		return nil, "", dnsutil.ErrUnavailable
 	}
-	c := a.getCollection(ResolvColName)
+	return a.listResolvs("listresolvs", dnsquery.Filters(filters), rev, max, next)
+}
+
+func (a *Archiver) listResolvs(op string, filters []dnsquery.Filter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
 	//create filter
 	filter := createFilter(filters)
 	if next != "" && bson.IsObjectIdHex(next) {
@@ -221,23 +369,19 @@ func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFil
 			filter["_id"] = bson.M{"$gt": bson.ObjectIdHex(next)}
 		}
 	}
-	//do find
-	q := c.Find(filter)
-	if rev {
-		q = q.Sort("-_id")
-	}
 	if max == 0 && DefaultMaxSize > 0 {
 		max = DefaultMaxSize
 	}
-	if max > 0 {
-		q = q.Limit(max)
-	}
 	//do query
-	var mdbAll []mdbResolvData
-	err := q.All(&mdbAll)
+	names, err := a.resolvCollections(dnsquery.BaseFilters(filters))
 	if err != nil {
-		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
-		return nil, "", dnsutil.ErrInternal
+		a.logger.Warnf("%s: %s(): %v", a.id, op, err)
+		return nil, "", a.dbError(err)
+	}
+	mdbAll, err := a.findResolvs(names, filter, rev, max)
+	if err != nil {
+		a.logger.Warnf("%s: %s(): %v", a.id, op, err)
+		return nil, "", a.dbError(err)
 	}
 	//convert data
 	last := ""
@@ -246,7 +390,7 @@ func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFil
 		var r dnsutil.ResolvData
 		err = fromMData(&m, &r)
 		if err != nil {
-			a.logger.Warnf("%s: listresolvs(): converting from mongo '%s': %v", a.id, m.StorageID.Hex(), err)
+			a.logger.Warnf("%s: %s(): converting from mongo '%s': %v", a.id, op, m.StorageID.Hex(), err)
 			return nil, "", dnsutil.ErrInternal
 		}
 		result = append(result, r)
@@ -267,7 +411,14 @@ func (a *Archiver) Shutdown() {
 	if a.started {
 		a.logger.Infof("%s: shutting down dns archiver", a.id)
 		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
 		close(a.close)
+		// waits for the last sync before closing writers and session
+		a.closeWg.Wait()
+		a.closeWriters()
 		a.session.Fsync(false)
 		if a.opts.closeSession {
 			a.session.Close()
@@ -286,35 +437,152 @@ func (a *Archiver) Ping() error {
 }
 
 func (a *Archiver) doSync() {
+	defer a.closeWg.Done()
 	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
 	defer tick.Stop()
+	var passiveFull chan struct{}
+	if a.passive != nil {
+		passiveFull = a.passive.full
+	}
 	for {
 		select {
+		case <-passiveFull:
+			err := a.syncPassive()
+			if err != nil {
+				a.dbError(err)
+				a.logger.Warnf("%s: sync %s: %v", a.id, PassiveColName, err)
+			}
 		case <-tick.C:
 			errs := a.syncBulks()
 			for _, err := range errs {
 				a.logger.Warnf("%s: %v", a.id, err)
 			}
+			a.replayBulks()
+			if a.opts.partition != NoPartition {
+				a.closeIdlePartitions()
+			}
 		case <-a.close:
 			errs := a.syncBulks()
 			for _, err := range errs {
 				a.logger.Warnf("%s: %v", a.id, err)
 			}
-			break
+			return
+		}
+	}
+}
+
+func (a *Archiver) purge(before time.Time) {
+	if a.opts.passive {
+		removed, err := mongoutil.Purge(a.getCollection(PassiveColName), "lastSeen", before)
+		if err != nil {
+			a.logger.Warnf("%s: purging passive dns records: %v", a.id, err)
+		} else if removed > 0 {
+			metrics.PurgedDocuments.WithLabelValues(a.id, PassiveColName).Add(float64(removed))
+			a.logger.Infof("%s: purged %v passive dns records not seen since %v", a.id, removed, before.Format(time.RFC3339))
+		}
+	}
+	if a.opts.partition != NoPartition {
+		dropped, err := a.dropPartitions(before)
+		if err != nil {
+			a.logger.Warnf("%s: dropping partitions: %v", a.id, err)
+		} else if dropped > 0 {
+			a.logger.Infof("%s: dropped %v partitions older than %v", a.id, dropped, before.Format(time.RFC3339))
+		}
+		// resolvs stored before enabling partitions
+		exists, err := a.hasCollection(ResolvColName)
+		if err != nil || !exists {
+			return
+		}
+	}
+	removed, err := mongoutil.Purge(a.getCollection(ResolvColName), "timestamp", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging resolvs: %v", a.id, err)
+		return
+	}
+	metrics.PurgedDocuments.WithLabelValues(a.id, ResolvColName).Add(float64(removed))
+	a.logger.Infof("%s: purged %v resolvs older than %v", a.id, removed, before.Format(time.RFC3339))
+}
+
+func (a *Archiver) replayBulks() {
+	for name, w := range a.writers() {
+		if !w.Pending() || !a.available() || a.session.Ping() != nil {
+			continue
+		}
+		n, err := w.Replay()
+		if err != nil {
+			a.logger.Warnf("%s: replaying %s: %v", a.id, name, err)
+		}
+		if n > 0 {
+			a.logger.Infof("%s: replayed %v %s from spool", a.id, n, name)
 		}
 	}
 }
 
 func (a *Archiver) syncBulks() []error {
 	errs := make([]error, 0, 1)
-	var err error
-	err = a.bulkResolvs.Flush()
-	if err != nil {
-		errs = append(errs, fmt.Errorf("sync resolvs: %v", err))
+	for name, w := range a.writers() {
+		err := w.Flush()
+		if err != nil {
+			a.dbError(err)
+			errs = append(errs, fmt.Errorf("sync %s: %v", name, err))
+		}
+	}
+	if a.passive != nil {
+		err := a.syncPassive()
+		if err != nil {
+			a.dbError(err)
+			errs = append(errs, fmt.Errorf("sync %s: %v", PassiveColName, err))
+		}
 	}
 	return errs
 }
 
+func (a *Archiver) newWriter(name string, size int) mongoutil.Writer {
+	var w mongoutil.Writer
+	if a.opts.workers == 0 {
+		w = mongoutil.NewBulk(a.getCollection(name), size)
+	} else {
+		w = mongoutil.NewPipeline(a.getCollection(name),
+			a.opts.queueDepth, a.opts.workers, size, a.opts.queuePolicy,
+			func(err error) {
+				a.dbError(err)
+				a.logger.Warnf("%s: inserting %s: %v", a.id, name, err)
+			})
+	}
+	w.SetStamp(stampResolv)
+	// partitions are counted as the logical collection
+	w.SetMetrics(a.id, ResolvColName)
+	return w
+}
+
+func (a *Archiver) closeWriters() {
+	for _, w := range a.writers() {
+		err := w.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+	if a.opts.partition != NoPartition {
+		a.pmu.Lock()
+		a.partitions = make(map[string]mongoutil.Writer)
+		a.pmu.Unlock()
+	}
+}
+
+// writers returns the writers of resolvs by collection name.
+func (a *Archiver) writers() map[string]mongoutil.Writer {
+	if a.opts.partition == NoPartition {
+		return map[string]mongoutil.Writer{ResolvColName: a.bulkResolvs}
+	}
+	a.pmu.RLock()
+	defer a.pmu.RUnlock()
+	writers := make(map[string]mongoutil.Writer, len(a.partitions))
+	for name, w := range a.partitions {
+		writers[name] = w
+	}
+	return writers
+}
+
 func (a *Archiver) getDatabase() *mgo.Database {
 	return a.session.DB(a.database)
 }
@@ -326,6 +594,32 @@ func (a *Archiver) getCollection(name string) *mgo.Collection {
 	return a.session.DB(a.database).C(name)
 }
 
+// copyCollection returns a collection using a copy of the session, the
+// session must be closed after use.
+func (a *Archiver) copyCollection(name string) *mgo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.session.Copy().DB(a.database).C(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongoutil.IsUnavailable(err) {
+		return dnsutil.ErrUnavailable
+	}
+	return dnsutil.ErrInternal
+}
+
 // ID implements archive.Service interface.
 func (a *Archiver) ID() string {
 	return a.id
@@ -341,7 +635,7 @@ func (a *Archiver) Implements() []archive.API {
 	return []archive.API{archive.DNSAPI}
 }
 
-func createFilter(filters []dnsutil.ResolvsFilter) bson.M {
+func createFilter(filters []dnsquery.Filter) bson.M {
 	switch len(filters) {
 	case 0:
 		return bson.M{}
@@ -355,7 +649,7 @@ func createFilter(filters []dnsutil.ResolvsFilter) bson.M {
 	return bson.M{"$or": mfilters}
 }
 
-func bsonFilter(f dnsutil.ResolvsFilter) bson.M {
+func bsonFilter(f dnsquery.Filter) bson.M {
 	m := make(bson.M)
 	if !f.Since.IsZero() || !f.To.IsZero() {
 		tfilter := bson.M{}
@@ -367,18 +661,38 @@ func bsonFilter(f dnsutil.ResolvsFilter) bson.M {
 		}
 		m["timestamp"] = tfilter
 	}
+	// conditions over the same fields are joined with $and
+	and := make([]bson.M, 0)
 	if f.Client != nil {
 		m["clientIP"] = f.Client.String()
 	}
+	if f.ClientNet != nil {
+		and = append(and, bsonNetFilter("clientIP", f.ClientNet))
+	}
 	if f.Server != nil {
 		m["serverIP"] = f.Server.String()
 	}
+	if f.ServerNet != nil {
+		and = append(and, bsonNetFilter("serverIP", f.ServerNet))
+	}
 	if f.Name != "" {
 		m["name"] = f.Name
 	}
+	if f.NamePattern != "" {
+		and = append(and, bsonPatternFilter(f.NamePattern))
+	}
+	if f.NameRegex != "" {
+		and = append(and, bson.M{"name": bson.RegEx{Pattern: f.NameRegex}})
+	}
+	if f.Domain != "" {
+		and = append(and, bsonDomainFilter(f.Domain))
+	}
 	if f.ResolvedIP != nil {
 		m["resolvedIPs"] = f.ResolvedIP.String()
 	}
+	if f.ResolvedNet != nil {
+		and = append(and, bsonNetFilter("resolvedIPs", f.ResolvedNet))
+	}
 	if f.ResolvedCNAME != "" {
 		m["resolvedCNAMEs"] = f.ResolvedCNAME
 	}
@@ -394,5 +708,8 @@ func bsonFilter(f dnsutil.ResolvsFilter) bson.M {
 	if f.TLDPlusOne != "" {
 		m["tldPlusOne"] = f.TLDPlusOne
 	}
+	if len(and) > 0 {
+		m["$and"] = and
+	}
 	return m
 }
@@ -4,12 +4,16 @@ package dnsmdb
 
 import "github.com/globalsign/mgo"
 
+// with partitions, indexes are created when partitions are opened.
 func (a *Archiver) createIdx() error {
-	return a.createIdxResolvs()
+	if a.opts.partition != NoPartition {
+		return nil
+	}
+	return a.createIdxResolvs(ResolvColName)
 }
 
-func (a *Archiver) createIdxResolvs() error {
-	c := a.getCollection(ResolvColName)
+func (a *Archiver) createIdxResolvs(name string) error {
+	c := a.getCollection(name)
 	indexes := []mgo.Index{
 		{Key: []string{"id"}, Unique: true},
 		{Key: []string{"timestamp"}},
@@ -19,6 +23,10 @@ func (a *Archiver) createIdxResolvs() error {
 		{Key: []string{"resolvedIPs"}},
 		{Key: []string{"resolvedCNAMEs"}},
 		{Key: []string{"tldPlusOne"}},
+		{Key: []string{"serverIPRaw"}},
+		{Key: []string{"clientIPRaw"}},
+		{Key: []string{"resolvedIPsRaw"}},
+		{Key: []string{"nameRev"}},
 	}
 	for _, idx := range indexes {
 		err := c.EnsureIndex(idx)
@@ -3,6 +3,7 @@ package dnsmdb
 import (
 	"errors"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/globalsign/mgo/bson"
@@ -31,6 +32,11 @@ type mdbResolvData struct {
 	//calculated info
 	TLD        string `bson:"tld"`
 	TLDPlusOne string `bson:"tldPlusOne"`
+	//indexed info for ranges and suffixes
+	ServerIPRaw    []byte   `bson:"serverIPRaw,omitempty"`
+	ClientIPRaw    []byte   `bson:"clientIPRaw,omitempty"`
+	ResolvedIPsRaw [][]byte `bson:"resolvedIPsRaw,omitempty"`
+	NameRev        string   `bson:"nameRev,omitempty"`
 }
 
 type mdbResolvQueryFlags struct {
@@ -77,6 +83,18 @@ func toMData(src *dnsutil.ResolvData, dst *mdbResolvData) (err error) {
 	}
 	dst.TLD = src.TLD
 	dst.TLDPlusOne = src.TLDPlusOne
+	//indexed data
+	dst.ServerIPRaw = rawIP(src.Server)
+	dst.ClientIPRaw = rawIP(src.Client)
+	if len(src.ResolvedIPs) > 0 {
+		dst.ResolvedIPsRaw = make([][]byte, 0, len(src.ResolvedIPs))
+		for _, ip := range src.ResolvedIPs {
+			if raw := rawIP(ip); raw != nil {
+				dst.ResolvedIPsRaw = append(dst.ResolvedIPsRaw, raw)
+			}
+		}
+	}
+	dst.NameRev = reverseName(src.Name)
 	return
 }
 
@@ -117,3 +135,48 @@ func fromMData(src *mdbResolvData, dst *dnsutil.ResolvData) (err error) {
 	dst.TLDPlusOne = src.TLDPlusOne
 	return
 }
+
+// rawIP returns the ip in 16 bytes form, so ipv4 and ipv6 ranges can be
+// compared as binary data.
+func rawIP(ip net.IP) []byte {
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return nil
+	}
+	return []byte(ip16)
+}
+
+// reverseName returns the labels of the name in reverse order, so suffixes
+// of names can be queried as prefixes.
+func reverseName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return ""
+	}
+	labels := strings.Split(name, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
+
+// stampResolv sets a new storage id when the document is inserted, also
+// when it is replayed from the spool. Ids follow the order of insertion, so
+// documents inserted late are not skipped when they are paginated or
+// followed. Replays are idempotent because of the unique index on 'id'.
+func stampResolv(doc interface{}) (interface{}, error) {
+	switch m := doc.(type) {
+	case *mdbResolvData:
+		m.StorageID = bson.NewObjectId()
+		return m, nil
+	case bson.Raw:
+		var r mdbResolvData
+		err := m.Unmarshal(&r)
+		if err != nil {
+			return nil, err
+		}
+		r.StorageID = bson.NewObjectId()
+		return &r, nil
+	}
+	return doc, nil
+}
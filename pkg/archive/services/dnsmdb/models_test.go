@@ -0,0 +1,45 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestStampResolv(t *testing.T) {
+	m := &mdbResolvData{ID: "id1", Name: "www.example.com"}
+	doc, err := stampResolv(m)
+	if err != nil {
+		t.Fatalf("stampResolv(): %v", err)
+	}
+	first := m.StorageID
+	if doc != m || !first.Valid() {
+		t.Fatalf("stampResolv(): storage id not set")
+	}
+
+	// replayed documents get a new id
+	data, err := bson.Marshal(m)
+	if err != nil {
+		t.Fatalf("bson.Marshal(): %v", err)
+	}
+	doc, err = stampResolv(bson.Raw{Kind: 0x03, Data: data})
+	if err != nil {
+		t.Fatalf("stampResolv(): %v", err)
+	}
+	got, ok := doc.(*mdbResolvData)
+	if !ok {
+		t.Fatalf("stampResolv() returned %T", doc)
+	}
+	if got.ID != m.ID || got.Name != m.Name {
+		t.Errorf("stampResolv() = %+v, want %+v", got, m)
+	}
+	if got.StorageID <= first {
+		t.Errorf("stampResolv(): id %v not greater than %v", got.StorageID, first)
+	}
+
+	if _, err := stampResolv(bson.Raw{Kind: 0x03, Data: []byte{1}}); err == nil {
+		t.Error("stampResolv(): expected error")
+	}
+}
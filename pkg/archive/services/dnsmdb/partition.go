@@ -0,0 +1,489 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/mongoutil"
+)
+
+// Partition defines the period of time of resolvs stored in each collection.
+type Partition int
+
+// Partition values.
+const (
+	// NoPartition stores all resolvs in the same collection.
+	NoPartition Partition = iota
+	// Daily stores resolvs in a collection by day.
+	Daily
+	// Weekly stores resolvs in a collection by week, starting on monday.
+	Weekly
+)
+
+func (p Partition) String() string {
+	switch p {
+	case NoPartition:
+		return "none"
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	}
+	return fmt.Sprintf("unknown(%d)", p)
+}
+
+// ParsePartition returns partition from string.
+func ParsePartition(s string) (Partition, error) {
+	switch s {
+	case "none", "":
+		return NoPartition, nil
+	case "daily":
+		return Daily, nil
+	case "weekly":
+		return Weekly, nil
+	}
+	return NoPartition, fmt.Errorf("invalid partition '%s'", s)
+}
+
+// partitions are named with the date in UTC of its start.
+const partitionLayout = "20060102"
+
+// Start returns the start time of the partition that contains t.
+func (p Partition) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if p == Weekly {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// End returns the end time of the partition that starts at start.
+func (p Partition) End(start time.Time) time.Time {
+	if p == Weekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Name returns the collection name, without prefix, of the partition that
+// contains t.
+func (p Partition) Name(t time.Time) string {
+	return ResolvColName + "_" + p.Start(t).Format(partitionLayout)
+}
+
+// parsePartitionName returns the start time of a partition collection name
+// without prefix.
+func parsePartitionName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, ResolvColName+"_") {
+		return time.Time{}, false
+	}
+	suffix := strings.TrimPrefix(name, ResolvColName+"_")
+	if len(suffix) != len(partitionLayout) {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(partitionLayout, suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+type partitionInfo struct {
+	name  string
+	start time.Time
+}
+
+// collectionsTTL is the max time that the names of the collections are
+// cached, so partitions created by other instances are found.
+const collectionsTTL = time.Minute
+
+// collectionNames returns the names of the collections in the database.
+// Names are cached, the cache is reset when partitions are opened or
+// dropped.
+func (a *Archiver) collectionNames() ([]string, error) {
+	a.cmu.Lock()
+	defer a.cmu.Unlock()
+	if a.colNames != nil && time.Since(a.colNamesTime) < collectionsTTL {
+		return a.colNames, nil
+	}
+	db := a.session.Copy().DB(a.database)
+	defer db.Session.Close()
+	names, err := db.CollectionNames()
+	if err != nil {
+		return nil, err
+	}
+	a.colNames, a.colNamesTime = names, time.Now()
+	return names, nil
+}
+
+func (a *Archiver) resetCollectionNames() {
+	a.cmu.Lock()
+	a.colNames = nil
+	a.cmu.Unlock()
+}
+
+// listPartitions returns the partitions stored in the database that may
+// contain resolvs of filters, sorted from newest to oldest.
+func (a *Archiver) listPartitions(filters []dnsutil.ResolvsFilter) ([]partitionInfo, error) {
+	names, err := a.collectionNames()
+	if err != nil {
+		return nil, err
+	}
+	return partitionsOf(names, a.colName(""), a.opts.partition, filters), nil
+}
+
+// partitionsOf returns the partitions in names with prefix that may contain
+// resolvs of filters, sorted from newest to oldest.
+func partitionsOf(names []string, prefix string, p Partition, filters []dnsutil.ResolvsFilter) []partitionInfo {
+	since, to := filtersRange(filters)
+	parts := make([]partitionInfo, 0)
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		start, ok := parsePartitionName(strings.TrimPrefix(name, prefix))
+		if !ok {
+			continue
+		}
+		if !since.IsZero() && !p.End(start).After(since) {
+			continue
+		}
+		if !to.IsZero() && !start.Before(to) {
+			continue
+		}
+		parts = append(parts, partitionInfo{name: strings.TrimPrefix(name, prefix), start: start})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].start.After(parts[j].start) })
+	return parts
+}
+
+// filtersRange returns the range of time of the filters, a zero value means
+// that the range is not bounded.
+func filtersRange(filters []dnsutil.ResolvsFilter) (since, to time.Time) {
+	if len(filters) == 0 {
+		return
+	}
+	for i, f := range filters {
+		if i == 0 {
+			since, to = f.Since, f.To
+			continue
+		}
+		if f.Since.IsZero() || (!since.IsZero() && f.Since.Before(since)) {
+			since = f.Since
+		}
+		if f.To.IsZero() || (!to.IsZero() && f.To.After(to)) {
+			to = f.To
+		}
+	}
+	return
+}
+
+// resolvCollections returns the names, without prefix, of the collections
+// that may contain resolvs of filters, newest partitions first. Collection
+// of resolvs without partition is included at the end if it exists.
+func (a *Archiver) resolvCollections(filters []dnsutil.ResolvsFilter) ([]string, error) {
+	if a.opts.partition == NoPartition {
+		return []string{ResolvColName}, nil
+	}
+	all, err := a.collectionNames()
+	if err != nil {
+		return nil, err
+	}
+	parts := partitionsOf(all, a.colName(""), a.opts.partition, filters)
+	names := make([]string, 0, len(parts)+1)
+	for _, p := range parts {
+		names = append(names, p.name)
+	}
+	if containsString(all, a.colName(ResolvColName)) {
+		names = append(names, ResolvColName)
+	}
+	return names, nil
+}
+
+func (a *Archiver) hasCollection(name string) (bool, error) {
+	names, err := a.collectionNames()
+	if err != nil {
+		return false, err
+	}
+	return containsString(names, a.colName(name)), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// insertResolv inserts the document in the writer of its partition.
+func (a *Archiver) insertResolv(m *mdbResolvData) error {
+	if a.opts.partition == NoPartition {
+		return a.bulkResolvs.Insert(m)
+	}
+	t := m.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	name := a.opts.partition.Name(t)
+	a.pmu.RLock()
+	w, ok := a.partitions[name]
+	if ok {
+		defer a.pmu.RUnlock()
+		return w.Insert(m)
+	}
+	a.pmu.RUnlock()
+	// partition is opened and the document inserted holding the lock,
+	// so the writer can't be closed before the insertion
+	a.pmu.Lock()
+	defer a.pmu.Unlock()
+	w, err := a.openPartition(name)
+	if err != nil {
+		return err
+	}
+	return w.Insert(m)
+}
+
+// openPartition returns the writer of the partition, it is created if it
+// doesn't exist. It must be called holding the lock.
+func (a *Archiver) openPartition(name string) (mongoutil.Writer, error) {
+	w, ok := a.partitions[name]
+	if ok {
+		return w, nil
+	}
+	w = a.newWriter(name, a.opts.resolvBulkSize)
+	if a.opts.spoolDir != "" {
+		spool, err := mongoutil.NewSpool(a.opts.spoolDir, a.colName(name), a.opts.spoolSize)
+		if err != nil {
+			return nil, err
+		}
+		w.SetSpool(spool)
+	}
+	a.partitions[name] = w
+	// with spool, indexes are created later if database is down
+	err := a.createIdxResolvs(name)
+	a.resetCollectionNames()
+	if err != nil {
+		a.logger.Warnf("%s: creating indexes of '%s': %v", a.id, name, err)
+		a.noIndexes[name] = true
+	}
+	return w, nil
+}
+
+// openSpooledPartitions opens the partitions with batches in the spool, so
+// they are replayed.
+func (a *Archiver) openSpooledPartitions() error {
+	prefix := a.colName(ResolvColName + "_")
+	paths, err := filepath.Glob(filepath.Join(a.opts.spoolDir, prefix+"*"))
+	if err != nil {
+		return err
+	}
+	a.pmu.Lock()
+	defer a.pmu.Unlock()
+	for _, path := range paths {
+		base := filepath.Base(path)
+		idx := strings.LastIndex(base, "-")
+		if idx < 0 {
+			continue
+		}
+		name := strings.TrimPrefix(base[:idx], a.colName(""))
+		if _, ok := parsePartitionName(name); !ok {
+			continue
+		}
+		_, err := a.openPartition(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeIdlePartitions closes writers of partitions older than the previous
+// one and without batches pending in the spool. It also retries creating
+// the indexes of partitions.
+func (a *Archiver) closeIdlePartitions() {
+	now := time.Now()
+	current := a.opts.partition.Start(now)
+	previous := a.opts.partition.Start(current.Add(-time.Second))
+	a.pmu.Lock()
+	defer a.pmu.Unlock()
+	for name := range a.noIndexes {
+		err := a.createIdxResolvs(name)
+		if err == nil {
+			delete(a.noIndexes, name)
+			a.resetCollectionNames()
+		}
+	}
+	for name, w := range a.partitions {
+		start, _ := parsePartitionName(name)
+		if !start.Before(previous) || w.Pending() {
+			continue
+		}
+		err := w.Close()
+		if err != nil {
+			a.logger.Warnf("%s: closing partition '%s': %v", a.id, name, err)
+		}
+		delete(a.partitions, name)
+	}
+}
+
+// dropPartitions drops the partitions that end before the time, their
+// documents are counted as purged resolvs.
+func (a *Archiver) dropPartitions(before time.Time) (int, error) {
+	parts, err := a.listPartitions(nil)
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for _, p := range parts {
+		if a.opts.partition.End(p.start).After(before) {
+			continue
+		}
+		a.pmu.RLock()
+		_, open := a.partitions[p.name]
+		a.pmu.RUnlock()
+		if open {
+			continue
+		}
+		c := a.getCollection(p.name)
+		removed, err := c.Count()
+		if err != nil {
+			return dropped, fmt.Errorf("counting '%s': %v", p.name, err)
+		}
+		err = c.DropCollection()
+		a.resetCollectionNames()
+		if err != nil {
+			return dropped, fmt.Errorf("dropping '%s': %v", p.name, err)
+		}
+		metrics.PurgedDocuments.WithLabelValues(a.id, ResolvColName).Add(float64(removed))
+		dropped++
+	}
+	return dropped, nil
+}
+
+// findResolvs queries the collections and merges the results sorted by
+// storage id. Collections are queried in the order of the page, once the
+// page is full only documents that sort before the last one of the page
+// are queried, so the queries of the remaining collections are cheap.
+// Partitions are chosen by timestamp and ids are set at insertion, so
+// collections can't be skipped.
+func (a *Archiver) findResolvs(names []string, filter bson.M, rev bool, max int) ([]mdbResolvData, error) {
+	if !rev {
+		// oldest documents are in the oldest collections
+		rnames := make([]string, 0, len(names))
+		for i := len(names) - 1; i >= 0; i-- {
+			rnames = append(rnames, names[i])
+		}
+		names = rnames
+	}
+	all := make([]mdbResolvData, 0)
+	for _, name := range names {
+		query := filter
+		if max > 0 && len(all) == max {
+			last := all[len(all)-1].StorageID
+			if rev {
+				query = bson.M{"$and": []bson.M{filter, {"_id": bson.M{"$gt": last}}}}
+			} else {
+				query = bson.M{"$and": []bson.M{filter, {"_id": bson.M{"$lt": last}}}}
+			}
+		}
+		c := a.copyCollection(name)
+		q := c.Find(query)
+		if rev {
+			q = q.Sort("-_id")
+		} else {
+			q = q.Sort("_id")
+		}
+		if max > 0 {
+			q = q.Limit(max)
+		}
+		var result []mdbResolvData
+		err := q.All(&result)
+		c.Database.Session.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = mergeResolvs(all, result, rev, max)
+	}
+	return all, nil
+}
+
+// mergeResolvs merges two lists sorted by storage id and returns at most
+// max documents.
+func mergeResolvs(a, b []mdbResolvData, rev bool, max int) []mdbResolvData {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	merged := make([]mdbResolvData, 0, len(a)+len(b))
+	i, j := 0, 0
+	for (max <= 0 || len(merged) < max) && (i < len(a) || j < len(b)) {
+		takeA := j == len(b)
+		if i < len(a) && j < len(b) {
+			if rev {
+				takeA = a[i].StorageID > b[j].StorageID
+			} else {
+				takeA = a[i].StorageID < b[j].StorageID
+			}
+		}
+		if takeA {
+			merged = append(merged, a[i])
+			i++
+		} else {
+			merged = append(merged, b[j])
+			j++
+		}
+	}
+	return merged
+}
+
+// getResolv finds the resolv in the collections with a single query, the
+// collections after the first are added using $unionWith.
+func (a *Archiver) getResolv(names []string, sid string) (mdbResolvData, bool, error) {
+	var m mdbResolvData
+	if len(names) == 0 {
+		return m, false, nil
+	}
+	match := bson.M{"$match": bson.M{"id": sid}}
+	pipeline := []bson.M{match}
+	for _, name := range names[1:] {
+		pipeline = append(pipeline, bson.M{"$unionWith": bson.M{
+			"coll":     a.colName(name),
+			"pipeline": []bson.M{match},
+		}})
+	}
+	pipeline = append(pipeline, bson.M{"$limit": 1})
+	c := a.copyCollection(names[0])
+	defer c.Database.Session.Close()
+	err := c.Pipe(pipeline).One(&m)
+	if err == mgo.ErrNotFound {
+		return m, false, nil
+	}
+	if err != nil {
+		return m, false, err
+	}
+	return m, true, nil
+}
+
+// colName returns the name of a collection with the prefix.
+func (a *Archiver) colName(name string) string {
+	if a.opts.prefix != "" {
+		return a.opts.prefix + "_" + name
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+func TestPartitionName(t *testing.T) {
+	// wednesday, late in UTC+2
+	ts := time.Date(2021, 3, 17, 23, 30, 0, 0, time.FixedZone("", 2*3600))
+	tests := []struct {
+		p    Partition
+		name string
+		end  time.Time
+	}{
+		{Daily, "resolvs_20210317", time.Date(2021, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{Weekly, "resolvs_20210315", time.Date(2021, 3, 22, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Name(ts); got != tt.name {
+			t.Errorf("%v.Name() = %v, want %v", tt.p, got, tt.name)
+		}
+		start, ok := parsePartitionName(tt.name)
+		if !ok || !start.Equal(tt.p.Start(ts)) {
+			t.Errorf("parsePartitionName(%v) = %v, %v", tt.name, start, ok)
+		}
+		if got := tt.p.End(start); !got.Equal(tt.end) {
+			t.Errorf("%v.End() = %v, want %v", tt.p, got, tt.end)
+		}
+	}
+	for _, name := range []string{"resolvs", "resolvs_2021031", "resolvs_2021x317", "passive_20210317"} {
+		if _, ok := parsePartitionName(name); ok {
+			t.Errorf("parsePartitionName(%v) expected false", name)
+		}
+	}
+}
+
+func TestFiltersRange(t *testing.T) {
+	t1 := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(24 * time.Hour)
+	t3 := t2.Add(24 * time.Hour)
+	tests := []struct {
+		filters   []dnsutil.ResolvsFilter
+		since, to time.Time
+	}{
+		{nil, time.Time{}, time.Time{}},
+		{[]dnsutil.ResolvsFilter{{Since: t1, To: t2}}, t1, t2},
+		{[]dnsutil.ResolvsFilter{{Since: t2, To: t3}, {Since: t1, To: t2}}, t1, t3},
+		{[]dnsutil.ResolvsFilter{{Since: t2}, {Since: t1, To: t2}}, t1, time.Time{}},
+		{[]dnsutil.ResolvsFilter{{Since: t2, To: t3}, {To: t2}}, time.Time{}, t3},
+	}
+	for i, tt := range tests {
+		since, to := filtersRange(tt.filters)
+		if !since.Equal(tt.since) || !to.Equal(tt.to) {
+			t.Errorf("%v: filtersRange() = %v, %v, want %v, %v", i, since, to, tt.since, tt.to)
+		}
+	}
+}
+
+func TestPartitionsOf(t *testing.T) {
+	names := []string{"p_resolvs", "p_resolvs_20210301", "p_resolvs_20210303", "p_resolvs_20210302",
+		"p_passive", "resolvs_20210304", "p_resolvs_bad"}
+	got := partitionsOf(names, "p_", Daily, nil)
+	var gotNames []string
+	for _, p := range got {
+		gotNames = append(gotNames, p.name)
+	}
+	want := []string{"resolvs_20210303", "resolvs_20210302", "resolvs_20210301"}
+	if !reflect.DeepEqual(gotNames, want) {
+		t.Errorf("partitionsOf() = %v, want %v", gotNames, want)
+	}
+	filters := []dnsutil.ResolvsFilter{{
+		Since: time.Date(2021, 3, 2, 12, 0, 0, 0, time.UTC),
+		To:    time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC),
+	}}
+	got = partitionsOf(names, "p_", Daily, filters)
+	if len(got) != 1 || got[0].name != "resolvs_20210302" {
+		t.Errorf("partitionsOf() with range = %v", got)
+	}
+}
+
+func TestMergeResolvs(t *testing.T) {
+	ids := make([]bson.ObjectId, 6)
+	for i := range ids {
+		ids[i] = bson.NewObjectIdWithTime(time.Unix(int64(1000+i), 0))
+	}
+	mk := func(idx ...int) []mdbResolvData {
+		l := make([]mdbResolvData, 0, len(idx))
+		for _, i := range idx {
+			l = append(l, mdbResolvData{StorageID: ids[i]})
+		}
+		return l
+	}
+	tests := []struct {
+		a, b []mdbResolvData
+		rev  bool
+		max  int
+		want []mdbResolvData
+	}{
+		{mk(0, 2, 4), mk(1, 3), false, 0, mk(0, 1, 2, 3, 4)},
+		{mk(0, 2, 4), mk(1, 3), false, 3, mk(0, 1, 2)},
+		{mk(4, 2), mk(5, 3, 1), true, 3, mk(5, 4, 3)},
+		{mk(), mk(1, 3), false, 3, mk(1, 3)},
+		{mk(1), mk(), false, 3, mk(1)},
+	}
+	for i, tt := range tests {
+		got := mergeResolvs(tt.a, tt.b, tt.rev, tt.max)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: mergeResolvs() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
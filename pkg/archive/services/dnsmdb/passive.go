@@ -0,0 +1,273 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/passivedns"
+)
+
+// DefaultPassiveSize is the max number of records deduplicated in memory
+// between syncs. When the cache is full, it is synced before the period.
+const DefaultPassiveSize = 65536
+
+type mdbPassiveData struct {
+	StorageID bson.ObjectId `bson:"_id"`
+	Name      string        `bson:"name"`
+	Type      string        `bson:"type"`
+	Value     string        `bson:"value"`
+	FirstSeen time.Time     `bson:"firstSeen"`
+	LastSeen  time.Time     `bson:"lastSeen"`
+	Count     int64         `bson:"count"`
+}
+
+type passiveKey struct {
+	name, rrtype, value string
+}
+
+type passiveCounter struct {
+	firstSeen, lastSeen time.Time
+	count               int64
+}
+
+// passiveCache deduplicates the records of the resolvs saved between
+// syncs, so each record is upserted once per sync. When the cache is full,
+// it notifies through channel full and new records are dropped until it is
+// synced.
+type passiveCache struct {
+	mu      sync.Mutex
+	maxSize int
+	records map[passiveKey]*passiveCounter
+	dropped int
+	full    chan struct{}
+}
+
+func newPassiveCache(maxSize int) *passiveCache {
+	return &passiveCache{
+		maxSize: maxSize,
+		records: make(map[passiveKey]*passiveCounter),
+		full:    make(chan struct{}, 1),
+	}
+}
+
+// add records of the resolv.
+func (c *passiveCache) add(m *mdbResolvData) {
+	name := strings.ToLower(strings.TrimSuffix(m.Name, "."))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ip := range m.ResolvedIPs {
+		rrtype := passivedns.TypeA
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			rrtype = passivedns.TypeAAAA
+		}
+		c.addKey(passiveKey{name: name, rrtype: rrtype, value: ip}, m.Timestamp, 1)
+	}
+	for _, cname := range m.ResolvedCNAMEs {
+		cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+		c.addKey(passiveKey{name: name, rrtype: passivedns.TypeCNAME, value: cname}, m.Timestamp, 1)
+	}
+	if c.maxSize > 0 && len(c.records) >= c.maxSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// addKey returns false if the record is dropped because the cache is full.
+// It must be called holding the lock.
+func (c *passiveCache) addKey(k passiveKey, t time.Time, count int64) bool {
+	r, ok := c.records[k]
+	if !ok {
+		if c.maxSize > 0 && len(c.records) >= c.maxSize {
+			c.dropped++
+			return false
+		}
+		c.records[k] = &passiveCounter{firstSeen: t, lastSeen: t, count: count}
+		return true
+	}
+	if t.Before(r.firstSeen) {
+		r.firstSeen = t
+	}
+	if t.After(r.lastSeen) {
+		r.lastSeen = t
+	}
+	r.count += count
+	return true
+}
+
+// take returns the records and the number of records dropped, and empties
+// the cache.
+func (c *passiveCache) take() (map[passiveKey]*passiveCounter, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records, dropped := c.records, c.dropped
+	c.records = make(map[passiveKey]*passiveCounter)
+	c.dropped = 0
+	return records, dropped
+}
+
+// restore adds records that couldn't be stored. Records dropped because
+// the cache is full are counted in the next take.
+func (c *passiveCache) restore(records map[passiveKey]*passiveCounter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, r := range records {
+		if c.addKey(k, r.firstSeen, r.count) {
+			c.addKey(k, r.lastSeen, 0)
+		}
+	}
+}
+
+// syncPassive upserts the records deduplicated since the last sync.
+func (a *Archiver) syncPassive() error {
+	records, dropped := a.passive.take()
+	if dropped > 0 {
+		a.logger.Warnf("%s: dropped %v passive dns records: cache is full", a.id, dropped)
+		metrics.PassiveDropped.WithLabelValues(a.id).Add(float64(dropped))
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	c := a.copyCollection(PassiveColName)
+	defer c.Database.Session.Close()
+	bulk := c.Bulk()
+	bulk.Unordered()
+	keys := make([]passiveKey, 0, len(records))
+	for k, r := range records {
+		keys = append(keys, k)
+		bulk.Upsert(
+			bson.M{"name": k.name, "type": k.rrtype, "value": k.value},
+			bson.M{
+				"$min": bson.M{"firstSeen": r.firstSeen},
+				"$max": bson.M{"lastSeen": r.lastSeen},
+				"$inc": bson.M{"count": r.count},
+			})
+	}
+	_, err := bulk.Run()
+	if err != nil {
+		a.passive.restore(failedRecords(records, keys, err))
+		return err
+	}
+	metrics.Documents.WithLabelValues(a.id, PassiveColName).Add(float64(len(records)))
+	return nil
+}
+
+// failedRecords returns the records of the upserts that failed. If the
+// failed operations are unknown, all records are returned.
+func failedRecords(records map[passiveKey]*passiveCounter, keys []passiveKey, err error) map[passiveKey]*passiveCounter {
+	berr, ok := err.(*mgo.BulkError)
+	if !ok || len(berr.Cases()) == 0 {
+		return records
+	}
+	failed := make(map[passiveKey]*passiveCounter)
+	for _, ecase := range berr.Cases() {
+		if ecase.Index < 0 || ecase.Index >= len(keys) {
+			return records
+		}
+		k := keys[ecase.Index]
+		failed[k] = records[k]
+	}
+	return failed
+}
+
+// ListRecords implements passivedns.Finder interface.
+func (a *Archiver) ListRecords(ctx context.Context, f passivedns.RecordsFilter, max int, next string) ([]passivedns.Record, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	if !a.opts.passive {
+		return nil, "", dnsutil.ErrNotSupported
+	}
+	if f.Empty() {
+		a.logger.Warnf("%s: listrecords(): name, ip or cname is required", a.id)
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	//create filter
+	filter := passiveFilter(f)
+	if next != "" && bson.IsObjectIdHex(next) {
+		filter["_id"] = bson.M{"$gt": bson.ObjectIdHex(next)}
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	//do query
+	c := a.copyCollection(PassiveColName)
+	defer c.Database.Session.Close()
+	q := c.Find(filter).Sort("_id")
+	if max > 0 {
+		q = q.Limit(max)
+	}
+	var mdbAll []mdbPassiveData
+	err := q.All(&mdbAll)
+	if err != nil {
+		a.logger.Warnf("%s: listrecords(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//convert data
+	last := ""
+	result := make([]passivedns.Record, 0, len(mdbAll))
+	for _, m := range mdbAll {
+		result = append(result, passivedns.Record{
+			Name:      m.Name,
+			Type:      m.Type,
+			Value:     m.Value,
+			FirstSeen: m.FirstSeen,
+			LastSeen:  m.LastSeen,
+			Count:     m.Count,
+		})
+		last = m.StorageID.Hex()
+	}
+	if max > 0 && len(result) == max {
+		return result, last, nil
+	}
+	return result, "", nil
+}
+
+func passiveFilter(f passivedns.RecordsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() {
+		m["lastSeen"] = bson.M{"$gte": f.Since}
+	}
+	if !f.To.IsZero() {
+		m["firstSeen"] = bson.M{"$lte": f.To}
+	}
+	if f.Name != "" {
+		m["name"] = strings.ToLower(strings.TrimSuffix(f.Name, "."))
+	}
+	switch {
+	case f.IP != nil:
+		m["type"] = bson.M{"$in": []string{passivedns.TypeA, passivedns.TypeAAAA}}
+		m["value"] = f.IP.String()
+	case f.CNAME != "":
+		m["type"] = passivedns.TypeCNAME
+		m["value"] = strings.ToLower(strings.TrimSuffix(f.CNAME, "."))
+	}
+	return m
+}
+
+func (a *Archiver) createIdxPassive() error {
+	c := a.getCollection(PassiveColName)
+	indexes := []mgo.Index{
+		{Key: []string{"name", "type", "value"}, Unique: true},
+		{Key: []string{"value"}},
+		{Key: []string{"lastSeen"}},
+	}
+	for _, idx := range indexes {
+		err := c.EnsureIndex(idx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPassiveCache(t *testing.T) {
+	now := time.Now()
+	c := newPassiveCache(3)
+	c.add(&mdbResolvData{Name: "www.Example.com.", Timestamp: now,
+		ResolvedIPs: []string{"10.0.0.1", "fe80::1"}})
+	select {
+	case <-c.full:
+		t.Fatal("add(): full notified before the limit")
+	default:
+	}
+	c.add(&mdbResolvData{Name: "www.example.com", Timestamp: now.Add(time.Second),
+		ResolvedIPs: []string{"10.0.0.1"}, ResolvedCNAMEs: []string{"web.example.com."}})
+	select {
+	case <-c.full:
+	default:
+		t.Fatal("add(): full not notified")
+	}
+	c.add(&mdbResolvData{Name: "other.example.com", Timestamp: now,
+		ResolvedIPs: []string{"10.0.0.2", "10.0.0.3"}})
+
+	records, dropped := c.take()
+	if len(records) != 3 || dropped != 2 {
+		t.Fatalf("take() = %v records, %v dropped; want 3, 2", len(records), dropped)
+	}
+	r := records[passiveKey{name: "www.example.com", rrtype: "A", value: "10.0.0.1"}]
+	if r == nil {
+		t.Fatal("take(): record not found")
+	}
+	if r.count != 2 || !r.firstSeen.Equal(now) || !r.lastSeen.Equal(now.Add(time.Second)) {
+		t.Errorf("take(): record %+v", r)
+	}
+	if _, ok := records[passiveKey{name: "www.example.com", rrtype: "AAAA", value: "fe80::1"}]; !ok {
+		t.Error("take(): AAAA record not found")
+	}
+	if _, ok := records[passiveKey{name: "www.example.com", rrtype: "CNAME", value: "web.example.com"}]; !ok {
+		t.Error("take(): CNAME record not found")
+	}
+	if records, dropped := c.take(); len(records) != 0 || dropped != 0 {
+		t.Errorf("take() = %v records, %v dropped; want empty", len(records), dropped)
+	}
+
+	// failed records are restored up to the limit
+	c.add(&mdbResolvData{Name: "new.example.com", Timestamp: now, ResolvedIPs: []string{"10.0.0.4"}})
+	c.restore(records)
+	records, dropped = c.take()
+	if len(records) != 3 || dropped != 1 {
+		t.Errorf("take() = %v records, %v dropped; want 3, 1", len(records), dropped)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/dnsquery"
+)
+
+// QueryResolvs implements dnsquery.Finder interface.
+//
+// Networks are matched against ips stored in binary form and suffixes of
+// names against names stored with labels reversed. Documents stored without
+// these fields are matched with regular expressions over the ips and names,
+// in this case ipv6 networks only match single hosts.
+func (a *Archiver) QueryResolvs(ctx context.Context, filters []dnsquery.Filter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	err := validateFilters(filters)
+	if err != nil {
+		a.logger.Warnf("%s: queryresolvs(): %v", a.id, err)
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	return a.listResolvs("queryresolvs", filters, rev, max, next)
+}
+
+// FollowResolvs implements dnsquery.Follower interface.
+//
+// Collections are polled each sync period for documents with ids created
+// since the previous period. Ids are set by the writers when documents are
+// inserted, but several workers insert bulks concurrently, so a document
+// can be visible after others with greater ids: polls overlap and documents
+// already sent are discarded. Each poll reads at most DefaultFollowSize
+// documents, the next ones are read before waiting for the next period.
+func (a *Archiver) FollowResolvs(ctx context.Context, filters []dnsquery.Filter, fn func(dnsutil.ResolvData) error) error {
+	if !a.started || !a.available() {
+		return dnsutil.ErrUnavailable
+	}
+	err := validateFilters(filters)
+	if err != nil {
+		a.logger.Warnf("%s: followresolvs(): %v", a.id, err)
+		return dnsutil.ErrBadRequest
+	}
+	period := time.Duration(a.opts.syncSecs) * time.Second
+	start := time.Now()
+	sent := make(map[bson.ObjectId]bool)
+	tick := time.NewTicker(period)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-a.close:
+			return dnsutil.ErrUnavailable
+		case <-tick.C:
+		}
+		// ids have a precision of seconds
+		since := time.Now().Add(-2*period - time.Second)
+		if since.Before(start) {
+			since = start
+		}
+		names, err := a.resolvCollections(dnsquery.BaseFilters(filters))
+		if err != nil {
+			a.logger.Warnf("%s: followresolvs(): %v", a.id, err)
+			return a.dbError(err)
+		}
+		from := bson.M{"$gte": bson.NewObjectIdWithTime(since)}
+		for {
+			filter := createFilter(filters)
+			filter["_id"] = from
+			mdbAll, err := a.findResolvs(names, filter, false, DefaultFollowSize)
+			if err != nil {
+				a.logger.Warnf("%s: followresolvs(): %v", a.id, err)
+				return a.dbError(err)
+			}
+			for _, m := range mdbAll {
+				if sent[m.StorageID] {
+					continue
+				}
+				sent[m.StorageID] = true
+				var r dnsutil.ResolvData
+				err = fromMData(&m, &r)
+				if err != nil {
+					a.logger.Warnf("%s: followresolvs(): converting from mongo '%s': %v", a.id, m.StorageID.Hex(), err)
+					return dnsutil.ErrInternal
+				}
+				err = fn(r)
+				if err != nil {
+					return err
+				}
+			}
+			if len(mdbAll) < DefaultFollowSize {
+				break
+			}
+			from = bson.M{"$gt": mdbAll[len(mdbAll)-1].StorageID}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-a.close:
+				return dnsutil.ErrUnavailable
+			default:
+			}
+		}
+		// forget ids out of the next poll
+		for id := range sent {
+			if id.Time().Before(since.Truncate(time.Second)) {
+				delete(sent, id)
+			}
+		}
+	}
+}
+
+func validateFilters(filters []dnsquery.Filter) error {
+	for _, f := range filters {
+		if f.NameRegex == "" {
+			continue
+		}
+		_, err := regexp.Compile(f.NameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid name regex: %v", err)
+		}
+	}
+	return nil
+}
+
+// bsonNetFilter returns the filter for the ips of the network in field.
+func bsonNetFilter(field string, n *net.IPNet) bson.M {
+	start, end := netRange(n)
+	var rng interface{} = bson.M{"$gte": start, "$lte": end}
+	if field == "resolvedIPs" {
+		rng = bson.M{"$elemMatch": rng}
+	}
+	return orLegacy(field+"Raw", rng, field, bson.RegEx{Pattern: netPattern(n)})
+}
+
+// bsonPatternFilter returns the filter for the names that matches the
+// pattern. Names are matched ignoring case. Patterns of suffixes like
+// '*.example.com' use reversed names.
+func bsonPatternFilter(pattern string) bson.M {
+	pattern = strings.ToLower(pattern)
+	if strings.HasPrefix(pattern, "*.") && !strings.Contains(pattern[2:], "*") {
+		rev := regexp.QuoteMeta(reverseName(pattern[2:]))
+		legacy := bson.RegEx{Pattern: namePattern(pattern), Options: "i"}
+		return orLegacy("nameRev", bson.RegEx{Pattern: "^" + rev + `\.`}, "name", legacy)
+	}
+	return bson.M{"name": bson.RegEx{Pattern: namePattern(pattern), Options: "i"}}
+}
+
+// bsonDomainFilter returns the filter for the domain and its subdomains.
+func bsonDomainFilter(domain string) bson.M {
+	rev := regexp.QuoteMeta(reverseName(domain))
+	legacy := bson.RegEx{Pattern: `^(.*\.)?` + regexp.QuoteMeta(strings.TrimSuffix(domain, ".")) + "$", Options: "i"}
+	return orLegacy("nameRev", bson.RegEx{Pattern: "^" + rev + `(\.|$)`}, "name", legacy)
+}
+
+// orLegacy returns a filter with the condition over the indexed field or
+// the legacy condition for documents stored without it.
+func orLegacy(field string, cond interface{}, legacyField string, legacy interface{}) bson.M {
+	return bson.M{"$or": []bson.M{
+		{field: cond},
+		{field: bson.M{"$exists": false}, legacyField: legacy},
+	}}
+}
+
+// netRange returns the first and last ips of the network in binary form.
+func netRange(n *net.IPNet) (start, end []byte) {
+	ip := n.IP.To16()
+	mask := n.Mask
+	if len(mask) == net.IPv4len {
+		ones, _ := mask.Size()
+		mask = net.CIDRMask(ones+8*(net.IPv6len-net.IPv4len), 8*net.IPv6len)
+	}
+	start = make([]byte, net.IPv6len)
+	end = make([]byte, net.IPv6len)
+	for i := 0; i < net.IPv6len; i++ {
+		start[i] = ip[i] & mask[i]
+		end[i] = start[i] | ^mask[i]
+	}
+	return
+}
+
+// netPattern returns a regular expression that matches the ipv4 addresses
+// of the network in dotted notation. Octets of the mask are matched as
+// prefix and the partial octet with an alternation of its values.
+func netPattern(n *net.IPNet) string {
+	ones, bits := n.Mask.Size()
+	ip4 := n.IP.To4()
+	if ip4 == nil {
+		// only hosts
+		return "^" + regexp.QuoteMeta(n.IP.String()) + "$"
+	}
+	if bits == 8*net.IPv6len {
+		ones -= 8 * (net.IPv6len - net.IPv4len)
+	}
+	full, rem := ones/8, ones%8
+	parts := make([]string, 0, 4)
+	for i := 0; i < full; i++ {
+		parts = append(parts, strconv.Itoa(int(ip4[i])))
+	}
+	if full == 4 {
+		return "^" + strings.Join(parts, `\.`) + "$"
+	}
+	if rem > 0 {
+		values := make([]string, 0, 1<<(8-rem))
+		for v := int(ip4[full]); v < int(ip4[full])+1<<(8-rem); v++ {
+			values = append(values, strconv.Itoa(v))
+		}
+		parts = append(parts, "("+strings.Join(values, "|")+")")
+	}
+	if len(parts) == 0 {
+		return `^[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+$`
+	}
+	pattern := "^" + strings.Join(parts, `\.`)
+	if len(parts) == 4 {
+		return pattern + "$"
+	}
+	return pattern + `\.`
+}
+
+// namePattern returns a regular expression from a wildcard pattern.
+func namePattern(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return fmt.Sprintf("^%s$", strings.Join(parts, ".*"))
+}
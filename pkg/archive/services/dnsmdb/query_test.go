@@ -0,0 +1,100 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestNetRange(t *testing.T) {
+	tests := []struct {
+		cidr       string
+		start, end string
+	}{
+		{"10.1.2.3/8", "10.0.0.0", "10.255.255.255"},
+		{"192.168.1.0/24", "192.168.1.0", "192.168.1.255"},
+		{"192.168.1.7/32", "192.168.1.7", "192.168.1.7"},
+		{"2001:db8::1/32", "2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"},
+	}
+	for _, tt := range tests {
+		_, n, _ := net.ParseCIDR(tt.cidr)
+		start, end := netRange(n)
+		if !bytes.Equal(start, rawIP(net.ParseIP(tt.start))) || !bytes.Equal(end, rawIP(net.ParseIP(tt.end))) {
+			t.Errorf("netRange(%v) = %v, %v, want %v, %v", tt.cidr, net.IP(start), net.IP(end), tt.start, tt.end)
+		}
+	}
+}
+
+func TestNetPattern(t *testing.T) {
+	tests := []struct {
+		cidr     string
+		match    []string
+		notMatch []string
+	}{
+		{"10.0.0.0/8", []string{"10.0.0.1", "10.255.1.2"}, []string{"100.0.0.1", "11.0.0.1"}},
+		{"192.168.4.0/22", []string{"192.168.4.1", "192.168.7.255"}, []string{"192.168.8.1", "192.168.3.1", "192.168.40.1"}},
+		{"192.168.1.16/28", []string{"192.168.1.16", "192.168.1.31"}, []string{"192.168.1.32", "192.168.1.160"}},
+		{"192.168.1.7/32", []string{"192.168.1.7"}, []string{"192.168.1.70"}},
+		{"0.0.0.0/0", []string{"1.2.3.4"}, []string{"2001:db8::1"}},
+		{"2001:db8::1/128", []string{"2001:db8::1"}, []string{"2001:db8::10"}},
+	}
+	for _, tt := range tests {
+		_, n, _ := net.ParseCIDR(tt.cidr)
+		re, err := regexp.Compile(netPattern(n))
+		if err != nil {
+			t.Fatalf("netPattern(%v): %v", tt.cidr, err)
+		}
+		for _, ip := range tt.match {
+			if !re.MatchString(ip) {
+				t.Errorf("netPattern(%v) = %v, doesn't match %v", tt.cidr, re, ip)
+			}
+		}
+		for _, ip := range tt.notMatch {
+			if re.MatchString(ip) {
+				t.Errorf("netPattern(%v) = %v, matches %v", tt.cidr, re, ip)
+			}
+		}
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"com":              "com",
+		"WWW.Example.com.": "com.example.www",
+		"a.b.c.d":          "d.c.b.a",
+	}
+	for name, want := range tests {
+		if got := reverseName(name); got != want {
+			t.Errorf("reverseName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBsonPatternFilter(t *testing.T) {
+	// generic patterns ignore case
+	f := bsonPatternFilter("WWW.*.Example.com")
+	re, ok := f["name"].(bson.RegEx)
+	if !ok || re.Options != "i" || re.Pattern != namePattern("www.*.example.com") {
+		t.Errorf("bsonPatternFilter() = %v", f)
+	}
+	// suffixes use reversed names
+	f = bsonPatternFilter("*.Example.COM")
+	or, ok := f["$or"].([]bson.M)
+	if !ok || len(or) != 2 {
+		t.Fatalf("bsonPatternFilter() = %v", f)
+	}
+	rev, ok := or[0]["nameRev"].(bson.RegEx)
+	if !ok || rev.Pattern != `^com\.example\.` {
+		t.Errorf("bsonPatternFilter() nameRev = %v", or[0])
+	}
+	legacy, ok := or[1]["name"].(bson.RegEx)
+	if !ok || legacy.Options != "i" {
+		t.Errorf("bsonPatternFilter() legacy = %v", or[1])
+	}
+}
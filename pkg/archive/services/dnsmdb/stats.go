@@ -0,0 +1,107 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	"github.com/luids-io/archive/pkg/dnsstats"
+)
+
+// Aggregate implements dnsstats.Aggregator interface.
+func (a *Archiver) Aggregate(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	groupBy dnsstats.GroupBy, max int) ([]dnsstats.Item, error) {
+	if !a.started || !a.available() {
+		return nil, dnsutil.ErrUnavailable
+	}
+	field, ok := groupFields[groupBy]
+	if !ok {
+		a.logger.Warnf("%s: aggregate(%v): invalid group by", a.id, groupBy)
+		return nil, dnsutil.ErrBadRequest
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	names, err := a.resolvCollections(filters)
+	if err != nil {
+		a.logger.Warnf("%s: aggregate(%v): %v", a.id, groupBy, err)
+		return nil, a.dbError(err)
+	}
+	groups, err := a.aggregateResolvs(names, createFilter(dnsquery.Filters(filters)), field, max)
+	if err != nil {
+		a.logger.Warnf("%s: aggregate(%v): %v", a.id, groupBy, err)
+		return nil, a.dbError(err)
+	}
+	items := make([]dnsstats.Item, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, dnsstats.Item{
+			Key:       fmt.Sprint(g.Key),
+			Count:     g.Count,
+			NXDomain:  g.NXDomain,
+			FirstSeen: g.FirstSeen,
+			LastSeen:  g.LastSeen,
+		})
+	}
+	return items, nil
+}
+
+var groupFields = map[dnsstats.GroupBy]string{
+	dnsstats.ByName:       "name",
+	dnsstats.ByTLDPlusOne: "tldPlusOne",
+	dnsstats.ByClient:     "clientIP",
+	dnsstats.ByReturnCode: "returnCode",
+}
+
+type mdbGroupData struct {
+	Key       interface{} `bson:"_id"`
+	Count     int64       `bson:"count"`
+	NXDomain  int64       `bson:"nxdomain"`
+	FirstSeen time.Time   `bson:"firstSeen"`
+	LastSeen  time.Time   `bson:"lastSeen"`
+}
+
+// aggregateResolvs groups the resolvs of the collections sorted by count.
+// With several collections, the resolvs of the others are added to the
+// first one using $unionWith (MongoDB 4.4 or later), so the groups are
+// merged, sorted and limited by the server.
+func (a *Archiver) aggregateResolvs(names []string, filter bson.M, field string, max int) ([]mdbGroupData, error) {
+	if len(names) == 0 {
+		return []mdbGroupData{}, nil
+	}
+	pipeline := []bson.M{{"$match": filter}}
+	for _, name := range names[1:] {
+		pipeline = append(pipeline, bson.M{"$unionWith": bson.M{
+			"coll":     a.colName(name),
+			"pipeline": []bson.M{{"$match": filter}},
+		}})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":   "$" + field,
+			"count": bson.M{"$sum": 1},
+			"nxdomain": bson.M{"$sum": bson.M{
+				"$cond": []interface{}{bson.M{"$eq": []interface{}{"$returnCode", dnsstats.NXDomain}}, 1, 0},
+			}},
+			"firstSeen": bson.M{"$min": "$timestamp"},
+			"lastSeen":  bson.M{"$max": "$timestamp"},
+		}},
+		bson.M{"$sort": bson.D{{Name: "count", Value: -1}, {Name: "_id", Value: 1}}},
+	)
+	if max > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": max})
+	}
+	c := a.copyCollection(names[0])
+	defer c.Database.Session.Close()
+	var result []mdbGroupData
+	err := c.Pipe(pipeline).AllowDiskUse().All(&result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,558 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package dnsmongo implements dnsutil.Archive using mongodriver backend.
+// Collections and documents are the same of dnsmdb.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsmongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "dnsmongo"
+
+// Collection names.
+const (
+	ResolvColName = "resolvs"
+)
+
+// Default values.
+const (
+	DefaultDBName         = "luidsdb"
+	DefaultResolvBulkSize = 1024
+	DefaultSyncSeconds    = 5
+	DefaultMaxSize        = 100
+	DefaultWorkers        = 2
+	DefaultQueueDepth     = 4096
+)
+
+// Archiver implements dns archive backend using a mongo database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	client   *mongo.Client
+	database string
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	purger  *mongoutil.Purger
+	//bulks & caches
+	bulkResolvs mongoutil.Writer
+}
+
+// New creates a new storage.
+func New(id string, client *mongo.Client, db string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:       id,
+		opts:     opts,
+		logger:   opts.logger,
+		database: db,
+		client:   client,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger         yalogi.Logger
+	health         mongoutil.Health
+	closeClient    bool
+	resolvBulkSize int
+	syncSecs       int
+	prefix         string
+	retention      time.Duration
+	spoolDir       string
+	spoolSize      int64
+	workers        int
+	queueDepth     int
+	queuePolicy    mongoutil.QueuePolicy
+}
+
+var defaultOptions = options{
+	logger:         yalogi.LogNull,
+	resolvBulkSize: DefaultResolvBulkSize,
+	syncSecs:       DefaultSyncSeconds,
+	workers:        DefaultWorkers,
+	queueDepth:     DefaultQueueDepth,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// CloseClient option allows disconnect mongo client on shutdown.
+func CloseClient(b bool) Option {
+	return func(o *options) {
+		o.closeClient = b
+	}
+}
+
+// SetPrefix option allows set a prefix to collection.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
+// SetSpool option enables a spool in dir where batches are stored when
+// database is unavailable. If maxSize is zero, spool size is unlimited.
+func SetSpool(dir string, maxSize int64) Option {
+	return func(o *options) {
+		o.spoolDir = dir
+		o.spoolSize = maxSize
+	}
+}
+
+// SetWorkers option sets the number of workers that insert documents
+// asynchronously, by default DefaultWorkers. If n is zero, documents are
+// inserted by the callers and bulks are flushed inside their requests, this
+// mode is kept only for compatibility.
+func SetWorkers(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.workers = n
+		}
+	}
+}
+
+// SetQueue option sets the depth and the policy of the insert queues.
+func SetQueue(depth int, policy mongoutil.QueuePolicy) Option {
+	return func(o *options) {
+		if depth > 0 {
+			o.queueDepth = depth
+		}
+		o.queuePolicy = policy
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting mongodb dns archiver", a.id)
+	//create indexes
+	err := a.createIdx()
+	if err != nil {
+		return err
+	}
+	//init bulks & caches
+	a.bulkResolvs = a.newWriter(ResolvColName, a.opts.resolvBulkSize)
+	if a.opts.spoolDir != "" {
+		spool, err := mongoutil.NewSpool(a.opts.spoolDir, a.getCollection(ResolvColName).Name(), a.opts.spoolSize)
+		if err != nil {
+			return err
+		}
+		a.bulkResolvs.SetSpool(spool)
+	}
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
+	a.started = true
+	return nil
+}
+
+// SaveResolv implements dnsutil.Archiver interface.
+func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	if !a.started {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	// with spool, data is stored while database is down
+	if !a.available() && a.opts.spoolDir == "" {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	// create new uuid if not set
+	sid := rd.ID.String()
+	if sid == "" {
+		newid, err := uuid.NewRandom()
+		if err != nil {
+			a.logger.Warnf("%s: saveresolv(): generating new id: %v", a.id, err)
+			return uuid.Nil, dnsutil.ErrInternal
+		}
+		rd.ID = newid
+		sid = rd.ID.String()
+	}
+	// compute fields
+	rd.TLD, _ = publicsuffix.PublicSuffix(rd.Name)
+	rd.TLDPlusOne, _ = publicsuffix.EffectiveTLDPlusOne(rd.Name)
+	// convert to mongo data
+	m := &mdbResolvData{}
+	err := toMData(&rd, m)
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): converting to mongo: %v", a.id, sid, err)
+		return uuid.Nil, dnsutil.ErrBadRequest
+	}
+	// store data
+	m.StorageID = primitive.NewObjectID()
+	err = a.bulkResolvs.Insert(m)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		a.logger.Warnf("%s: saveresolv(%s): %v", a.id, sid, err)
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): inserting in bulk: %v", a.id, sid, err)
+		return uuid.Nil, a.dbError(err)
+	}
+	return rd.ID, nil
+}
+
+// GetResolv implements dnsutil.Finder interface.
+func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
+	if !a.started || !a.available() {
+		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	sid := id.String()
+	if sid == "" {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	//do find
+	var m mdbResolvData
+	err := a.getCollection(ResolvColName).FindOne(ctx, bson.M{"id": sid}).Decode(&m)
+	if err == mongo.ErrNoDocuments {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): %v", a.id, sid, err)
+		return dnsutil.ResolvData{}, false, a.dbError(err)
+	}
+	//encode response
+	var r dnsutil.ResolvData
+	err = fromMData(&m, &r)
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): converting from mongo: %v", a.id, sid, err)
+		return dnsutil.ResolvData{}, false, dnsutil.ErrInternal
+	}
+	return r, true, nil
+}
+
+// ListResolvs implements dnsutil.Finder interface.
+func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	//create filter
+	filter := createFilter(filters)
+	if next != "" {
+		if oid, err := primitive.ObjectIDFromHex(next); err == nil {
+			if rev {
+				filter["_id"] = bson.M{"$lt": oid}
+			} else {
+				filter["_id"] = bson.M{"$gt": oid}
+			}
+		}
+	}
+	//do find
+	fopts := mopts.Find()
+	if rev {
+		fopts.SetSort(bson.D{{Key: "_id", Value: -1}})
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		fopts.SetLimit(int64(max))
+	}
+	//do query
+	var mdbAll []mdbResolvData
+	cur, err := a.getCollection(ResolvColName).Find(ctx, filter, fopts)
+	if err == nil {
+		err = cur.All(ctx, &mdbAll)
+	}
+	if err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//convert data
+	last := ""
+	result := make([]dnsutil.ResolvData, 0, len(mdbAll))
+	for _, m := range mdbAll {
+		var r dnsutil.ResolvData
+		err = fromMData(&m, &r)
+		if err != nil {
+			a.logger.Warnf("%s: listresolvs(): converting from mongo '%s': %v", a.id, m.StorageID.Hex(), err)
+			return nil, "", dnsutil.ErrInternal
+		}
+		result = append(result, r)
+		last = m.StorageID.Hex()
+	}
+	//return
+	if max > 0 && len(result) == max {
+		return result, last, nil
+	}
+	return result, "", nil
+}
+
+// Shutdown closes the conection.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down dns archiver", a.id)
+		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
+		close(a.close)
+		a.closeWriters()
+		if a.opts.closeClient {
+			ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+			defer cancel()
+			a.client.Disconnect(ctx)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.ping()
+}
+
+func (a *Archiver) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	return a.client.Ping(ctx, nil)
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+			a.replayBulks()
+		case <-a.close:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+			return
+		}
+	}
+}
+
+func (a *Archiver) purge(before time.Time) {
+	removed, err := mongodriverutil.Purge(a.getCollection(ResolvColName), "timestamp", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging resolvs: %v", a.id, err)
+		return
+	}
+	metrics.PurgedDocuments.WithLabelValues(a.id, ResolvColName).Add(float64(removed))
+	a.logger.Infof("%s: purged %v resolvs older than %v", a.id, removed, before.Format(time.RFC3339))
+}
+
+func (a *Archiver) replayBulks() {
+	for _, b := range []struct {
+		name string
+		bulk mongoutil.Writer
+	}{{"resolvs", a.bulkResolvs}} {
+		if !b.bulk.Pending() || !a.available() || a.ping() != nil {
+			continue
+		}
+		n, err := b.bulk.Replay()
+		if err != nil {
+			a.logger.Warnf("%s: replaying %s: %v", a.id, b.name, err)
+		}
+		if n > 0 {
+			a.logger.Infof("%s: replayed %v %s from spool", a.id, n, b.name)
+		}
+	}
+}
+
+func (a *Archiver) syncBulks() []error {
+	errs := make([]error, 0, 1)
+	var err error
+	err = a.bulkResolvs.Flush()
+	if err != nil {
+		a.dbError(err)
+		errs = append(errs, fmt.Errorf("sync resolvs: %v", err))
+	}
+	return errs
+}
+
+func (a *Archiver) newWriter(name string, size int) mongoutil.Writer {
+	var w mongoutil.Writer
+	if a.opts.workers == 0 {
+		w = mongodriverutil.NewBulk(a.getCollection(name), size)
+	} else {
+		w = mongodriverutil.NewPipeline(a.getCollection(name),
+			a.opts.queueDepth, a.opts.workers, size, a.opts.queuePolicy,
+			func(err error) {
+				a.dbError(err)
+				a.logger.Warnf("%s: inserting %s: %v", a.id, name, err)
+			})
+	}
+	w.SetMetrics(a.id, name)
+	return w
+}
+
+func (a *Archiver) closeWriters() {
+	for _, w := range []mongoutil.Writer{a.bulkResolvs} {
+		err := w.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+}
+
+func (a *Archiver) getCollection(name string) *mongo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.client.Database(a.database).Collection(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongodriverutil.IsUnavailable(err) {
+		return dnsutil.ErrUnavailable
+	}
+	return dnsutil.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.DNSAPI}
+}
+
+func createFilter(filters []dnsutil.ResolvsFilter) bson.M {
+	switch len(filters) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return bsonFilter(filters[0])
+	}
+	mfilters := make([]bson.M, 0, len(filters))
+	for _, f := range filters {
+		mfilters = append(mfilters, bsonFilter(f))
+	}
+	return bson.M{"$or": mfilters}
+}
+
+func bsonFilter(f dnsutil.ResolvsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() || !f.To.IsZero() {
+		tfilter := bson.M{}
+		if !f.Since.IsZero() {
+			tfilter["$gt"] = f.Since
+		}
+		if !f.To.IsZero() {
+			tfilter["$lt"] = f.To
+		}
+		m["timestamp"] = tfilter
+	}
+	if f.Client != nil {
+		m["clientIP"] = f.Client.String()
+	}
+	if f.Server != nil {
+		m["serverIP"] = f.Server.String()
+	}
+	if f.Name != "" {
+		m["name"] = f.Name
+	}
+	if f.ResolvedIP != nil {
+		m["resolvedIPs"] = f.ResolvedIP.String()
+	}
+	if f.ResolvedCNAME != "" {
+		m["resolvedCNAMEs"] = f.ResolvedCNAME
+	}
+	if f.QID > 0 {
+		m["qid"] = f.QID
+	}
+	if f.ReturnCode > 0 {
+		m["returnCode"] = f.ReturnCode
+	}
+	if f.TLD != "" {
+		m["tld"] = f.TLD
+	}
+	if f.TLDPlusOne != "" {
+		m["tldPlusOne"] = f.TLDPlusOne
+	}
+	return m
+}
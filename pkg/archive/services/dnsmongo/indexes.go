@@ -0,0 +1,39 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+)
+
+func (a *Archiver) createIdx() error {
+	return a.createIdxResolvs()
+}
+
+func (a *Archiver) createIdxResolvs() error {
+	c := a.getCollection(ResolvColName)
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: mopts.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "timestamp", Value: 1}}},
+		{Keys: bson.D{{Key: "serverIP", Value: 1}}},
+		{Keys: bson.D{{Key: "clientIP", Value: 1}}},
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "resolvedIPs", Value: 1}}},
+		{Keys: bson.D{{Key: "resolvedCNAMEs", Value: 1}}},
+		{Keys: bson.D{{Key: "tldPlusOne", Value: 1}}},
+		{Keys: bson.D{{Key: "serverIPRaw", Value: 1}}},
+		{Keys: bson.D{{Key: "clientIPRaw", Value: 1}}},
+		{Keys: bson.D{{Key: "resolvedIPsRaw", Value: 1}}},
+		{Keys: bson.D{{Key: "nameRev", Value: 1}}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	_, err := c.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,161 @@
+package dnsmongo
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+type mdbResolvData struct {
+	StorageID primitive.ObjectID `bson:"_id"`
+	ID        string             `bson:"id"`
+	Timestamp time.Time          `bson:"timestamp"`
+	Duration  time.Duration      `bson:"duration"`
+	ServerIP  string             `bson:"serverIP"`
+	ClientIP  string             `bson:"clientIP"`
+	//query info
+	QID        uint16              `bson:"qid"`
+	Name       string              `bson:"name"`
+	IsIPv6     bool                `bson:"isIPv6"`
+	QueryFlags mdbResolvQueryFlags `bson:"queryFlags"`
+	//response info
+	ReturnCode     int                    `bson:"returnCode"`
+	ResolvedIPs    []string               `bson:"resolvedIPs,omitempty"`
+	ResolvedCNAMEs []string               `bson:"resolvedCNAMEs,omitempty"`
+	ResponseFlags  mdbResolvResponseFlags `bson:"responseFlags"`
+	//calculated info
+	TLD        string `bson:"tld"`
+	TLDPlusOne string `bson:"tldPlusOne"`
+	//indexed info for ranges and suffixes
+	ServerIPRaw    []byte   `bson:"serverIPRaw,omitempty"`
+	ClientIPRaw    []byte   `bson:"clientIPRaw,omitempty"`
+	ResolvedIPsRaw [][]byte `bson:"resolvedIPsRaw,omitempty"`
+	NameRev        string   `bson:"nameRev,omitempty"`
+}
+
+type mdbResolvQueryFlags struct {
+	Do                bool `bson:"do"`
+	AuthenticatedData bool `bson:"authenticatedData"`
+	CheckingDisabled  bool `bson:"checkingDisabled"`
+}
+
+type mdbResolvResponseFlags struct {
+	AuthenticatedData bool `bson:"authenticatedData"`
+}
+
+func toMData(src *dnsutil.ResolvData, dst *mdbResolvData) (err error) {
+	dst.ID = src.ID.String()
+	if dst.ID == "" {
+		err = errors.New("invalid id")
+		return
+	}
+	dst.Timestamp = src.Timestamp
+	dst.Duration = src.Duration
+	dst.ServerIP = src.Server.String()
+	dst.ClientIP = src.Client.String()
+	//query data
+	dst.QID = src.QID
+	dst.Name = src.Name
+	dst.IsIPv6 = src.IsIPv6
+	dst.QueryFlags.Do = src.QueryFlags.Do
+	dst.QueryFlags.AuthenticatedData = src.QueryFlags.AuthenticatedData
+	dst.QueryFlags.CheckingDisabled = src.QueryFlags.CheckingDisabled
+	//response data
+	dst.ReturnCode = src.ReturnCode
+	dst.ResponseFlags.AuthenticatedData = src.ResponseFlags.AuthenticatedData
+	if len(src.ResolvedIPs) > 0 {
+		dst.ResolvedIPs = make([]string, 0, len(src.ResolvedIPs))
+		for _, ip := range src.ResolvedIPs {
+			dst.ResolvedIPs = append(dst.ResolvedIPs, ip.String())
+		}
+	}
+	if len(src.ResolvedCNAMEs) > 0 {
+		dst.ResolvedCNAMEs = make([]string, 0, len(src.ResolvedCNAMEs))
+		for _, cname := range src.ResolvedCNAMEs {
+			dst.ResolvedCNAMEs = append(dst.ResolvedCNAMEs, cname)
+		}
+	}
+	dst.TLD = src.TLD
+	dst.TLDPlusOne = src.TLDPlusOne
+	//indexed data
+	dst.ServerIPRaw = rawIP(src.Server)
+	dst.ClientIPRaw = rawIP(src.Client)
+	if len(src.ResolvedIPs) > 0 {
+		dst.ResolvedIPsRaw = make([][]byte, 0, len(src.ResolvedIPs))
+		for _, ip := range src.ResolvedIPs {
+			if raw := rawIP(ip); raw != nil {
+				dst.ResolvedIPsRaw = append(dst.ResolvedIPsRaw, raw)
+			}
+		}
+	}
+	dst.NameRev = reverseName(src.Name)
+	return
+}
+
+func fromMData(src *mdbResolvData, dst *dnsutil.ResolvData) (err error) {
+	dst.ID, err = uuid.Parse(src.ID)
+	if err != nil {
+		err = errors.New("invalid id")
+		return
+	}
+	dst.Timestamp = src.Timestamp
+	dst.Duration = src.Duration
+	dst.Server = net.ParseIP(src.ServerIP)
+	dst.Client = net.ParseIP(src.ClientIP)
+	//query data
+	dst.QID = src.QID
+	dst.Name = src.Name
+	dst.IsIPv6 = src.IsIPv6
+	dst.QueryFlags.Do = src.QueryFlags.Do
+	dst.QueryFlags.AuthenticatedData = src.QueryFlags.AuthenticatedData
+	dst.QueryFlags.CheckingDisabled = src.QueryFlags.CheckingDisabled
+	//response data
+	dst.ReturnCode = src.ReturnCode
+	dst.ResponseFlags.AuthenticatedData = src.ResponseFlags.AuthenticatedData
+	if len(src.ResolvedIPs) > 0 {
+		dst.ResolvedIPs = make([]net.IP, 0, len(src.ResolvedIPs))
+		for _, ip := range src.ResolvedIPs {
+			dst.ResolvedIPs = append(dst.ResolvedIPs, net.ParseIP(ip))
+		}
+	}
+	if len(src.ResolvedCNAMEs) > 0 {
+		dst.ResolvedCNAMEs = make([]string, 0, len(src.ResolvedCNAMEs))
+		for _, cname := range src.ResolvedCNAMEs {
+			dst.ResolvedCNAMEs = append(dst.ResolvedCNAMEs, cname)
+		}
+	}
+	//calculated data
+	dst.TLD = src.TLD
+	dst.TLDPlusOne = src.TLDPlusOne
+	return
+}
+
+// rawIP returns the ip in 16 bytes form, the same as dnsmdb, so documents
+// can be queried by ranges from both services.
+func rawIP(ip net.IP) []byte {
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return nil
+	}
+	return []byte(ip16)
+}
+
+// reverseName returns the labels of the name in reverse order, the same as
+// dnsmdb, so suffixes of names can be queried as prefixes.
+func reverseName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return ""
+	}
+	labels := strings.Split(name, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnsmongo
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+func TestToMDataIndexed(t *testing.T) {
+	src := dnsutil.ResolvData{
+		ID:          uuid.New(),
+		Server:      net.ParseIP("10.0.0.1"),
+		Client:      net.ParseIP("2001:db8::1"),
+		Name:        "WWW.Example.com.",
+		ResolvedIPs: []net.IP{net.ParseIP("192.0.2.1"), nil},
+	}
+	var m mdbResolvData
+	if err := toMData(&src, &m); err != nil {
+		t.Fatalf("toMData(): %v", err)
+	}
+	if !bytes.Equal(m.ServerIPRaw, net.ParseIP("10.0.0.1").To16()) {
+		t.Errorf("ServerIPRaw = %v", m.ServerIPRaw)
+	}
+	if !bytes.Equal(m.ClientIPRaw, net.ParseIP("2001:db8::1")) {
+		t.Errorf("ClientIPRaw = %v", m.ClientIPRaw)
+	}
+	if len(m.ResolvedIPsRaw) != 1 || len(m.ResolvedIPsRaw[0]) != net.IPv6len {
+		t.Errorf("ResolvedIPsRaw = %v", m.ResolvedIPsRaw)
+	}
+	if m.NameRev != "com.example.www" {
+		t.Errorf("NameRev = %q, want %q", m.NameRev, "com.example.www")
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package dnspg implements dnsutil.Archive and dnsutil.Finder using
+// postgres backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnspg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/sqlutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "dnspg"
+
+// Table names.
+const (
+	ResolvTableName = "resolvs"
+)
+
+// Default values.
+const (
+	DefaultResolvBulkSize = 1024
+	DefaultSyncSeconds    = 5
+	DefaultMaxSize        = 100
+)
+
+// Archiver implements dns archive backend using a postgres database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	db *sql.DB
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	//bulks
+	bulkResolvs *sqlutil.Bulk
+}
+
+// New creates a new storage.
+func New(id string, db *sql.DB, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		db:     db,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger         yalogi.Logger
+	resolvBulkSize int
+	syncSecs       int
+	prefix         string
+}
+
+var defaultOptions = options{
+	logger:         yalogi.LogNull,
+	resolvBulkSize: DefaultResolvBulkSize,
+	syncSecs:       DefaultSyncSeconds,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to tables.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetBulkSize option sets the size of insert bulks.
+func SetBulkSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.resolvBulkSize = n
+		}
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting postgres dns archiver", a.id)
+	//migrate schema
+	err := a.migrate()
+	if err != nil {
+		return err
+	}
+	//init bulks
+	a.bulkResolvs = sqlutil.NewBulk(a.db,
+		a.getTableName(ResolvTableName), resolvColumns,
+		a.opts.resolvBulkSize,
+	)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	a.started = true
+	return nil
+}
+
+// SaveResolv implements dnsutil.Archiver interface.
+func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	if !a.started {
+		return uuid.Nil, dnsutil.ErrUnavailable
+	}
+	// create new uuid if not set
+	if rd.ID == uuid.Nil {
+		newid, err := uuid.NewRandom()
+		if err != nil {
+			a.logger.Warnf("%s: saveresolv(): generating new id: %v", a.id, err)
+			return uuid.Nil, dnsutil.ErrInternal
+		}
+		rd.ID = newid
+	}
+	// compute fields
+	rd.TLD, _ = publicsuffix.PublicSuffix(rd.Name)
+	rd.TLDPlusOne, _ = publicsuffix.EffectiveTLDPlusOne(rd.Name)
+	// store data
+	err := a.bulkResolvs.Insert(toRow(&rd)...)
+	if err != nil {
+		a.logger.Warnf("%s: saveresolv(%s): inserting in bulk: %v", a.id, rd.ID, err)
+		return uuid.Nil, dbError(err)
+	}
+	return rd.ID, nil
+}
+
+// GetResolv implements dnsutil.Finder interface.
+func (a *Archiver) GetResolv(ctx context.Context, id uuid.UUID) (dnsutil.ResolvData, bool, error) {
+	if !a.started {
+		return dnsutil.ResolvData{}, false, dnsutil.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == uuid.Nil {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	//do find
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1",
+		selectColumns, a.getTableName(ResolvTableName))
+	var r dnsutil.ResolvData
+	_, err := fromRow(a.db.QueryRowContext(ctx, query, id.String()), &r)
+	if err == sql.ErrNoRows {
+		return dnsutil.ResolvData{}, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getresolv(%s): %v", a.id, id, err)
+		return dnsutil.ResolvData{}, false, dbError(err)
+	}
+	return r, true, nil
+}
+
+// ListResolvs implements dnsutil.Finder interface.
+func (a *Archiver) ListResolvs(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if !a.started {
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	//create filter
+	where, args := createFilter(filters)
+	if next != "" {
+		sid, err := strconv.ParseInt(next, 10, 64)
+		if err != nil {
+			a.logger.Warnf("%s: listresolvs(): invalid next '%s'", a.id, next)
+			return nil, "", dnsutil.ErrBadRequest
+		}
+		args = append(args, sid)
+		if rev {
+			where = append(where, fmt.Sprintf("sid < $%d", len(args)))
+		} else {
+			where = append(where, fmt.Sprintf("sid > $%d", len(args)))
+		}
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selectColumns, a.getTableName(ResolvTableName))
+	if len(where) > 0 {
+		query = query + " WHERE " + strings.Join(where, " AND ")
+	}
+	if rev {
+		query = query + " ORDER BY sid DESC"
+	} else {
+		query = query + " ORDER BY sid"
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		query = query + fmt.Sprintf(" LIMIT %d", max)
+	}
+	//do query
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", dbError(err)
+	}
+	defer rows.Close()
+	//convert data
+	var last int64
+	result := make([]dnsutil.ResolvData, 0)
+	for rows.Next() {
+		var r dnsutil.ResolvData
+		last, err = fromRow(rows, &r)
+		if err != nil {
+			a.logger.Warnf("%s: listresolvs(): converting from row: %v", a.id, err)
+			return nil, "", dbError(err)
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		a.logger.Warnf("%s: listresolvs(): %v", a.id, err)
+		return nil, "", dbError(err)
+	}
+	//return
+	if max > 0 && len(result) == max {
+		return result, strconv.FormatInt(last, 10), nil
+	}
+	return result, "", nil
+}
+
+// Shutdown flushes pending data, database is closed by the backend.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down dns archiver", a.id)
+		a.started = false
+		close(a.close)
+		errs := a.syncBulks()
+		for _, err := range errs {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.db.Ping()
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+		case <-a.close:
+			return
+		}
+	}
+}
+
+func (a *Archiver) syncBulks() []error {
+	errs := make([]error, 0, 1)
+	var err error
+	err = a.bulkResolvs.Flush()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sync resolvs: %v", err))
+	}
+	return errs
+}
+
+func (a *Archiver) getTableName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+// dbError returns the api error for a database error.
+func dbError(err error) error {
+	if err == sqlutil.ErrBulkFull || sqlutil.IsUnavailable(err) {
+		return dnsutil.ErrUnavailable
+	}
+	return dnsutil.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.DNSAPI}
+}
+
+// createFilter returns the conditions and args of the where clause.
+func createFilter(filters []dnsutil.ResolvsFilter) ([]string, []interface{}) {
+	args := make([]interface{}, 0)
+	switch len(filters) {
+	case 0:
+		return []string{}, args
+	case 1:
+		conds := sqlFilter(filters[0], &args)
+		return conds, args
+	}
+	ors := make([]string, 0, len(filters))
+	for _, f := range filters {
+		conds := sqlFilter(f, &args)
+		if len(conds) == 0 {
+			// matches everything
+			return []string{}, []interface{}{}
+		}
+		ors = append(ors, "("+strings.Join(conds, " AND ")+")")
+	}
+	return []string{"(" + strings.Join(ors, " OR ") + ")"}, args
+}
+
+func sqlFilter(f dnsutil.ResolvsFilter, args *[]interface{}) []string {
+	conds := make([]string, 0)
+	add := func(cond string, v interface{}) {
+		*args = append(*args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(*args)))
+	}
+	if !f.Since.IsZero() {
+		add("timestamp > $%d", f.Since)
+	}
+	if !f.To.IsZero() {
+		add("timestamp < $%d", f.To)
+	}
+	if f.Client != nil {
+		add("client_ip = $%d", f.Client.String())
+	}
+	if f.Server != nil {
+		add("server_ip = $%d", f.Server.String())
+	}
+	if f.Name != "" {
+		add("name = $%d", f.Name)
+	}
+	if f.ResolvedIP != nil {
+		add("resolved_ips @> ARRAY[$%d]::inet[]", f.ResolvedIP.String())
+	}
+	if f.ResolvedCNAME != "" {
+		add("resolved_cnames @> ARRAY[$%d]::text[]", f.ResolvedCNAME)
+	}
+	if f.QID > 0 {
+		add("qid = $%d", f.QID)
+	}
+	if f.ReturnCode > 0 {
+		add("return_code = $%d", f.ReturnCode)
+	}
+	if f.TLD != "" {
+		add("tld = $%d", f.TLD)
+	}
+	if f.TLDPlusOne != "" {
+		add("tld_plus_one = $%d", f.TLDPlusOne)
+	}
+	return conds
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnspg
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+const selectColumns = `sid, id, timestamp, duration, server_ip, client_ip,
+	qid, name, is_ipv6, query_do, query_authenticated_data, query_checking_disabled,
+	return_code, resolved_ips, resolved_cnames, response_authenticated_data,
+	tld, tld_plus_one`
+
+// toRow returns values in the order of resolvColumns.
+func toRow(src *dnsutil.ResolvData) []interface{} {
+	resolvedIPs := make([]string, 0, len(src.ResolvedIPs))
+	for _, ip := range src.ResolvedIPs {
+		resolvedIPs = append(resolvedIPs, ip.String())
+	}
+	resolvedCNAMEs := src.ResolvedCNAMEs
+	if resolvedCNAMEs == nil {
+		resolvedCNAMEs = []string{}
+	}
+	return []interface{}{
+		src.ID.String(), src.Timestamp, int64(src.Duration), ipValue(src.Server), ipValue(src.Client),
+		int64(src.QID), src.Name, src.IsIPv6,
+		src.QueryFlags.Do, src.QueryFlags.AuthenticatedData, src.QueryFlags.CheckingDisabled,
+		int64(src.ReturnCode), pq.Array(resolvedIPs), pq.Array(resolvedCNAMEs),
+		src.ResponseFlags.AuthenticatedData, src.TLD, src.TLDPlusOne,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func fromRow(row scanner, dst *dnsutil.ResolvData) (sid int64, err error) {
+	var id string
+	var duration, qid int64
+	var server, client sql.NullString
+	var resolvedIPs, resolvedCNAMEs pq.StringArray
+	err = row.Scan(&sid, &id, &dst.Timestamp, &duration, &server, &client,
+		&qid, &dst.Name, &dst.IsIPv6,
+		&dst.QueryFlags.Do, &dst.QueryFlags.AuthenticatedData, &dst.QueryFlags.CheckingDisabled,
+		&dst.ReturnCode, &resolvedIPs, &resolvedCNAMEs, &dst.ResponseFlags.AuthenticatedData,
+		&dst.TLD, &dst.TLDPlusOne)
+	if err != nil {
+		return
+	}
+	dst.ID, err = uuid.Parse(id)
+	if err != nil {
+		return
+	}
+	dst.Timestamp = dst.Timestamp.Local()
+	dst.Duration = time.Duration(duration)
+	dst.QID = uint16(qid)
+	if server.Valid {
+		dst.Server = net.ParseIP(server.String)
+	}
+	if client.Valid {
+		dst.Client = net.ParseIP(client.String)
+	}
+	if len(resolvedIPs) > 0 {
+		dst.ResolvedIPs = make([]net.IP, 0, len(resolvedIPs))
+		for _, s := range resolvedIPs {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				err = errors.New("invalid resolved ip")
+				return
+			}
+			dst.ResolvedIPs = append(dst.ResolvedIPs, ip)
+		}
+	}
+	if len(resolvedCNAMEs) > 0 {
+		dst.ResolvedCNAMEs = []string(resolvedCNAMEs)
+	}
+	return
+}
+
+func ipValue(ip net.IP) interface{} {
+	if ip == nil {
+		return nil
+	}
+	return ip.String()
+}
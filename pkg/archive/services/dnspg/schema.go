@@ -0,0 +1,56 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package dnspg
+
+import (
+	"fmt"
+
+	"github.com/luids-io/archive/pkg/sqlutil"
+)
+
+// resolvColumns used in bulk inserts.
+var resolvColumns = []string{
+	"id", "timestamp", "duration", "server_ip", "client_ip",
+	"qid", "name", "is_ipv6", "query_do", "query_authenticated_data", "query_checking_disabled",
+	"return_code", "resolved_ips", "resolved_cnames", "response_authenticated_data",
+	"tld", "tld_plus_one",
+}
+
+// resolvMigrations returns schema migrations, indexes are equivalent to
+// dnsmdb indexes.
+func resolvMigrations(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+			sid BIGSERIAL PRIMARY KEY,
+			id UUID NOT NULL UNIQUE,
+			timestamp TIMESTAMPTZ NOT NULL,
+			duration BIGINT NOT NULL,
+			server_ip INET,
+			client_ip INET,
+			qid INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			is_ipv6 BOOLEAN NOT NULL,
+			query_do BOOLEAN NOT NULL,
+			query_authenticated_data BOOLEAN NOT NULL,
+			query_checking_disabled BOOLEAN NOT NULL,
+			return_code INTEGER NOT NULL,
+			resolved_ips INET[],
+			resolved_cnames TEXT[],
+			response_authenticated_data BOOLEAN NOT NULL,
+			tld TEXT NOT NULL,
+			tld_plus_one TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS %[1]s_timestamp_idx ON %[1]s (timestamp);
+		CREATE INDEX IF NOT EXISTS %[1]s_server_ip_idx ON %[1]s (server_ip);
+		CREATE INDEX IF NOT EXISTS %[1]s_client_ip_idx ON %[1]s (client_ip);
+		CREATE INDEX IF NOT EXISTS %[1]s_name_idx ON %[1]s (name);
+		CREATE INDEX IF NOT EXISTS %[1]s_resolved_ips_idx ON %[1]s USING GIN (resolved_ips);
+		CREATE INDEX IF NOT EXISTS %[1]s_resolved_cnames_idx ON %[1]s USING GIN (resolved_cnames);
+		CREATE INDEX IF NOT EXISTS %[1]s_tld_plus_one_idx ON %[1]s (tld_plus_one);`, table),
+	}
+}
+
+func (a *Archiver) migrate() error {
+	table := a.getTableName(ResolvTableName)
+	return sqlutil.Migrate(a.db, table, resolvMigrations(table))
+}
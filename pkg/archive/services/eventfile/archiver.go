@@ -0,0 +1,221 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package eventfile implements event.Archive using file backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package eventfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/fileutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "eventfile"
+
+// File names.
+const (
+	EventFileName = "events"
+)
+
+// Default values.
+const (
+	DefaultRotatePeriod = 24 * time.Hour
+	DefaultMaxFileSize  = 0
+	DefaultSyncSeconds  = 5
+)
+
+// Archiver implements event archive backend using json lines files.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//files
+	dir string
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	//writers
+	wEvents *fileutil.Writer
+}
+
+// New creates a new storage.
+func New(id string, dir string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		dir:    dir,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger   yalogi.Logger
+	rotate   time.Duration
+	maxSize  int64
+	compress bool
+	syncSecs int
+	prefix   string
+}
+
+var defaultOptions = options{
+	logger:   yalogi.LogNull,
+	rotate:   DefaultRotatePeriod,
+	maxSize:  DefaultMaxFileSize,
+	compress: true,
+	syncSecs: DefaultSyncSeconds,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to file names.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetRotate option sets the period of file rotation.
+func SetRotate(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.rotate = d
+		}
+	}
+}
+
+// SetMaxFileSize option sets the max size in bytes of files.
+func SetMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// Compress option allows compress rotated files.
+func Compress(b bool) Option {
+	return func(o *options) {
+		o.compress = b
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting file event archiver", a.id)
+	//init writers
+	a.wEvents = fileutil.NewWriter(a.dir, a.getFileName(EventFileName),
+		a.opts.rotate, a.opts.maxSize, a.opts.compress)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	a.started = true
+	return nil
+}
+
+// SaveEvent implements event.Archiver interface.
+func (a *Archiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	if !a.started {
+		return "", event.ErrUnavailable
+	}
+	err := a.wEvents.Write(e)
+	if err != nil {
+		a.logger.Warnf("%s: saving event '%s': %v", a.id, e.ID, err)
+		return "", event.ErrInternal
+	}
+	return e.ID, nil
+}
+
+// Shutdown closes the files.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down event archiver", a.id)
+		a.started = false
+		close(a.close)
+		err := a.wEvents.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	_, err := os.Stat(a.dir)
+	return err
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			err := a.wEvents.Flush()
+			if err != nil {
+				a.logger.Warnf("%s: sync events: %v", a.id, err)
+			}
+		case <-a.close:
+			return
+		}
+	}
+}
+
+func (a *Archiver) getFileName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.EventAPI}
+}
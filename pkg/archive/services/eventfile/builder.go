@@ -0,0 +1,100 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package eventfile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/backends/file"
+	"github.com/luids-io/archive/pkg/fileutil"
+	"github.com/luids-io/core/option"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildServiceFn {
+	return func(b *archive.Builder, def archive.ServiceDef) (archive.Service, error) {
+		if def.Backend == "" {
+			return nil, errors.New("'backend' is required")
+		}
+		//get file backend
+		back, ok := b.Backend(def.Backend)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		if back.Class() != file.BackendClass {
+			return nil, fmt.Errorf("'backend' class '%s' not suported in service", back.Class())
+		}
+		// get directory from backend container
+		dir, ok := back.Session().(string)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		// parse options
+		bopt := make([]Option, 0)
+		bopt = append(bopt, SetLogger(b.Logger()))
+		if def.Opts != nil {
+			prefixOpt, ok, err := option.String(def.Opts, "prefix")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				bopt = append(bopt, SetPrefix(prefixOpt))
+			}
+			rotateOpt, ok, err := option.String(def.Opts, "rotate")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				period, err := fileutil.ParsePeriod(rotateOpt)
+				if err != nil {
+					return nil, fmt.Errorf("'rotate': %v", err)
+				}
+				bopt = append(bopt, SetRotate(period))
+			}
+			maxsizeOpt, ok, err := option.Int(def.Opts, "maxsize")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				if maxsizeOpt < 0 {
+					return nil, errors.New("'maxsize' must be positive")
+				}
+				bopt = append(bopt, SetMaxFileSize(int64(maxsizeOpt)*1024*1024))
+			}
+			compressOpt, ok, err := option.Bool(def.Opts, "compress")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				bopt = append(bopt, Compress(compressOpt))
+			}
+		}
+		//create archive service
+		archiver := New(def.ID, dir, bopt...)
+		b.OnStartup(func() error {
+			return archiver.Start()
+		})
+		b.OnShutdown(func() error {
+			archiver.Shutdown()
+			return nil
+		})
+		return archiver, nil
+	}
+}
+
+func init() {
+	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Backends:   []string{file.BackendClass},
+		Implements: []archive.API{archive.EventAPI},
+		Exclusive:  true,
+		Options: []archive.OptionSpec{
+			{Name: "prefix", Type: archive.OptString, Description: "prefix for file names"},
+			{Name: "rotate", Type: archive.OptString, Enum: []string{"hourly", "daily"}, Description: "rotation period"},
+			{Name: "maxsize", Type: archive.OptInt, Description: "max size of files in MB"},
+			{Name: "compress", Type: archive.OptBool, Description: "compress rotated files"},
+		},
+	})
+}
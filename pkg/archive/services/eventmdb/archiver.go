@@ -1,6 +1,7 @@
 // Copyright 2019 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
 
-// Package eventmdb implements event.Archive using mongodb backend.
+// Package eventmdb implements event.Archive and eventfinder.Finder using
+// mongodb backend.
 //
 // This package is a work in progress and makes no API stability promises.
 package eventmdb
@@ -9,12 +10,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
 
 	"github.com/luids-io/api/event"
 	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/mongoutil"
 	"github.com/luids-io/core/yalogi"
 )
 
@@ -28,7 +36,8 @@ const (
 
 // Default values.
 const (
-	DefaultDBName = "luidsdb"
+	DefaultDBName  = "luidsdb"
+	DefaultMaxSize = 100
 )
 
 // Archiver implements event archive backend using a mongo database.
@@ -42,6 +51,7 @@ type Archiver struct {
 	//control
 	mu      sync.Mutex
 	started bool
+	purger  *mongoutil.Purger
 }
 
 // New creates a new storage.
@@ -65,8 +75,10 @@ type Option func(*options)
 
 type options struct {
 	logger       yalogi.Logger
+	health       mongoutil.Health
 	closeSession bool
 	prefix       string
+	retention    time.Duration
 }
 
 var defaultOptions = options{logger: yalogi.LogNull}
@@ -94,6 +106,21 @@ func SetPrefix(s string) Option {
 	}
 }
 
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
 // Start the archiver.
 func (a *Archiver) Start() error {
 	a.mu.Lock()
@@ -109,23 +136,115 @@ func (a *Archiver) Start() error {
 		return err
 	}
 	//init control
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
 	a.started = true
 	return nil
 }
 
 // SaveEvent implements event.Archiver interface.
 func (a *Archiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
-	if !a.started {
+	if !a.started || !a.available() {
 		return "", event.ErrUnavailable
 	}
-	err := a.getCollection(EventColName).Insert(e)
+	c := a.copyCollection(EventColName)
+	defer c.Database.Session.Close()
+	err := c.Insert(e)
 	if err != nil {
 		a.logger.Warnf("%s: saving event '%s': %v", a.id, e.ID, err)
-		return "", event.ErrInternal
+		return "", a.dbError(err)
 	}
+	metrics.Documents.WithLabelValues(a.id, EventColName).Inc()
 	return e.ID, nil
 }
 
+// GetEvent implements eventfinder.Finder interface.
+func (a *Archiver) GetEvent(ctx context.Context, id string) (event.Event, bool, error) {
+	if !a.started || !a.available() {
+		return event.Event{}, false, event.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == "" {
+		return event.Event{}, false, nil
+	}
+	//do find
+	var e event.Event
+	c := a.copyCollection(EventColName)
+	defer c.Database.Session.Close()
+	err := c.FindId(id).One(&e)
+	if err == mgo.ErrNotFound {
+		return event.Event{}, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getevent(%s): %v", a.id, id, err)
+		return event.Event{}, false, a.dbError(err)
+	}
+	return e, true, nil
+}
+
+// ListEvents implements eventfinder.Finder interface.
+func (a *Archiver) ListEvents(ctx context.Context, filters []eventfinder.EventsFilter,
+	rev bool, max int, next string) ([]event.Event, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", event.ErrUnavailable
+	}
+	c := a.copyCollection(EventColName)
+	defer c.Database.Session.Close()
+	//create filter
+	filter, err := createFilter(filters)
+	if err != nil {
+		a.logger.Warnf("%s: listevents(): %v", a.id, err)
+		return nil, "", event.ErrBadRequest
+	}
+	if next != "" {
+		created, id, err := parseNext(next)
+		if err != nil {
+			a.logger.Warnf("%s: listevents(): %v", a.id, err)
+			return nil, "", event.ErrBadRequest
+		}
+		op := "$gt"
+		if rev {
+			op = "$lt"
+		}
+		cursor := bson.M{"$or": []bson.M{
+			{"created": bson.M{op: created}},
+			{"created": created, "_id": bson.M{op: id}},
+		}}
+		if len(filter) > 0 {
+			filter = bson.M{"$and": []bson.M{filter, cursor}}
+		} else {
+			filter = cursor
+		}
+	}
+	//do find
+	q := c.Find(filter)
+	if rev {
+		q = q.Sort("-created", "-_id")
+	} else {
+		q = q.Sort("created", "_id")
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		q = q.Limit(max)
+	}
+	//do query
+	var result []event.Event
+	err = q.All(&result)
+	if err != nil {
+		a.logger.Warnf("%s: listevents(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//return
+	if max > 0 && len(result) == max {
+		last := result[len(result)-1]
+		return result, formatNext(last.Created, last.ID), nil
+	}
+	return result, "", nil
+}
+
 // Shutdown closes the conection.
 func (a *Archiver) Shutdown() {
 	a.mu.Lock()
@@ -134,6 +253,10 @@ func (a *Archiver) Shutdown() {
 	if a.started {
 		a.logger.Infof("%s: shutting down event archiver", a.id)
 		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
 		a.session.Fsync(false)
 		if a.opts.closeSession {
 			a.session.Close()
@@ -151,6 +274,16 @@ func (a *Archiver) Ping() error {
 	return a.session.Ping()
 }
 
+func (a *Archiver) purge(before time.Time) {
+	removed, err := mongoutil.Purge(a.getCollection(EventColName), "created", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging events: %v", a.id, err)
+		return
+	}
+	metrics.PurgedDocuments.WithLabelValues(a.id, EventColName).Add(float64(removed))
+	a.logger.Infof("%s: purged %v events older than %v", a.id, removed, before.Format(time.RFC3339))
+}
+
 func (a *Archiver) getDatabase() *mgo.Database {
 	return a.session.DB(a.database)
 }
@@ -162,6 +295,32 @@ func (a *Archiver) getCollection(name string) *mgo.Collection {
 	return a.session.DB(a.database).C(name)
 }
 
+// copyCollection returns a collection using a copy of the session, the
+// session must be closed after use.
+func (a *Archiver) copyCollection(name string) *mgo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.session.Copy().DB(a.database).C(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongoutil.IsUnavailable(err) {
+		return event.ErrUnavailable
+	}
+	return event.ErrInternal
+}
+
 // ID implements archive.Service interface.
 func (a *Archiver) ID() string {
 	return a.id
@@ -176,3 +335,84 @@ func (a *Archiver) Class() string {
 func (a *Archiver) Implements() []archive.API {
 	return []archive.API{archive.EventAPI}
 }
+
+// createFilter returns the query of the filters. MongoDB allows only one
+// $text expression by query, so the text search is moved out of $or and it
+// must be the same in all filters.
+func createFilter(filters []eventfinder.EventsFilter) (bson.M, error) {
+	switch len(filters) {
+	case 0:
+		return bson.M{}, nil
+	case 1:
+		return bsonFilter(filters[0]), nil
+	}
+	text := filters[0].Text
+	mfilters := make([]bson.M, 0, len(filters))
+	for _, f := range filters {
+		if f.Text != text {
+			return nil, errors.New("text search must be the same in all filters")
+		}
+		f.Text = ""
+		mfilters = append(mfilters, bsonFilter(f))
+	}
+	m := bson.M{"$or": mfilters}
+	if text != "" {
+		m["$text"] = bson.M{"$search": text}
+	}
+	return m, nil
+}
+
+func bsonFilter(f eventfinder.EventsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() || !f.To.IsZero() {
+		tfilter := bson.M{}
+		if !f.Since.IsZero() {
+			tfilter["$gt"] = f.Since
+		}
+		if !f.To.IsZero() {
+			tfilter["$lt"] = f.To
+		}
+		m["created"] = tfilter
+	}
+	if f.Code > 0 {
+		m["code"] = f.Code
+	}
+	if f.MinLevel > event.Info {
+		m["level"] = bson.M{"$gte": f.MinLevel}
+	}
+	if f.Source.Hostname != "" {
+		m["source.hostname"] = f.Source.Hostname
+	}
+	if f.Source.Program != "" {
+		m["source.program"] = f.Source.Program
+	}
+	if f.Source.Instance != "" {
+		m["source.instance"] = f.Source.Instance
+	}
+	if f.Source.PID > 0 {
+		m["source.pid"] = f.Source.PID
+	}
+	if f.Text != "" {
+		m["$text"] = bson.M{"$search": f.Text}
+	}
+	return m
+}
+
+// next token is composed by the creation time in milliseconds (precision
+// used by mongodb) and the event id.
+func formatNext(created time.Time, id string) string {
+	ms := created.UnixNano() / int64(time.Millisecond)
+	return fmt.Sprintf("%d_%s", ms, id)
+}
+
+func parseNext(next string) (time.Time, string, error) {
+	fields := strings.SplitN(next, "_", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s'", next)
+	}
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s': %v", next, err)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), fields[1], nil
+}
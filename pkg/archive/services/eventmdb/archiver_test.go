@@ -0,0 +1,45 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package eventmdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/eventfinder"
+)
+
+func TestCreateFilter(t *testing.T) {
+	tests := []struct {
+		filters []eventfinder.EventsFilter
+		want    bson.M
+		wantErr bool
+	}{
+		{nil, bson.M{}, false},
+		{[]eventfinder.EventsFilter{{Code: 1, Text: "foo"}},
+			bson.M{"code": event.Code(1), "$text": bson.M{"$search": "foo"}}, false},
+		{[]eventfinder.EventsFilter{{Code: 1}, {Code: 2}},
+			bson.M{"$or": []bson.M{{"code": event.Code(1)}, {"code": event.Code(2)}}}, false},
+		// the same text search is moved out of $or
+		{[]eventfinder.EventsFilter{{Code: 1, Text: "foo"}, {Code: 2, Text: "foo"}},
+			bson.M{
+				"$or":   []bson.M{{"code": event.Code(1)}, {"code": event.Code(2)}},
+				"$text": bson.M{"$search": "foo"},
+			}, false},
+		{[]eventfinder.EventsFilter{{Code: 1, Text: "foo"}, {Code: 2, Text: "bar"}}, nil, true},
+		{[]eventfinder.EventsFilter{{Code: 1, Text: "foo"}, {Code: 2}}, nil, true},
+	}
+	for i, tt := range tests {
+		got, err := createFilter(tt.filters)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %v: err = %v, wantErr %v", i, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %v: createFilter() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
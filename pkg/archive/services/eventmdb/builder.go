@@ -10,6 +10,7 @@ import (
 
 	"github.com/luids-io/archive/pkg/archive"
 	"github.com/luids-io/archive/pkg/archive/backends/mongodb"
+	"github.com/luids-io/archive/pkg/mongoutil"
 	"github.com/luids-io/core/option"
 )
 
@@ -35,6 +36,9 @@ func Builder() archive.BuildServiceFn {
 		// parse options
 		bopt := make([]Option, 0)
 		bopt = append(bopt, SetLogger(b.Logger()))
+		if h, ok := back.(mongoutil.Health); ok {
+			bopt = append(bopt, SetHealth(h))
+		}
 		//by default, it uses DefaultDBName
 		dbname := DefaultDBName
 		if def.Opts != nil {
@@ -53,6 +57,17 @@ func Builder() archive.BuildServiceFn {
 			if ok {
 				bopt = append(bopt, SetPrefix(prefixOpt))
 			}
+			retentionOpt, ok, err := option.String(def.Opts, "retention")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				retention, err := archive.ParseRetention(retentionOpt)
+				if err != nil {
+					return nil, fmt.Errorf("'retention': %v", err)
+				}
+				bopt = append(bopt, SetRetention(retention))
+			}
 		}
 		//create archive service
 		archiver := New(def.ID, session, dbname, bopt...)
@@ -69,4 +84,13 @@ func Builder() archive.BuildServiceFn {
 
 func init() {
 	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Backends:   []string{mongodb.BackendClass},
+		Implements: []archive.API{archive.EventAPI},
+		Options: []archive.OptionSpec{
+			{Name: "dbname", Type: archive.OptString, Description: "database name"},
+			{Name: "prefix", Type: archive.OptString, Description: "prefix for collection names"},
+			{Name: "retention", Type: archive.OptString, Description: "purge documents older than (30d, 2w, 12h)"},
+		},
+	})
 }
@@ -12,6 +12,7 @@ func (a *Archiver) createIdxEvents() error {
 	c := a.getCollection(EventColName)
 	indexes := []mgo.Index{
 		{Key: []string{"created"}},
+		{Key: []string{"created", "_id"}},
 		{Key: []string{"code"}},
 		{Key: []string{"level"}},
 		{Key: []string{"$text:description"}},
@@ -0,0 +1,407 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package eventmongo implements event.Archive and eventfinder.Finder using
+// mongodriver backend. Collections and documents are the same of eventmdb.
+//
+// This package is a work in progress and makes no API stability promises.
+package eventmongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "eventmongo"
+
+// Collection names.
+const (
+	EventColName = "events"
+)
+
+// Default values.
+const (
+	DefaultDBName  = "luidsdb"
+	DefaultMaxSize = 100
+)
+
+// Archiver implements event archive backend using a mongo database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	client   *mongo.Client
+	database string
+	//control
+	mu      sync.Mutex
+	started bool
+	purger  *mongoutil.Purger
+}
+
+// New creates a new storage.
+func New(id string, client *mongo.Client, db string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:       id,
+		opts:     opts,
+		logger:   opts.logger,
+		database: db,
+		client:   client,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger      yalogi.Logger
+	health      mongoutil.Health
+	closeClient bool
+	prefix      string
+	retention   time.Duration
+}
+
+var defaultOptions = options{logger: yalogi.LogNull}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// CloseClient option allows disconnect mongo client on shutdown.
+func CloseClient(b bool) Option {
+	return func(o *options) {
+		o.closeClient = b
+	}
+}
+
+// SetPrefix option allows set a prefix to collection.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting mongodb event archiver", a.id)
+	//create indexes
+	err := a.createIdx()
+	if err != nil {
+		return err
+	}
+	//init control
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
+	a.started = true
+	return nil
+}
+
+// SaveEvent implements event.Archiver interface.
+func (a *Archiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	if !a.started || !a.available() {
+		return "", event.ErrUnavailable
+	}
+	_, err := a.getCollection(EventColName).InsertOne(ctx, e)
+	if err != nil {
+		a.logger.Warnf("%s: saving event '%s': %v", a.id, e.ID, err)
+		return "", a.dbError(err)
+	}
+	metrics.Documents.WithLabelValues(a.id, EventColName).Inc()
+	return e.ID, nil
+}
+
+// GetEvent implements eventfinder.Finder interface.
+func (a *Archiver) GetEvent(ctx context.Context, id string) (event.Event, bool, error) {
+	if !a.started || !a.available() {
+		return event.Event{}, false, event.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == "" {
+		return event.Event{}, false, nil
+	}
+	//do find
+	var e event.Event
+	err := a.getCollection(EventColName).FindOne(ctx, bson.M{"_id": id}).Decode(&e)
+	if err == mongo.ErrNoDocuments {
+		return event.Event{}, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getevent(%s): %v", a.id, id, err)
+		return event.Event{}, false, a.dbError(err)
+	}
+	return e, true, nil
+}
+
+// ListEvents implements eventfinder.Finder interface.
+func (a *Archiver) ListEvents(ctx context.Context, filters []eventfinder.EventsFilter,
+	rev bool, max int, next string) ([]event.Event, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", event.ErrUnavailable
+	}
+	//create filter
+	filter, err := createFilter(filters)
+	if err != nil {
+		a.logger.Warnf("%s: listevents(): %v", a.id, err)
+		return nil, "", event.ErrBadRequest
+	}
+	if next != "" {
+		created, id, err := parseNext(next)
+		if err != nil {
+			a.logger.Warnf("%s: listevents(): %v", a.id, err)
+			return nil, "", event.ErrBadRequest
+		}
+		op := "$gt"
+		if rev {
+			op = "$lt"
+		}
+		cursor := bson.M{"$or": []bson.M{
+			{"created": bson.M{op: created}},
+			{"created": created, "_id": bson.M{op: id}},
+		}}
+		if len(filter) > 0 {
+			filter = bson.M{"$and": []bson.M{filter, cursor}}
+		} else {
+			filter = cursor
+		}
+	}
+	//do find
+	fopts := mopts.Find()
+	if rev {
+		fopts.SetSort(bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}})
+	} else {
+		fopts.SetSort(bson.D{{Key: "created", Value: 1}, {Key: "_id", Value: 1}})
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		fopts.SetLimit(int64(max))
+	}
+	//do query
+	var result []event.Event
+	cur, err := a.getCollection(EventColName).Find(ctx, filter, fopts)
+	if err == nil {
+		err = cur.All(ctx, &result)
+	}
+	if err != nil {
+		a.logger.Warnf("%s: listevents(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//return
+	if max > 0 && len(result) == max {
+		last := result[len(result)-1]
+		return result, formatNext(last.Created, last.ID), nil
+	}
+	return result, "", nil
+}
+
+// Shutdown closes the conection.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down event archiver", a.id)
+		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
+		if a.opts.closeClient {
+			ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+			defer cancel()
+			a.client.Disconnect(ctx)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	return a.client.Ping(ctx, nil)
+}
+
+func (a *Archiver) purge(before time.Time) {
+	removed, err := mongodriverutil.Purge(a.getCollection(EventColName), "created", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging events: %v", a.id, err)
+		return
+	}
+	metrics.PurgedDocuments.WithLabelValues(a.id, EventColName).Add(float64(removed))
+	a.logger.Infof("%s: purged %v events older than %v", a.id, removed, before.Format(time.RFC3339))
+}
+
+func (a *Archiver) getCollection(name string) *mongo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.client.Database(a.database).Collection(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongodriverutil.IsUnavailable(err) {
+		return event.ErrUnavailable
+	}
+	return event.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.EventAPI}
+}
+
+// createFilter returns the query of the filters. MongoDB allows only one
+// $text expression by query, so the text search is moved out of $or and it
+// must be the same in all filters.
+func createFilter(filters []eventfinder.EventsFilter) (bson.M, error) {
+	switch len(filters) {
+	case 0:
+		return bson.M{}, nil
+	case 1:
+		return bsonFilter(filters[0]), nil
+	}
+	text := filters[0].Text
+	mfilters := make([]bson.M, 0, len(filters))
+	for _, f := range filters {
+		if f.Text != text {
+			return nil, errors.New("text search must be the same in all filters")
+		}
+		f.Text = ""
+		mfilters = append(mfilters, bsonFilter(f))
+	}
+	m := bson.M{"$or": mfilters}
+	if text != "" {
+		m["$text"] = bson.M{"$search": text}
+	}
+	return m, nil
+}
+
+func bsonFilter(f eventfinder.EventsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() || !f.To.IsZero() {
+		tfilter := bson.M{}
+		if !f.Since.IsZero() {
+			tfilter["$gt"] = f.Since
+		}
+		if !f.To.IsZero() {
+			tfilter["$lt"] = f.To
+		}
+		m["created"] = tfilter
+	}
+	if f.Code > 0 {
+		m["code"] = f.Code
+	}
+	if f.MinLevel > event.Info {
+		m["level"] = bson.M{"$gte": f.MinLevel}
+	}
+	if f.Source.Hostname != "" {
+		m["source.hostname"] = f.Source.Hostname
+	}
+	if f.Source.Program != "" {
+		m["source.program"] = f.Source.Program
+	}
+	if f.Source.Instance != "" {
+		m["source.instance"] = f.Source.Instance
+	}
+	if f.Source.PID > 0 {
+		m["source.pid"] = f.Source.PID
+	}
+	if f.Text != "" {
+		m["$text"] = bson.M{"$search": f.Text}
+	}
+	return m
+}
+
+// next token is composed by the creation time in milliseconds (precision
+// used by mongodb) and the event id. It is compatible with eventmdb.
+func formatNext(created time.Time, id string) string {
+	ms := created.UnixNano() / int64(time.Millisecond)
+	return fmt.Sprintf("%d_%s", ms, id)
+}
+
+func parseNext(next string) (time.Time, string, error) {
+	fields := strings.SplitN(next, "_", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s'", next)
+	}
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s': %v", next, err)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), fields[1], nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package eventmongo
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/backends/mongodriver"
+	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/core/option"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildServiceFn {
+	return func(b *archive.Builder, def archive.ServiceDef) (archive.Service, error) {
+		if def.Backend == "" {
+			return nil, errors.New("'backend' is required")
+		}
+		//get mongodriver backend
+		back, ok := b.Backend(def.Backend)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		if back.Class() != mongodriver.BackendClass {
+			return nil, fmt.Errorf("'backend' class '%s' not suported in service", back.Class())
+		}
+		// get client from backend container
+		client, ok := back.Session().(*mongo.Client)
+		if !ok {
+			return nil, errors.New("'backend' not found")
+		}
+		// parse options
+		bopt := make([]Option, 0)
+		bopt = append(bopt, SetLogger(b.Logger()))
+		if h, ok := back.(mongoutil.Health); ok {
+			bopt = append(bopt, SetHealth(h))
+		}
+		//by default, it uses DefaultDBName
+		dbname := DefaultDBName
+		if def.Opts != nil {
+			var err error
+			dbnameOpt, ok, err := option.String(def.Opts, "dbname")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				dbname = dbnameOpt
+			}
+			prefixOpt, ok, err := option.String(def.Opts, "prefix")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				bopt = append(bopt, SetPrefix(prefixOpt))
+			}
+			retentionOpt, ok, err := option.String(def.Opts, "retention")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				retention, err := archive.ParseRetention(retentionOpt)
+				if err != nil {
+					return nil, fmt.Errorf("'retention': %v", err)
+				}
+				bopt = append(bopt, SetRetention(retention))
+			}
+		}
+		//create archive service
+		archiver := New(def.ID, client, dbname, bopt...)
+		b.OnStartup(func() error {
+			return archiver.Start()
+		})
+		b.OnShutdown(func() error {
+			archiver.Shutdown()
+			return nil
+		})
+		return archiver, nil
+	}
+}
+
+func init() {
+	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Backends:   []string{mongodriver.BackendClass},
+		Implements: []archive.API{archive.EventAPI},
+		Options: []archive.OptionSpec{
+			{Name: "dbname", Type: archive.OptString, Description: "database name"},
+			{Name: "prefix", Type: archive.OptString, Description: "prefix for collection names"},
+			{Name: "retention", Type: archive.OptString, Description: "purge documents older than (30d, 2w, 12h)"},
+		},
+	})
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package eventmongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+)
+
+func (a *Archiver) createIdx() error {
+	return a.createIdxEvents()
+}
+
+// index names are the same of the ones created by mgo.
+func (a *Archiver) createIdxEvents() error {
+	c := a.getCollection(EventColName)
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created", Value: 1}}},
+		{Keys: bson.D{{Key: "created", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "code", Value: 1}}},
+		{Keys: bson.D{{Key: "level", Value: 1}}},
+		{Keys: bson.D{{Key: "description", Value: "text"}}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	_, err := c.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package eventpg implements event.Archive using postgres backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package eventpg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lib/pq"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/sqlutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "eventpg"
+
+// Table names.
+const (
+	EventTableName = "events"
+)
+
+// Archiver implements event archive backend using a postgres database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	db *sql.DB
+	//control
+	mu      sync.Mutex
+	started bool
+}
+
+// New creates a new storage.
+func New(id string, db *sql.DB, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		db:     db,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger yalogi.Logger
+	prefix string
+}
+
+var defaultOptions = options{
+	logger: yalogi.LogNull,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to tables.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting postgres event archiver", a.id)
+	//migrate schema
+	table := a.getTableName(EventTableName)
+	err := sqlutil.Migrate(a.db, table, eventMigrations(table))
+	if err != nil {
+		return err
+	}
+	a.started = true
+	return nil
+}
+
+// SaveEvent implements event.Archiver interface.
+func (a *Archiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	if !a.started {
+		return "", event.ErrUnavailable
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		a.logger.Warnf("%s: saving event '%s': encoding data: %v", a.id, e.ID, err)
+		return "", event.ErrBadRequest
+	}
+	processors, err := json.Marshal(e.Processors)
+	if err != nil {
+		a.logger.Warnf("%s: saving event '%s': encoding processors: %v", a.id, e.ID, err)
+		return "", event.ErrBadRequest
+	}
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		a.getTableName(EventTableName), eventColumns)
+	_, err = a.db.ExecContext(ctx, query,
+		e.ID, int(e.Type), int(e.Code), e.Codename, int(e.Level),
+		e.Created, e.Received, e.Source.Hostname, e.Source.Program, e.Source.Instance, e.Source.PID,
+		e.Duplicates, e.Description, string(data), string(processors), pq.Array(tags))
+	if err != nil {
+		a.logger.Warnf("%s: saving event '%s': %v", a.id, e.ID, err)
+		return "", dbError(err)
+	}
+	return e.ID, nil
+}
+
+// Shutdown the archiver, database is closed by the backend.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down event archiver", a.id)
+		a.started = false
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.db.Ping()
+}
+
+func (a *Archiver) getTableName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+// dbError returns the api error for a database error.
+func dbError(err error) error {
+	if sqlutil.IsUnavailable(err) {
+		return event.ErrUnavailable
+	}
+	return event.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.EventAPI}
+}
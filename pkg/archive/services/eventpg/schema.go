@@ -0,0 +1,37 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package eventpg
+
+import "fmt"
+
+const eventColumns = `id, type, code, codename, level, created, received,
+	source_hostname, source_program, source_instance, source_pid,
+	duplicates, description, data, processors, tags`
+
+// eventMigrations returns schema migrations, indexes are equivalent to
+// eventmdb indexes.
+func eventMigrations(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+			id TEXT PRIMARY KEY,
+			type INTEGER NOT NULL,
+			code INTEGER NOT NULL,
+			codename TEXT NOT NULL,
+			level INTEGER NOT NULL,
+			created TIMESTAMPTZ NOT NULL,
+			received TIMESTAMPTZ NOT NULL,
+			source_hostname TEXT NOT NULL,
+			source_program TEXT NOT NULL,
+			source_instance TEXT NOT NULL,
+			source_pid INTEGER NOT NULL,
+			duplicates INTEGER NOT NULL,
+			description TEXT NOT NULL,
+			data JSONB,
+			processors JSONB,
+			tags TEXT[]
+		);
+		CREATE INDEX IF NOT EXISTS %[1]s_created_idx ON %[1]s (created);
+		CREATE INDEX IF NOT EXISTS %[1]s_code_idx ON %[1]s (code);
+		CREATE INDEX IF NOT EXISTS %[1]s_level_idx ON %[1]s (level);`, table),
+	}
+}
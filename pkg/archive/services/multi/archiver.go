@@ -0,0 +1,307 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package multi implements a composite service that archives data in
+// several services at once.
+//
+// This package is a work in progress and makes no API stability promises.
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "multi"
+
+// Policy defines the behaviour on partial failures.
+type Policy int
+
+// Policy values.
+const (
+	// AllMustSucceed returns an error if any of the services fails.
+	AllMustSucceed Policy = iota
+	// BestEffort returns an error only if all the services fail.
+	BestEffort
+	// PrimarySecondary returns an error only if the first service fails.
+	PrimarySecondary
+)
+
+func (p Policy) String() string {
+	switch p {
+	case AllMustSucceed:
+		return "all"
+	case BestEffort:
+		return "besteffort"
+	case PrimarySecondary:
+		return "primary"
+	}
+	return fmt.Sprintf("unknown(%d)", p)
+}
+
+// ParsePolicy returns policy from string.
+func ParsePolicy(s string) (Policy, error) {
+	switch s {
+	case "all":
+		return AllMustSucceed, nil
+	case "besteffort":
+		return BestEffort, nil
+	case "primary":
+		return PrimarySecondary, nil
+	}
+	return AllMustSucceed, fmt.Errorf("invalid policy '%s'", s)
+}
+
+// Archiver implements a fan-out archive service. The first service is
+// the primary.
+type Archiver struct {
+	id       string
+	opts     options
+	logger   yalogi.Logger
+	services []archive.Service
+	apis     []archive.API
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger yalogi.Logger
+	policy Policy
+}
+
+var defaultOptions = options{
+	logger: yalogi.LogNull,
+	policy: AllMustSucceed,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPolicy option sets the policy on partial failures.
+func SetPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// New creates a new composite service. It implements the apis implemented
+// by all the services.
+func New(id string, services []archive.Service, opt ...Option) (*Archiver, error) {
+	if len(services) == 0 {
+		return nil, errors.New("services are required")
+	}
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	a := &Archiver{
+		id:       id,
+		opts:     opts,
+		logger:   opts.logger,
+		services: services,
+		apis:     commonAPIs(services),
+	}
+	if len(a.apis) == 0 {
+		return nil, errors.New("services don't implement a common api")
+	}
+	//check interfaces
+	for _, svc := range services {
+		for _, api := range a.apis {
+			var ok bool
+			switch api {
+			case archive.DNSAPI:
+				_, ok = svc.(dnsutil.Archiver)
+			case archive.EventAPI:
+				_, ok = svc.(event.Archiver)
+			case archive.TLSAPI:
+				_, ok = svc.(tlsutil.Archiver)
+			}
+			if !ok {
+				return nil, fmt.Errorf("service '%s' doesn't implement archiver", svc.ID())
+			}
+		}
+	}
+	return a, nil
+}
+
+// SaveResolv implements dnsutil.Archiver interface.
+func (a *Archiver) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	if !a.implements(archive.DNSAPI) {
+		return uuid.Nil, dnsutil.ErrNotSupported
+	}
+	// all services must store the same id
+	if rd.ID == uuid.Nil {
+		newid, err := uuid.NewRandom()
+		if err != nil {
+			a.logger.Warnf("%s: saveresolv(): generating new id: %v", a.id, err)
+			return uuid.Nil, dnsutil.ErrInternal
+		}
+		rd.ID = newid
+	}
+	err := a.fanout("saveresolv", func(svc archive.Service) error {
+		_, err := svc.(dnsutil.Archiver).SaveResolv(ctx, rd)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return rd.ID, nil
+}
+
+// SaveEvent implements event.Archiver interface.
+func (a *Archiver) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	if !a.implements(archive.EventAPI) {
+		return "", event.ErrNotSupported
+	}
+	err := a.fanout("saveevent", func(svc archive.Service) error {
+		_, err := svc.(event.Archiver).SaveEvent(ctx, e)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return e.ID, nil
+}
+
+// SaveConnection implements tlsutil.Archiver interface.
+func (a *Archiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	if !a.implements(archive.TLSAPI) {
+		return "", tlsutil.ErrNotSupported
+	}
+	err := a.fanout("saveconnection", func(svc archive.Service) error {
+		_, err := svc.(tlsutil.Archiver).SaveConnection(ctx, cn)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return cn.ID, nil
+}
+
+// SaveCertificate implements tlsutil.Archiver interface. Services generate
+// the ids of certificates, so the id returned is the one of the primary
+// service and it fails if the primary fails, whatever the policy. Errors of
+// the other services are returned only with AllMustSucceed policy.
+func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	if !a.implements(archive.TLSAPI) {
+		return "", tlsutil.ErrNotSupported
+	}
+	primary := a.services[0]
+	id, err := primary.(tlsutil.Archiver).SaveCertificate(ctx, cert)
+	if err != nil {
+		a.logger.Warnf("%s: savecertificate(): service '%s': %v", a.id, primary.ID(), err)
+		return "", err
+	}
+	var first error
+	for _, svc := range a.services[1:] {
+		_, err := svc.(tlsutil.Archiver).SaveCertificate(ctx, cert)
+		if err != nil {
+			a.logger.Warnf("%s: savecertificate(): service '%s': %v", a.id, svc.ID(), err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if first != nil && a.opts.policy == AllMustSucceed {
+		return "", first
+	}
+	return id, nil
+}
+
+// StoreRecord implements tlsutil.Archiver interface.
+func (a *Archiver) StoreRecord(r *tlsutil.RecordData) error {
+	if !a.implements(archive.TLSAPI) {
+		return tlsutil.ErrNotSupported
+	}
+	return a.fanout("storerecord", func(svc archive.Service) error {
+		return svc.(tlsutil.Archiver).StoreRecord(r)
+	})
+}
+
+// fanout calls fn for each service and returns the error according to
+// the policy.
+func (a *Archiver) fanout(op string, fn func(archive.Service) error) error {
+	var first error
+	success := 0
+	for i, svc := range a.services {
+		err := fn(svc)
+		if err != nil {
+			a.logger.Warnf("%s: %s(): service '%s': %v", a.id, op, svc.ID(), err)
+			if first == nil {
+				first = err
+			}
+			if i == 0 && a.opts.policy == PrimarySecondary {
+				return err
+			}
+			continue
+		}
+		success++
+	}
+	switch a.opts.policy {
+	case BestEffort:
+		if success > 0 {
+			return nil
+		}
+		return first
+	case PrimarySecondary:
+		return nil
+	}
+	return first
+}
+
+func (a *Archiver) implements(api archive.API) bool {
+	for _, i := range a.apis {
+		if i == api {
+			return true
+		}
+	}
+	return false
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return a.apis
+}
+
+func commonAPIs(services []archive.Service) []archive.API {
+	apis := services[0].Implements()
+	for _, svc := range services[1:] {
+		common := make([]archive.API, 0, len(apis))
+		for _, api := range apis {
+			for _, other := range svc.Implements() {
+				if api == other {
+					common = append(common, api)
+					break
+				}
+			}
+		}
+		apis = common
+	}
+	return apis
+}
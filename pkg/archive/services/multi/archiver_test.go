@@ -0,0 +1,189 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package multi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+)
+
+// testService is a child service that fails with err.
+type testService struct {
+	id     string
+	apis   []archive.API
+	err    error
+	certID string
+	calls  int
+	saved  uuid.UUID
+}
+
+func (s *testService) ID() string                { return s.id }
+func (s *testService) Class() string             { return "test" }
+func (s *testService) Implements() []archive.API { return s.apis }
+
+func (s *testService) SaveResolv(ctx context.Context, rd dnsutil.ResolvData) (uuid.UUID, error) {
+	s.calls++
+	if s.err != nil {
+		return uuid.Nil, s.err
+	}
+	s.saved = rd.ID
+	return rd.ID, nil
+}
+
+func (s *testService) SaveEvent(ctx context.Context, e event.Event) (string, error) {
+	s.calls++
+	return e.ID, s.err
+}
+
+func (s *testService) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	s.calls++
+	return cn.ID, s.err
+}
+
+func (s *testService) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.certID, nil
+}
+
+func (s *testService) StoreRecord(r *tlsutil.RecordData) error {
+	s.calls++
+	return s.err
+}
+
+var (
+	err1 = errors.New("error 1")
+	err2 = errors.New("error 2")
+	err3 = errors.New("error 3")
+)
+
+var allAPIs = []archive.API{archive.DNSAPI, archive.EventAPI, archive.TLSAPI}
+
+func newTestArchiver(t *testing.T, policy Policy, errs ...error) (*Archiver, []*testService) {
+	t.Helper()
+	children := make([]*testService, 0, len(errs))
+	services := make([]archive.Service, 0, len(errs))
+	for i, err := range errs {
+		svc := &testService{id: string(rune('a' + i)), apis: allAPIs, err: err, certID: "cert-" + string(rune('a'+i))}
+		children = append(children, svc)
+		services = append(services, svc)
+	}
+	a, err := New("multi", services, SetPolicy(policy))
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	return a, children
+}
+
+func TestFanoutPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		errs   []error
+		want   error
+		calls  []int
+	}{
+		{"all ok", AllMustSucceed, []error{nil, nil, nil}, nil, []int{1, 1, 1}},
+		{"all first error", AllMustSucceed, []error{nil, err2, err3}, err2, []int{1, 1, 1}},
+		{"all primary error", AllMustSucceed, []error{err1, nil, nil}, err1, []int{1, 1, 1}},
+		{"besteffort one ok", BestEffort, []error{err1, nil, err3}, nil, []int{1, 1, 1}},
+		{"besteffort all fail", BestEffort, []error{err1, err2, err3}, err1, []int{1, 1, 1}},
+		{"primary ok", PrimarySecondary, []error{nil, err2, err3}, nil, []int{1, 1, 1}},
+		{"primary fails", PrimarySecondary, []error{err1, nil, nil}, err1, []int{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		a, children := newTestArchiver(t, tt.policy, tt.errs...)
+		id, err := a.SaveResolv(context.Background(), dnsutil.ResolvData{Name: "www.example.com"})
+		if err != tt.want {
+			t.Errorf("%s: SaveResolv() err = %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && id == uuid.Nil {
+			t.Errorf("%s: SaveResolv() returned nil id", tt.name)
+		}
+		for i, child := range children {
+			if child.calls != tt.calls[i] {
+				t.Errorf("%s: service %v calls = %v, want %v", tt.name, i, child.calls, tt.calls[i])
+			}
+			// all services store the same id
+			if child.err == nil && child.calls > 0 && child.saved != id && err == nil {
+				t.Errorf("%s: service %v saved id %v, want %v", tt.name, i, child.saved, id)
+			}
+		}
+		// the same policy is applied to the other apis
+		err = a.StoreRecord(&tlsutil.RecordData{})
+		if err != tt.want {
+			t.Errorf("%s: StoreRecord() err = %v, want %v", tt.name, err, tt.want)
+		}
+		_, err = a.SaveEvent(context.Background(), event.Event{ID: "1"})
+		if err != tt.want {
+			t.Errorf("%s: SaveEvent() err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSaveCertificate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		errs    []error
+		want    string
+		wantErr error
+	}{
+		{"all ok", AllMustSucceed, []error{nil, nil}, "cert-a", nil},
+		{"all secondary fails", AllMustSucceed, []error{nil, err2}, "", err2},
+		{"all primary fails", AllMustSucceed, []error{err1, nil}, "", err1},
+		{"besteffort secondary fails", BestEffort, []error{nil, err2}, "cert-a", nil},
+		{"besteffort primary fails", BestEffort, []error{err1, nil}, "", err1},
+		{"primary secondary fails", PrimarySecondary, []error{nil, err2}, "cert-a", nil},
+		{"primary fails", PrimarySecondary, []error{err1, nil}, "", err1},
+	}
+	for _, tt := range tests {
+		a, _ := newTestArchiver(t, tt.policy, tt.errs...)
+		id, err := a.SaveCertificate(context.Background(), &tlsutil.CertificateData{Digest: "digest"})
+		if err != tt.wantErr || id != tt.want {
+			t.Errorf("%s: SaveCertificate() = %q, %v; want %q, %v", tt.name, id, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNotSupported(t *testing.T) {
+	dns := &testService{id: "dns", apis: []archive.API{archive.DNSAPI}}
+	dnstls := &testService{id: "dnstls", apis: []archive.API{archive.DNSAPI, archive.TLSAPI}}
+	a, err := New("multi", []archive.Service{dnstls, dns})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if apis := a.Implements(); len(apis) != 1 || apis[0] != archive.DNSAPI {
+		t.Errorf("Implements() = %v, want [DNSAPI]", apis)
+	}
+	if _, err := a.SaveConnection(context.Background(), &tlsutil.ConnectionData{}); err != tlsutil.ErrNotSupported {
+		t.Errorf("SaveConnection() err = %v, want %v", err, tlsutil.ErrNotSupported)
+	}
+	if _, err := a.SaveCertificate(context.Background(), &tlsutil.CertificateData{}); err != tlsutil.ErrNotSupported {
+		t.Errorf("SaveCertificate() err = %v, want %v", err, tlsutil.ErrNotSupported)
+	}
+	if err := a.StoreRecord(&tlsutil.RecordData{}); err != tlsutil.ErrNotSupported {
+		t.Errorf("StoreRecord() err = %v, want %v", err, tlsutil.ErrNotSupported)
+	}
+	if _, err := a.SaveEvent(context.Background(), event.Event{}); err != event.ErrNotSupported {
+		t.Errorf("SaveEvent() err = %v, want %v", err, event.ErrNotSupported)
+	}
+	if dns.calls != 0 || dnstls.calls != 0 {
+		t.Errorf("services were called: %v, %v", dns.calls, dnstls.calls)
+	}
+	// without common apis
+	evt := &testService{id: "event", apis: []archive.API{archive.EventAPI}}
+	if _, err := New("multi", []archive.Service{dns, evt}); err == nil {
+		t.Errorf("New() without common apis expected error")
+	}
+}
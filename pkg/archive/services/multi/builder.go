@@ -0,0 +1,65 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package multi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/core/option"
+)
+
+// Builder returns a builder function.
+func Builder() archive.BuildServiceFn {
+	return func(b *archive.Builder, def archive.ServiceDef) (archive.Service, error) {
+		if def.Opts == nil {
+			return nil, errors.New("'opts' is required")
+		}
+		// get services, they must be defined before
+		ids, ok, err := option.SliceString(def.Opts, "services")
+		if err != nil {
+			return nil, err
+		}
+		if !ok || len(ids) == 0 {
+			return nil, errors.New("'services' is required")
+		}
+		services := make([]archive.Service, 0, len(ids))
+		for _, id := range ids {
+			if id == def.ID {
+				return nil, errors.New("'services' can't reference itself")
+			}
+			svc, ok := b.Service(id)
+			if !ok {
+				return nil, fmt.Errorf("'services': '%s' not found", id)
+			}
+			services = append(services, svc)
+		}
+		// parse options
+		bopt := make([]Option, 0)
+		bopt = append(bopt, SetLogger(b.Logger()))
+		policyOpt, ok, err := option.String(def.Opts, "policy")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			policy, err := ParsePolicy(policyOpt)
+			if err != nil {
+				return nil, fmt.Errorf("'policy': %v", err)
+			}
+			bopt = append(bopt, SetPolicy(policy))
+		}
+		//create archive service
+		return New(def.ID, services, bopt...)
+	}
+}
+
+func init() {
+	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Options: []archive.OptionSpec{
+			{Name: "services", Type: archive.OptServices, Required: true, Description: "services where requests are sent"},
+			{Name: "policy", Type: archive.OptString, Enum: []string{"all", "besteffort", "primary"}, Description: "policy for errors of services"},
+		},
+	})
+}
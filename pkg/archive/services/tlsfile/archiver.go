@@ -0,0 +1,285 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package tlsfile implements tlsutil.Archive using file backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package tlsfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/fileutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "tlsfile"
+
+// File names.
+const (
+	ConnectionFileName  = "connections"
+	CertificateFileName = "certificates"
+	RecordFileName      = "records"
+)
+
+// Default values.
+const (
+	DefaultRotatePeriod         = time.Hour
+	DefaultMaxFileSize          = 0
+	DefaultSyncSeconds          = 5
+	DefaultCacheCertsExpiration = 30 * time.Minute
+	DefaultCacheCertsCleanUp    = 5 * time.Minute
+)
+
+// Archiver implements tls archive backend using json lines files.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//files
+	dir string
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	//writers & caches
+	wConns     *fileutil.Writer
+	wCerts     *fileutil.Writer
+	wRecords   *fileutil.Writer
+	cacheCerts *cache.Cache
+}
+
+// New creates a new storage.
+func New(id string, dir string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		dir:    dir,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger   yalogi.Logger
+	rotate   time.Duration
+	maxSize  int64
+	compress bool
+	syncSecs int
+	prefix   string
+}
+
+var defaultOptions = options{
+	logger:   yalogi.LogNull,
+	rotate:   DefaultRotatePeriod,
+	maxSize:  DefaultMaxFileSize,
+	compress: true,
+	syncSecs: DefaultSyncSeconds,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to file names.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetRotate option sets the period of file rotation.
+func SetRotate(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.rotate = d
+		}
+	}
+}
+
+// SetMaxFileSize option sets the max size in bytes of files.
+func SetMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// Compress option allows compress rotated files.
+func Compress(b bool) Option {
+	return func(o *options) {
+		o.compress = b
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting file tls archiver", a.id)
+	//init writers
+	a.wConns = fileutil.NewWriter(a.dir, a.getFileName(ConnectionFileName),
+		a.opts.rotate, a.opts.maxSize, a.opts.compress)
+	a.wCerts = fileutil.NewWriter(a.dir, a.getFileName(CertificateFileName),
+		a.opts.rotate, a.opts.maxSize, a.opts.compress)
+	a.wRecords = fileutil.NewWriter(a.dir, a.getFileName(RecordFileName),
+		a.opts.rotate, a.opts.maxSize, a.opts.compress)
+	a.cacheCerts = cache.New(DefaultCacheCertsExpiration, DefaultCacheCertsCleanUp)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	a.started = true
+	return nil
+}
+
+// SaveConnection implements tlsutil.Archiver interface.
+func (a *Archiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	if !a.started {
+		return "", tlsutil.ErrUnavailable
+	}
+	err := a.wConns.Write(cn)
+	if err != nil {
+		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
+		return "", tlsutil.ErrInternal
+	}
+	return cn.ID, nil
+}
+
+// fileCertificate is the stored certificate, x509 data is stored in raw.
+type fileCertificate struct {
+	ID     string `json:"id"`
+	Digest string `json:"digest"`
+	Raw    []byte `json:"raw"`
+}
+
+// SaveCertificate implements tlsutil.Archiver interface. Certificates are
+// only deduplicated while they remain in cache.
+func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	if !a.started {
+		return "", tlsutil.ErrUnavailable
+	}
+	// check in cache
+	ccert, ok := a.cacheCerts.Get(cert.Digest)
+	if ok {
+		cert, _ = ccert.(*tlsutil.CertificateData)
+		return cert.ID, nil
+	}
+	// don't exist, add to cache
+	a.cacheCerts.Add(cert.Digest, cert, cache.DefaultExpiration)
+	fcert := fileCertificate{ID: cert.ID, Digest: cert.Digest}
+	if cert.Data != nil {
+		fcert.Raw = cert.Data.Raw
+	}
+	err := a.wCerts.Write(fcert)
+	if err != nil {
+		a.logger.Warnf("%s: saving cert '%s': %v", a.id, cert.Digest, err)
+		return "", tlsutil.ErrInternal
+	}
+	return cert.ID, nil
+}
+
+// StoreRecord implements tlsutil.Archiver interface.
+func (a *Archiver) StoreRecord(r *tlsutil.RecordData) error {
+	if !a.started {
+		return tlsutil.ErrUnavailable
+	}
+	err := a.wRecords.Write(r)
+	if err != nil {
+		a.logger.Warnf("%s: saving record: %v", a.id, err)
+		return tlsutil.ErrInternal
+	}
+	return nil
+}
+
+// Shutdown closes the files.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down tls archiver", a.id)
+		a.started = false
+		close(a.close)
+		for _, w := range []*fileutil.Writer{a.wConns, a.wCerts, a.wRecords} {
+			err := w.Close()
+			if err != nil {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	_, err := os.Stat(a.dir)
+	return err
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			for _, w := range []*fileutil.Writer{a.wConns, a.wCerts, a.wRecords} {
+				err := w.Flush()
+				if err != nil {
+					a.logger.Warnf("%s: sync: %v", a.id, err)
+				}
+			}
+		case <-a.close:
+			return
+		}
+	}
+}
+
+func (a *Archiver) getFileName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.TLSAPI}
+}
@@ -1,14 +1,18 @@
 // Copyright 2019 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
 
-// Package tlsmdb implements tlsutil.Archive using mongodb backend.
+// Package tlsmdb implements tlsutil.Archive and tlsfinder.Finder using
+// mongodb backend.
 //
 // This package is a work in progress and makes no API stability promises.
 package tlsmdb
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,7 +22,9 @@ import (
 
 	"github.com/luids-io/api/tlsutil"
 	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
 	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/archive/pkg/tlsfinder"
 	"github.com/luids-io/core/yalogi"
 )
 
@@ -40,6 +46,9 @@ const (
 	DefaultSyncSeconds          = 5
 	DefaultCacheCertsExpiration = 30 * time.Minute
 	DefaultCacheCertsCleanUp    = 5 * time.Minute
+	DefaultMaxSize              = 100
+	DefaultWorkers              = 2
+	DefaultQueueDepth           = 4096
 )
 
 // Archiver implements tls archive backend using a mongo database.
@@ -54,9 +63,10 @@ type Archiver struct {
 	mu      sync.Mutex
 	started bool
 	close   chan struct{}
+	purger  *mongoutil.Purger
 	//bulks & caches
-	bulkConns   *mongoutil.Bulk
-	bulkRecords *mongoutil.Bulk
+	bulkConns   mongoutil.Writer
+	bulkRecords mongoutil.Writer
 	cacheCerts  *cache.Cache
 }
 
@@ -81,6 +91,7 @@ type Option func(*options)
 
 type options struct {
 	logger               yalogi.Logger
+	health               mongoutil.Health
 	connsBulkSize        int
 	recordsBulkSize      int
 	syncSecs             int
@@ -88,6 +99,12 @@ type options struct {
 	cacheCertsCleanUp    time.Duration
 	closeSession         bool
 	prefix               string
+	retention            time.Duration
+	spoolDir             string
+	spoolSize            int64
+	workers              int
+	queueDepth           int
+	queuePolicy          mongoutil.QueuePolicy
 }
 
 var defaultOptions = options{
@@ -97,6 +114,8 @@ var defaultOptions = options{
 	syncSecs:             DefaultSyncSeconds,
 	cacheCertsExpiration: DefaultCacheCertsExpiration,
 	cacheCertsCleanUp:    DefaultCacheCertsCleanUp,
+	workers:              DefaultWorkers,
+	queueDepth:           DefaultQueueDepth,
 }
 
 // SetLogger option allows set a custom logger.
@@ -122,6 +141,52 @@ func SetPrefix(s string) Option {
 	}
 }
 
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
+// SetSpool option enables a spool in dir where batches are stored when
+// database is unavailable. If maxSize is zero, spool size is unlimited.
+func SetSpool(dir string, maxSize int64) Option {
+	return func(o *options) {
+		o.spoolDir = dir
+		o.spoolSize = maxSize
+	}
+}
+
+// SetWorkers option sets the number of workers that insert documents
+// asynchronously, by default DefaultWorkers. If n is zero, documents are
+// inserted by the callers and bulks are flushed inside their requests, this
+// mode is kept only for compatibility.
+func SetWorkers(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.workers = n
+		}
+	}
+}
+
+// SetQueue option sets the depth and the policy of the insert queues.
+func SetQueue(depth int, policy mongoutil.QueuePolicy) Option {
+	return func(o *options) {
+		if depth > 0 {
+			o.queueDepth = depth
+		}
+		o.queuePolicy = policy
+	}
+}
+
 // Start the archiver.
 func (a *Archiver) Start() error {
 	a.mu.Lock()
@@ -137,14 +202,20 @@ func (a *Archiver) Start() error {
 		return err
 	}
 	//init bulks & caches
-	a.bulkConns = mongoutil.NewBulk(
-		a.getCollection(ConnectionColName),
-		a.opts.connsBulkSize,
-	)
-	a.bulkRecords = mongoutil.NewBulk(
-		a.getCollection(RecordsColName),
-		a.opts.recordsBulkSize,
-	)
+	a.bulkConns = a.newWriter(ConnectionColName, a.opts.connsBulkSize)
+	a.bulkRecords = a.newWriter(RecordsColName, a.opts.recordsBulkSize)
+	if a.opts.spoolDir != "" {
+		for _, b := range []struct {
+			name string
+			bulk mongoutil.Writer
+		}{{ConnectionColName, a.bulkConns}, {RecordsColName, a.bulkRecords}} {
+			spool, err := mongoutil.NewSpool(a.opts.spoolDir, a.getCollection(b.name).Name, a.opts.spoolSize)
+			if err != nil {
+				return err
+			}
+			b.bulk.SetSpool(spool)
+		}
+	}
 	a.cacheCerts = cache.New(
 		DefaultCacheCertsExpiration,
 		DefaultCacheCertsCleanUp,
@@ -152,6 +223,9 @@ func (a *Archiver) Start() error {
 	//init control
 	a.close = make(chan struct{})
 	go a.doSync()
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
 	a.started = true
 	return nil
 }
@@ -161,32 +235,43 @@ func (a *Archiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionDat
 	if !a.started {
 		return "", tlsutil.ErrUnavailable
 	}
+	// with spool, data is stored while database is down
+	if !a.available() && a.opts.spoolDir == "" {
+		return "", tlsutil.ErrUnavailable
+	}
 	err := a.bulkConns.Insert(cn)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
+		return "", tlsutil.ErrUnavailable
+	}
 	if err != nil {
 		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
-		return "", tlsutil.ErrInternal
+		return "", a.dbError(err)
 	}
 	return cn.ID, nil
 }
 
 // SaveCertificate implements tlsutil.Archiver interface.
 func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
-	if !a.started {
+	if !a.started || !a.available() {
 		return "", tlsutil.ErrUnavailable
 	}
 	// check in cache
 	ccert, ok := a.cacheCerts.Get(cert.Digest)
 	if ok {
+		metrics.CacheRequests.WithLabelValues(a.id, "certificates", "hit").Inc()
 		cert, _ = ccert.(*tlsutil.CertificateData)
 		return cert.ID, nil
 	}
+	metrics.CacheRequests.WithLabelValues(a.id, "certificates", "miss").Inc()
 	// check in database
 	var dbcert tlsutil.CertificateData
-	err := a.getCollection(CertificateColName).
-		Find(bson.M{"digest": cert.Digest}).One(&dbcert)
+	c := a.copyCollection(CertificateColName)
+	defer c.Database.Session.Close()
+	err := c.Find(bson.M{"digest": cert.Digest}).One(&dbcert)
 	if err != nil && err != mgo.ErrNotFound {
 		a.logger.Errorf("%s: finding cert digest: %v", a.id, err)
-		return "", tlsutil.ErrInternal
+		return "", a.dbError(err)
 	} else if err == nil {
 		//exists, but not in cache-> add to cache
 		a.cacheCerts.Add(cert.Digest, &dbcert, cache.DefaultExpiration)
@@ -194,11 +279,13 @@ func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.Certificat
 	}
 	// don't exist, add to cache
 	a.cacheCerts.Add(cert.Digest, cert, cache.DefaultExpiration)
-	err = a.getCollection(CertificateColName).Insert(cert)
+	err = c.Insert(cert)
 	if err != nil {
+		a.cacheCerts.Delete(cert.Digest)
 		a.logger.Warnf("%s: saving cert '%s': %v", a.id, cert.Digest, err)
-		return "", tlsutil.ErrInternal
+		return "", a.dbError(err)
 	}
+	metrics.Documents.WithLabelValues(a.id, CertificateColName).Inc()
 	return cert.ID, nil
 }
 
@@ -207,14 +294,172 @@ func (a *Archiver) StoreRecord(r *tlsutil.RecordData) error {
 	if !a.started {
 		return tlsutil.ErrUnavailable
 	}
+	if !a.available() && a.opts.spoolDir == "" {
+		return tlsutil.ErrUnavailable
+	}
 	err := a.bulkRecords.Insert(r)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		return tlsutil.ErrUnavailable
+	}
 	if err != nil {
 		a.logger.Warnf("%s: saving record: %v", a.id, err)
-		return tlsutil.ErrInternal
+		return a.dbError(err)
 	}
 	return nil
 }
 
+// GetConnection implements tlsfinder.Finder interface.
+func (a *Archiver) GetConnection(ctx context.Context, id string) (*tlsutil.ConnectionData, bool, error) {
+	if !a.started || !a.available() {
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == "" {
+		return nil, false, nil
+	}
+	//do find
+	var cn tlsutil.ConnectionData
+	c := a.copyCollection(ConnectionColName)
+	defer c.Database.Session.Close()
+	err := c.FindId(id).One(&cn)
+	if err == mgo.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getconnection(%s): %v", a.id, id, err)
+		return nil, false, a.dbError(err)
+	}
+	return &cn, true, nil
+}
+
+// ListConnections implements tlsfinder.Finder interface.
+func (a *Archiver) ListConnections(ctx context.Context, filters []tlsfinder.ConnectionsFilter,
+	rev bool, max int, next string) ([]*tlsutil.ConnectionData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	c := a.copyCollection(ConnectionColName)
+	defer c.Database.Session.Close()
+	//create filter
+	filter, err := connectionsFilter(filters, rev, next)
+	if err != nil {
+		a.logger.Warnf("%s: listconnections(): %v", a.id, err)
+		return nil, "", tlsutil.ErrBadRequest
+	}
+	//do find
+	q := c.Find(filter)
+	if rev {
+		q = q.Sort("-info.start", "-_id")
+	} else {
+		q = q.Sort("info.start", "_id")
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		q = q.Limit(max)
+	}
+	//do query
+	var result []*tlsutil.ConnectionData
+	err = q.All(&result)
+	if err != nil {
+		a.logger.Warnf("%s: listconnections(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//return
+	if max > 0 && len(result) == max {
+		last := result[len(result)-1]
+		var start time.Time
+		if last.Info != nil {
+			start = last.Info.Start
+		}
+		return result, formatNext(start, last.ID), nil
+	}
+	return result, "", nil
+}
+
+// GetCertificate implements tlsfinder.Finder interface.
+func (a *Archiver) GetCertificate(ctx context.Context, digest string) (*tlsutil.CertificateData, bool, error) {
+	if !a.started || !a.available() {
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//if invalid digest, then returns not found
+	if digest == "" {
+		return nil, false, nil
+	}
+	//do find
+	var m mdbCertificateData
+	c := a.copyCollection(CertificateColName)
+	defer c.Database.Session.Close()
+	err := c.Find(bson.M{"digest": digest}).One(&m)
+	if err == mgo.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getcertificate(%s): %v", a.id, digest, err)
+		return nil, false, a.dbError(err)
+	}
+	//decode raw certificate
+	cert := &tlsutil.CertificateData{ID: m.ID, Digest: m.Digest}
+	if len(m.Data.Raw) > 0 {
+		cert.Data, err = x509.ParseCertificate(m.Data.Raw)
+		if err != nil {
+			a.logger.Warnf("%s: getcertificate(%s): parsing certificate: %v", a.id, digest, err)
+			return nil, false, tlsutil.ErrInternal
+		}
+	}
+	return cert, true, nil
+}
+
+// ListRecords implements tlsfinder.Finder interface.
+func (a *Archiver) ListRecords(ctx context.Context, connID string, max int, next string) ([]*tlsutil.RecordData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	//get streams from connection
+	cn, ok, err := a.GetConnection(ctx, connID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return []*tlsutil.RecordData{}, "", nil
+	}
+	//create filter
+	filter := recordsFilter(cn, next)
+	if filter == nil {
+		return []*tlsutil.RecordData{}, "", nil
+	}
+	//do find
+	c := a.copyCollection(RecordsColName)
+	defer c.Database.Session.Close()
+	q := c.Find(filter).Sort("_id")
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		q = q.Limit(max)
+	}
+	var mdbAll []mdbRecordData
+	err = q.All(&mdbAll)
+	if err != nil {
+		a.logger.Warnf("%s: listrecords(%s): %v", a.id, connID, err)
+		return nil, "", a.dbError(err)
+	}
+	//convert data
+	last := ""
+	result := make([]*tlsutil.RecordData, 0, len(mdbAll))
+	for _, m := range mdbAll {
+		r := m.RecordData
+		result = append(result, &r)
+		last = m.StorageID.Hex()
+	}
+	//return
+	if max > 0 && len(result) == max {
+		return result, last, nil
+	}
+	return result, "", nil
+}
+
 // Shutdown closes the conection.
 func (a *Archiver) Shutdown() {
 	a.mu.Lock()
@@ -223,7 +468,12 @@ func (a *Archiver) Shutdown() {
 	if a.started {
 		a.logger.Infof("%s: shutting down tls archiver", a.id)
 		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
 		close(a.close)
+		a.closeWriters()
 		a.session.Fsync(false)
 		if a.opts.closeSession {
 			a.session.Close()
@@ -251,12 +501,50 @@ func (a *Archiver) doSync() {
 			for _, err := range errs {
 				a.logger.Warnf("%s: %v", a.id, err)
 			}
+			a.replayBulks()
 		case <-a.close:
 			errs := a.syncBulks()
 			for _, err := range errs {
 				a.logger.Warnf("%s: %v", a.id, err)
 			}
-			break
+			return
+		}
+	}
+}
+
+// purge removes connections and records, certificates are shared between
+// connections so they are kept.
+func (a *Archiver) purge(before time.Time) {
+	removed, err := mongoutil.Purge(a.getCollection(ConnectionColName), "info.start", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging connections: %v", a.id, err)
+	} else {
+		metrics.PurgedDocuments.WithLabelValues(a.id, ConnectionColName).Add(float64(removed))
+		a.logger.Infof("%s: purged %v connections older than %v", a.id, removed, before.Format(time.RFC3339))
+	}
+	removed, err = mongoutil.Purge(a.getCollection(RecordsColName), "timestamp", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging records: %v", a.id, err)
+	} else {
+		metrics.PurgedDocuments.WithLabelValues(a.id, RecordsColName).Add(float64(removed))
+		a.logger.Infof("%s: purged %v records older than %v", a.id, removed, before.Format(time.RFC3339))
+	}
+}
+
+func (a *Archiver) replayBulks() {
+	for _, b := range []struct {
+		name string
+		bulk mongoutil.Writer
+	}{{"connections", a.bulkConns}, {"records", a.bulkRecords}} {
+		if !b.bulk.Pending() || !a.available() || a.session.Ping() != nil {
+			continue
+		}
+		n, err := b.bulk.Replay()
+		if err != nil {
+			a.logger.Warnf("%s: replaying %s: %v", a.id, b.name, err)
+		}
+		if n > 0 {
+			a.logger.Infof("%s: replayed %v %s from spool", a.id, n, b.name)
 		}
 	}
 }
@@ -266,15 +554,42 @@ func (a *Archiver) syncBulks() []error {
 	var err error
 	err = a.bulkConns.Flush()
 	if err != nil {
+		a.dbError(err)
 		errs = append(errs, fmt.Errorf("sync connections: %v", err))
 	}
 	err = a.bulkRecords.Flush()
 	if err != nil {
+		a.dbError(err)
 		errs = append(errs, fmt.Errorf("sync records: %v", err))
 	}
 	return errs
 }
 
+func (a *Archiver) newWriter(name string, size int) mongoutil.Writer {
+	var w mongoutil.Writer
+	if a.opts.workers == 0 {
+		w = mongoutil.NewBulk(a.getCollection(name), size)
+	} else {
+		w = mongoutil.NewPipeline(a.getCollection(name),
+			a.opts.queueDepth, a.opts.workers, size, a.opts.queuePolicy,
+			func(err error) {
+				a.dbError(err)
+				a.logger.Warnf("%s: inserting %s: %v", a.id, name, err)
+			})
+	}
+	w.SetMetrics(a.id, name)
+	return w
+}
+
+func (a *Archiver) closeWriters() {
+	for _, w := range []mongoutil.Writer{a.bulkConns, a.bulkRecords} {
+		err := w.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+}
+
 func (a *Archiver) getDatabase() *mgo.Database {
 	return a.session.DB(a.database)
 }
@@ -286,6 +601,32 @@ func (a *Archiver) getCollection(name string) *mgo.Collection {
 	return a.session.DB(a.database).C(name)
 }
 
+// copyCollection returns a collection using a copy of the session, the
+// session must be closed after use.
+func (a *Archiver) copyCollection(name string) *mgo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.session.Copy().DB(a.database).C(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongoutil.IsUnavailable(err) {
+		return tlsutil.ErrUnavailable
+	}
+	return tlsutil.ErrInternal
+}
+
 // ID implements archive.Service interface.
 func (a *Archiver) ID() string {
 	return a.id
@@ -300,3 +641,109 @@ func (a *Archiver) Class() string {
 func (a *Archiver) Implements() []archive.API {
 	return []archive.API{archive.TLSAPI}
 }
+
+func createFilter(filters []tlsfinder.ConnectionsFilter) bson.M {
+	switch len(filters) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return bsonFilter(filters[0])
+	}
+	mfilters := make([]bson.M, 0, len(filters))
+	for _, f := range filters {
+		mfilters = append(mfilters, bsonFilter(f))
+	}
+	return bson.M{"$or": mfilters}
+}
+
+func bsonFilter(f tlsfinder.ConnectionsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() || !f.To.IsZero() {
+		tfilter := bson.M{}
+		if !f.Since.IsZero() {
+			tfilter["$gt"] = f.Since
+		}
+		if !f.To.IsZero() {
+			tfilter["$lt"] = f.To
+		}
+		m["info.start"] = tfilter
+	}
+	if f.Client != nil {
+		m["info.clientip"] = f.Client.String()
+	}
+	if f.Server != nil {
+		m["info.serverip"] = f.Server.String()
+	}
+	if f.SNI != "" {
+		m["clienthello.extensioninfo.sni"] = f.SNI
+	}
+	if f.JA3Digest != "" {
+		m["clienthello.ja3digest"] = f.JA3Digest
+	}
+	return m
+}
+
+// connectionsFilter returns the filter of the connections that follow the
+// cursor next in the sort order.
+func connectionsFilter(filters []tlsfinder.ConnectionsFilter, rev bool, next string) (bson.M, error) {
+	filter := createFilter(filters)
+	if next == "" {
+		return filter, nil
+	}
+	start, id, err := parseNext(next)
+	if err != nil {
+		return nil, err
+	}
+	op := "$gt"
+	if rev {
+		op = "$lt"
+	}
+	cursor := bson.M{"$or": []bson.M{
+		{"info.start": bson.M{op: start}},
+		{"info.start": start, "_id": bson.M{op: id}},
+	}}
+	if len(filter) > 0 {
+		return bson.M{"$and": []bson.M{filter, cursor}}, nil
+	}
+	return cursor, nil
+}
+
+// recordsFilter returns the filter of the records of the streams of the
+// connection that follow the cursor next. It returns nil if the connection
+// has no streams.
+func recordsFilter(cn *tlsutil.ConnectionData, next string) bson.M {
+	streams := make([]string, 0, 2)
+	if cn.SendStream != nil && cn.SendStream.ID != "" {
+		streams = append(streams, cn.SendStream.ID)
+	}
+	if cn.RcvdStream != nil && cn.RcvdStream.ID != "" {
+		streams = append(streams, cn.RcvdStream.ID)
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+	filter := bson.M{"streamid": bson.M{"$in": streams}}
+	if next != "" && bson.IsObjectIdHex(next) {
+		filter["_id"] = bson.M{"$gt": bson.ObjectIdHex(next)}
+	}
+	return filter
+}
+
+// next token is composed by the start time in milliseconds (precision
+// used by mongodb) and the connection id.
+func formatNext(start time.Time, id string) string {
+	ms := start.UnixNano() / int64(time.Millisecond)
+	return fmt.Sprintf("%d_%s", ms, id)
+}
+
+func parseNext(next string) (time.Time, string, error) {
+	fields := strings.SplitN(next, "_", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s'", next)
+	}
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s': %v", next, err)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), fields[1], nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package tlsmdb
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+)
+
+func TestCreateFilter(t *testing.T) {
+	since := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	to := since.Add(time.Hour)
+	tests := []struct {
+		filters []tlsfinder.ConnectionsFilter
+		want    bson.M
+	}{
+		{nil, bson.M{}},
+		{[]tlsfinder.ConnectionsFilter{{Since: since}},
+			bson.M{"info.start": bson.M{"$gt": since}}},
+		{[]tlsfinder.ConnectionsFilter{{Since: since, To: to, SNI: "www.example.com"}},
+			bson.M{
+				"info.start":                    bson.M{"$gt": since, "$lt": to},
+				"clienthello.extensioninfo.sni": "www.example.com",
+			}},
+		{[]tlsfinder.ConnectionsFilter{{Client: net.ParseIP("10.0.0.1"), Server: net.ParseIP("10.0.0.2"), JA3Digest: "abc"}},
+			bson.M{
+				"info.clientip":         "10.0.0.1",
+				"info.serverip":         "10.0.0.2",
+				"clienthello.ja3digest": "abc",
+			}},
+		{[]tlsfinder.ConnectionsFilter{{SNI: "a.com"}, {To: to}},
+			bson.M{"$or": []bson.M{
+				{"clienthello.extensioninfo.sni": "a.com"},
+				{"info.start": bson.M{"$lt": to}},
+			}}},
+	}
+	for i, tt := range tests {
+		got := createFilter(tt.filters)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %v: createFilter() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestConnectionsFilter(t *testing.T) {
+	// parsed times are local
+	start := time.Unix(1615379415, 123000000)
+	next := formatNext(start, "conn_1")
+	after := bson.M{"$or": []bson.M{
+		{"info.start": bson.M{"$gt": start}},
+		{"info.start": start, "_id": bson.M{"$gt": "conn_1"}},
+	}}
+	before := bson.M{"$or": []bson.M{
+		{"info.start": bson.M{"$lt": start}},
+		{"info.start": start, "_id": bson.M{"$lt": "conn_1"}},
+	}}
+	sni := []tlsfinder.ConnectionsFilter{{SNI: "a.com"}}
+	tests := []struct {
+		filters []tlsfinder.ConnectionsFilter
+		rev     bool
+		next    string
+		want    bson.M
+		wantErr bool
+	}{
+		{nil, false, "", bson.M{}, false},
+		{sni, false, "", bson.M{"clienthello.extensioninfo.sni": "a.com"}, false},
+		{nil, false, next, after, false},
+		{nil, true, next, before, false},
+		{sni, false, next, bson.M{"$and": []bson.M{{"clienthello.extensioninfo.sni": "a.com"}, after}}, false},
+		{nil, false, "1615379415123", nil, true},
+		{nil, false, "1615379415123_", nil, true},
+		{nil, false, "bad_conn_1", nil, true},
+	}
+	for i, tt := range tests {
+		got, err := connectionsFilter(tt.filters, tt.rev, tt.next)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %v: err = %v, wantErr %v", i, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %v: connectionsFilter() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// mongodb stores times with millisecond precision
+	start := time.Date(2021, 3, 10, 12, 30, 15, 123456789, time.UTC)
+	next := formatNext(start, "conn_1_2")
+	if next != "1615379415123_conn_1_2" {
+		t.Errorf("formatNext() = %v", next)
+	}
+	gotStart, gotID, err := parseNext(next)
+	if err != nil {
+		t.Fatalf("parseNext(): %v", err)
+	}
+	if !gotStart.Equal(start.Truncate(time.Millisecond)) || gotID != "conn_1_2" {
+		t.Errorf("parseNext() = %v, %v", gotStart, gotID)
+	}
+}
+
+func TestRecordsFilter(t *testing.T) {
+	id := bson.NewObjectId()
+	send := &tlsutil.StreamData{ID: "s1"}
+	rcvd := &tlsutil.StreamData{ID: "s2"}
+	tests := []struct {
+		cn   *tlsutil.ConnectionData
+		next string
+		want bson.M
+	}{
+		{&tlsutil.ConnectionData{}, "", nil},
+		{&tlsutil.ConnectionData{SendStream: &tlsutil.StreamData{}}, "", nil},
+		{&tlsutil.ConnectionData{SendStream: send, RcvdStream: rcvd}, "",
+			bson.M{"streamid": bson.M{"$in": []string{"s1", "s2"}}}},
+		{&tlsutil.ConnectionData{RcvdStream: rcvd}, "",
+			bson.M{"streamid": bson.M{"$in": []string{"s2"}}}},
+		{&tlsutil.ConnectionData{SendStream: send}, id.Hex(),
+			bson.M{"streamid": bson.M{"$in": []string{"s1"}}, "_id": bson.M{"$gt": id}}},
+		// invalid cursors are ignored
+		{&tlsutil.ConnectionData{SendStream: send}, "invalid",
+			bson.M{"streamid": bson.M{"$in": []string{"s1"}}}},
+	}
+	for i, tt := range tests {
+		got := recordsFilter(tt.cn, tt.next)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %v: recordsFilter() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/luids-io/archive/pkg/archive"
 	"github.com/luids-io/archive/pkg/archive/backends/mongodb"
+	"github.com/luids-io/archive/pkg/mongoutil"
 	"github.com/luids-io/core/option"
 )
 
@@ -35,6 +36,9 @@ func Builder() archive.BuildServiceFn {
 		// parse options
 		bopt := make([]Option, 0)
 		bopt = append(bopt, SetLogger(b.Logger()))
+		if h, ok := back.(mongoutil.Health); ok {
+			bopt = append(bopt, SetHealth(h))
+		}
 		//by default, it uses DefaultDBName
 		dbname := DefaultDBName
 		if def.Opts != nil {
@@ -53,6 +57,57 @@ func Builder() archive.BuildServiceFn {
 			if ok {
 				bopt = append(bopt, SetPrefix(prefixOpt))
 			}
+			workersOpt, ok, err := option.Int(def.Opts, "workers")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				if workersOpt < 0 {
+					return nil, errors.New("'workers' must be positive")
+				}
+				bopt = append(bopt, SetWorkers(workersOpt))
+			}
+			queueOpt, _, err := option.Int(def.Opts, "queuesize")
+			if err != nil {
+				return nil, err
+			}
+			policy := mongoutil.Block
+			policyOpt, ok, err := option.String(def.Opts, "queuepolicy")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				policy, err = mongoutil.ParseQueuePolicy(policyOpt)
+				if err != nil {
+					return nil, fmt.Errorf("'queuepolicy': %v", err)
+				}
+			}
+			bopt = append(bopt, SetQueue(queueOpt, policy))
+			spoolOpt, ok, err := option.String(def.Opts, "spool")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				spoolsize, _, err := option.Int(def.Opts, "spoolsize")
+				if err != nil {
+					return nil, err
+				}
+				if spoolsize < 0 {
+					return nil, errors.New("'spoolsize' must be positive")
+				}
+				bopt = append(bopt, SetSpool(spoolOpt, int64(spoolsize)*1024*1024))
+			}
+			retentionOpt, ok, err := option.String(def.Opts, "retention")
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				retention, err := archive.ParseRetention(retentionOpt)
+				if err != nil {
+					return nil, fmt.Errorf("'retention': %v", err)
+				}
+				bopt = append(bopt, SetRetention(retention))
+			}
 		}
 		//create archive service
 		archiver := New(def.ID, session, dbname, bopt...)
@@ -69,4 +124,19 @@ func Builder() archive.BuildServiceFn {
 
 func init() {
 	archive.RegisterServiceBuilder(ServiceClass, Builder())
+	archive.RegisterServiceSpec(ServiceClass, archive.ServiceSpec{
+		Backends:   []string{mongodb.BackendClass},
+		Implements: []archive.API{archive.TLSAPI},
+		Exclusive:  true,
+		Options: []archive.OptionSpec{
+			{Name: "dbname", Type: archive.OptString, Description: "database name"},
+			{Name: "prefix", Type: archive.OptString, Description: "prefix for collection names"},
+			{Name: "retention", Type: archive.OptString, Description: "purge documents older than (30d, 2w, 12h)"},
+			{Name: "spool", Type: archive.OptString, Description: "spool directory for failed bulks"},
+			{Name: "spoolsize", Type: archive.OptInt, Description: "max size of spool in MB"},
+			{Name: "workers", Type: archive.OptInt, Description: "number of async insert workers, 0 inserts synchronously in the callers (legacy)"},
+			{Name: "queuesize", Type: archive.OptInt, Description: "depth of the insert queue"},
+			{Name: "queuepolicy", Type: archive.OptString, Enum: []string{"block", "drop"}, Description: "policy when queue is full"},
+		},
+	})
 }
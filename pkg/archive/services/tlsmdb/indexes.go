@@ -2,6 +2,64 @@
 
 package tlsmdb
 
+import "github.com/globalsign/mgo"
+
 func (a *Archiver) createIdx() error {
+	err := a.createIdxConnections()
+	if err != nil {
+		return err
+	}
+	err = a.createIdxCertificates()
+	if err != nil {
+		return err
+	}
+	return a.createIdxRecords()
+}
+
+func (a *Archiver) createIdxConnections() error {
+	c := a.getCollection(ConnectionColName)
+	indexes := []mgo.Index{
+		{Key: []string{"info.start"}},
+		{Key: []string{"info.start", "_id"}},
+		{Key: []string{"info.clientip"}},
+		{Key: []string{"info.serverip"}},
+		{Key: []string{"clienthello.extensioninfo.sni"}},
+		{Key: []string{"clienthello.ja3digest"}},
+	}
+	for _, idx := range indexes {
+		err := c.EnsureIndex(idx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Archiver) createIdxCertificates() error {
+	c := a.getCollection(CertificateColName)
+	indexes := []mgo.Index{
+		{Key: []string{"digest"}},
+	}
+	for _, idx := range indexes {
+		err := c.EnsureIndex(idx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Archiver) createIdxRecords() error {
+	c := a.getCollection(RecordsColName)
+	indexes := []mgo.Index{
+		{Key: []string{"streamid"}},
+		{Key: []string{"timestamp"}},
+	}
+	for _, idx := range indexes {
+		err := c.EnsureIndex(idx)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,24 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package tlsmdb
+
+import (
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/api/tlsutil"
+)
+
+// certificates are stored marshalling x509.Certificate, so only raw data is
+// decoded and then parsed
+type mdbCertificateData struct {
+	ID     string `bson:"id"`
+	Digest string `bson:"digest"`
+	Data   struct {
+		Raw []byte `bson:"raw"`
+	} `bson:"data"`
+}
+
+type mdbRecordData struct {
+	StorageID          bson.ObjectId `bson:"_id"`
+	tlsutil.RecordData `bson:",inline"`
+}
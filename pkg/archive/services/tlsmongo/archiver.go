@@ -0,0 +1,725 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package tlsmongo implements tlsutil.Archive and tlsfinder.Finder using
+// mongodriver backend. Collections and documents are the same of tlsmdb.
+//
+// This package is a work in progress and makes no API stability promises.
+package tlsmongo
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/archive/metrics"
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+	"github.com/luids-io/archive/pkg/mongoutil"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "tlsmongo"
+
+// Collection names.
+const (
+	ConnectionColName  = "connections"
+	CertificateColName = "certificates"
+	RecordsColName     = "records"
+)
+
+// Default values.
+const (
+	DefaultDBName               = "luidsdb"
+	DefaultConnsBulkSize        = 256
+	DefaultRecordsBulkSize      = 1024
+	DefaultSyncSeconds          = 5
+	DefaultCacheCertsExpiration = 30 * time.Minute
+	DefaultCacheCertsCleanUp    = 5 * time.Minute
+	DefaultMaxSize              = 100
+	DefaultWorkers              = 2
+	DefaultQueueDepth           = 4096
+)
+
+// Archiver implements tls archive backend using a mongo database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	client   *mongo.Client
+	database string
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	purger  *mongoutil.Purger
+	//bulks & caches
+	bulkConns   mongoutil.Writer
+	bulkRecords mongoutil.Writer
+	cacheCerts  *cache.Cache
+}
+
+// New creates a new storage.
+func New(id string, client *mongo.Client, db string, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:       id,
+		opts:     opts,
+		logger:   opts.logger,
+		database: db,
+		client:   client,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger               yalogi.Logger
+	health               mongoutil.Health
+	connsBulkSize        int
+	recordsBulkSize      int
+	syncSecs             int
+	cacheCertsExpiration time.Duration
+	cacheCertsCleanUp    time.Duration
+	closeClient          bool
+	prefix               string
+	retention            time.Duration
+	spoolDir             string
+	spoolSize            int64
+	workers              int
+	queueDepth           int
+	queuePolicy          mongoutil.QueuePolicy
+}
+
+var defaultOptions = options{
+	logger:               yalogi.LogNull,
+	connsBulkSize:        DefaultConnsBulkSize,
+	recordsBulkSize:      DefaultRecordsBulkSize,
+	syncSecs:             DefaultSyncSeconds,
+	cacheCertsExpiration: DefaultCacheCertsExpiration,
+	cacheCertsCleanUp:    DefaultCacheCertsCleanUp,
+	workers:              DefaultWorkers,
+	queueDepth:           DefaultQueueDepth,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// CloseClient option allows disconnect mongo client on shutdown.
+func CloseClient(b bool) Option {
+	return func(o *options) {
+		o.closeClient = b
+	}
+}
+
+// SetPrefix option allows set a prefix to collection.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// SetHealth option sets the health of the database. If the database is
+// unavailable, requests are rejected.
+func SetHealth(h mongoutil.Health) Option {
+	return func(o *options) {
+		o.health = h
+	}
+}
+
+// SetRetention option enables purging of data older than d.
+func SetRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.retention = d
+	}
+}
+
+// SetSpool option enables a spool in dir where batches are stored when
+// database is unavailable. If maxSize is zero, spool size is unlimited.
+func SetSpool(dir string, maxSize int64) Option {
+	return func(o *options) {
+		o.spoolDir = dir
+		o.spoolSize = maxSize
+	}
+}
+
+// SetWorkers option sets the number of workers that insert documents
+// asynchronously, by default DefaultWorkers. If n is zero, documents are
+// inserted by the callers and bulks are flushed inside their requests, this
+// mode is kept only for compatibility.
+func SetWorkers(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.workers = n
+		}
+	}
+}
+
+// SetQueue option sets the depth and the policy of the insert queues.
+func SetQueue(depth int, policy mongoutil.QueuePolicy) Option {
+	return func(o *options) {
+		if depth > 0 {
+			o.queueDepth = depth
+		}
+		o.queuePolicy = policy
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting mongodb tls archiver", a.id)
+	//create indexes
+	err := a.createIdx()
+	if err != nil {
+		return err
+	}
+	//init bulks & caches
+	a.bulkConns = a.newWriter(ConnectionColName, a.opts.connsBulkSize)
+	a.bulkRecords = a.newWriter(RecordsColName, a.opts.recordsBulkSize)
+	if a.opts.spoolDir != "" {
+		for _, b := range []struct {
+			name string
+			bulk mongoutil.Writer
+		}{{ConnectionColName, a.bulkConns}, {RecordsColName, a.bulkRecords}} {
+			spool, err := mongoutil.NewSpool(a.opts.spoolDir, a.getCollection(b.name).Name(), a.opts.spoolSize)
+			if err != nil {
+				return err
+			}
+			b.bulk.SetSpool(spool)
+		}
+	}
+	a.cacheCerts = cache.New(
+		DefaultCacheCertsExpiration,
+		DefaultCacheCertsCleanUp,
+	)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	if a.opts.retention > 0 {
+		a.purger = mongoutil.NewPurger(a.opts.retention, 0, a.purge)
+	}
+	a.started = true
+	return nil
+}
+
+// SaveConnection implements tlsutil.Archiver interface.
+func (a *Archiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	if !a.started {
+		return "", tlsutil.ErrUnavailable
+	}
+	// with spool, data is stored while database is down
+	if !a.available() && a.opts.spoolDir == "" {
+		return "", tlsutil.ErrUnavailable
+	}
+	err := a.bulkConns.Insert(cn)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
+		return "", tlsutil.ErrUnavailable
+	}
+	if err != nil {
+		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
+		return "", a.dbError(err)
+	}
+	return cn.ID, nil
+}
+
+// SaveCertificate implements tlsutil.Archiver interface.
+func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	if !a.started || !a.available() {
+		return "", tlsutil.ErrUnavailable
+	}
+	// check in cache
+	ccert, ok := a.cacheCerts.Get(cert.Digest)
+	if ok {
+		metrics.CacheRequests.WithLabelValues(a.id, "certificates", "hit").Inc()
+		cert, _ = ccert.(*tlsutil.CertificateData)
+		return cert.ID, nil
+	}
+	metrics.CacheRequests.WithLabelValues(a.id, "certificates", "miss").Inc()
+	// check in database
+	var dbcert mdbCertificateData
+	c := a.getCollection(CertificateColName)
+	err := c.FindOne(ctx, bson.M{"digest": cert.Digest}).Decode(&dbcert)
+	if err != nil && err != mongo.ErrNoDocuments {
+		a.logger.Errorf("%s: finding cert digest: %v", a.id, err)
+		return "", a.dbError(err)
+	} else if err == nil {
+		//exists, but not in cache-> add to cache
+		a.cacheCerts.Add(cert.Digest, &tlsutil.CertificateData{ID: dbcert.ID, Digest: dbcert.Digest}, cache.DefaultExpiration)
+		return dbcert.ID, nil
+	}
+	// don't exist, add to cache
+	a.cacheCerts.Add(cert.Digest, cert, cache.DefaultExpiration)
+	_, err = c.InsertOne(ctx, cert)
+	if err != nil {
+		a.cacheCerts.Delete(cert.Digest)
+		a.logger.Warnf("%s: saving cert '%s': %v", a.id, cert.Digest, err)
+		return "", a.dbError(err)
+	}
+	metrics.Documents.WithLabelValues(a.id, CertificateColName).Inc()
+	return cert.ID, nil
+}
+
+// StoreRecord implements tlsutil.Archiver interface.
+func (a *Archiver) StoreRecord(r *tlsutil.RecordData) error {
+	if !a.started {
+		return tlsutil.ErrUnavailable
+	}
+	if !a.available() && a.opts.spoolDir == "" {
+		return tlsutil.ErrUnavailable
+	}
+	err := a.bulkRecords.Insert(r)
+	if err == mongoutil.ErrSpoolFull || err == mongoutil.ErrQueueFull || err == mongoutil.ErrClosed {
+		return tlsutil.ErrUnavailable
+	}
+	if err != nil {
+		a.logger.Warnf("%s: saving record: %v", a.id, err)
+		return a.dbError(err)
+	}
+	return nil
+}
+
+// GetConnection implements tlsfinder.Finder interface.
+func (a *Archiver) GetConnection(ctx context.Context, id string) (*tlsutil.ConnectionData, bool, error) {
+	if !a.started || !a.available() {
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//if invalid id, then returns not found
+	if id == "" {
+		return nil, false, nil
+	}
+	//do find
+	var cn tlsutil.ConnectionData
+	err := a.getCollection(ConnectionColName).FindOne(ctx, bson.M{"_id": id}).Decode(&cn)
+	if err == mongo.ErrNoDocuments {
+		return nil, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getconnection(%s): %v", a.id, id, err)
+		return nil, false, a.dbError(err)
+	}
+	return &cn, true, nil
+}
+
+// ListConnections implements tlsfinder.Finder interface.
+func (a *Archiver) ListConnections(ctx context.Context, filters []tlsfinder.ConnectionsFilter,
+	rev bool, max int, next string) ([]*tlsutil.ConnectionData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	//create filter
+	filter := createFilter(filters)
+	if next != "" {
+		start, id, err := parseNext(next)
+		if err != nil {
+			a.logger.Warnf("%s: listconnections(): %v", a.id, err)
+			return nil, "", tlsutil.ErrBadRequest
+		}
+		op := "$gt"
+		if rev {
+			op = "$lt"
+		}
+		cursor := bson.M{"$or": []bson.M{
+			{"info.start": bson.M{op: start}},
+			{"info.start": start, "_id": bson.M{op: id}},
+		}}
+		if len(filter) > 0 {
+			filter = bson.M{"$and": []bson.M{filter, cursor}}
+		} else {
+			filter = cursor
+		}
+	}
+	//do find
+	fopts := mopts.Find()
+	if rev {
+		fopts.SetSort(bson.D{{Key: "info.start", Value: -1}, {Key: "_id", Value: -1}})
+	} else {
+		fopts.SetSort(bson.D{{Key: "info.start", Value: 1}, {Key: "_id", Value: 1}})
+	}
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		fopts.SetLimit(int64(max))
+	}
+	//do query
+	var result []*tlsutil.ConnectionData
+	cur, err := a.getCollection(ConnectionColName).Find(ctx, filter, fopts)
+	if err == nil {
+		err = cur.All(ctx, &result)
+	}
+	if err != nil {
+		a.logger.Warnf("%s: listconnections(): %v", a.id, err)
+		return nil, "", a.dbError(err)
+	}
+	//return
+	if max > 0 && len(result) == max {
+		last := result[len(result)-1]
+		var start time.Time
+		if last.Info != nil {
+			start = last.Info.Start
+		}
+		return result, formatNext(start, last.ID), nil
+	}
+	return result, "", nil
+}
+
+// GetCertificate implements tlsfinder.Finder interface.
+func (a *Archiver) GetCertificate(ctx context.Context, digest string) (*tlsutil.CertificateData, bool, error) {
+	if !a.started || !a.available() {
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//if invalid digest, then returns not found
+	if digest == "" {
+		return nil, false, nil
+	}
+	//do find
+	var m mdbCertificateData
+	err := a.getCollection(CertificateColName).FindOne(ctx, bson.M{"digest": digest}).Decode(&m)
+	if err == mongo.ErrNoDocuments {
+		return nil, false, nil
+	}
+	if err != nil {
+		a.logger.Warnf("%s: getcertificate(%s): %v", a.id, digest, err)
+		return nil, false, a.dbError(err)
+	}
+	//decode raw certificate
+	cert := &tlsutil.CertificateData{ID: m.ID, Digest: m.Digest}
+	if len(m.Data.Raw) > 0 {
+		cert.Data, err = x509.ParseCertificate(m.Data.Raw)
+		if err != nil {
+			a.logger.Warnf("%s: getcertificate(%s): parsing certificate: %v", a.id, digest, err)
+			return nil, false, tlsutil.ErrInternal
+		}
+	}
+	return cert, true, nil
+}
+
+// ListRecords implements tlsfinder.Finder interface.
+func (a *Archiver) ListRecords(ctx context.Context, connID string, max int, next string) ([]*tlsutil.RecordData, string, error) {
+	if !a.started || !a.available() {
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	//get streams from connection
+	cn, ok, err := a.GetConnection(ctx, connID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return []*tlsutil.RecordData{}, "", nil
+	}
+	streams := make([]string, 0, 2)
+	if cn.SendStream != nil && cn.SendStream.ID != "" {
+		streams = append(streams, cn.SendStream.ID)
+	}
+	if cn.RcvdStream != nil && cn.RcvdStream.ID != "" {
+		streams = append(streams, cn.RcvdStream.ID)
+	}
+	if len(streams) == 0 {
+		return []*tlsutil.RecordData{}, "", nil
+	}
+	//create filter
+	filter := bson.M{"streamid": bson.M{"$in": streams}}
+	if next != "" {
+		if oid, err := primitive.ObjectIDFromHex(next); err == nil {
+			filter["_id"] = bson.M{"$gt": oid}
+		}
+	}
+	//do find
+	fopts := mopts.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if max == 0 && DefaultMaxSize > 0 {
+		max = DefaultMaxSize
+	}
+	if max > 0 {
+		fopts.SetLimit(int64(max))
+	}
+	var mdbAll []mdbRecordData
+	cur, err := a.getCollection(RecordsColName).Find(ctx, filter, fopts)
+	if err == nil {
+		err = cur.All(ctx, &mdbAll)
+	}
+	if err != nil {
+		a.logger.Warnf("%s: listrecords(%s): %v", a.id, connID, err)
+		return nil, "", a.dbError(err)
+	}
+	//convert data
+	last := ""
+	result := make([]*tlsutil.RecordData, 0, len(mdbAll))
+	for _, m := range mdbAll {
+		r := m.RecordData
+		result = append(result, &r)
+		last = m.StorageID.Hex()
+	}
+	//return
+	if max > 0 && len(result) == max {
+		return result, last, nil
+	}
+	return result, "", nil
+}
+
+// Shutdown closes the conection.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down tls archiver", a.id)
+		a.started = false
+		if a.purger != nil {
+			a.purger.Close()
+			a.purger = nil
+		}
+		close(a.close)
+		a.closeWriters()
+		if a.opts.closeClient {
+			ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+			defer cancel()
+			a.client.Disconnect(ctx)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.ping()
+}
+
+func (a *Archiver) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	return a.client.Ping(ctx, nil)
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+			a.replayBulks()
+		case <-a.close:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+			return
+		}
+	}
+}
+
+// purge removes connections and records, certificates are shared between
+// connections so they are kept.
+func (a *Archiver) purge(before time.Time) {
+	removed, err := mongodriverutil.Purge(a.getCollection(ConnectionColName), "info.start", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging connections: %v", a.id, err)
+	} else {
+		metrics.PurgedDocuments.WithLabelValues(a.id, ConnectionColName).Add(float64(removed))
+		a.logger.Infof("%s: purged %v connections older than %v", a.id, removed, before.Format(time.RFC3339))
+	}
+	removed, err = mongodriverutil.Purge(a.getCollection(RecordsColName), "timestamp", before)
+	if err != nil {
+		a.logger.Warnf("%s: purging records: %v", a.id, err)
+	} else {
+		metrics.PurgedDocuments.WithLabelValues(a.id, RecordsColName).Add(float64(removed))
+		a.logger.Infof("%s: purged %v records older than %v", a.id, removed, before.Format(time.RFC3339))
+	}
+}
+
+func (a *Archiver) replayBulks() {
+	for _, b := range []struct {
+		name string
+		bulk mongoutil.Writer
+	}{{"connections", a.bulkConns}, {"records", a.bulkRecords}} {
+		if !b.bulk.Pending() || !a.available() || a.ping() != nil {
+			continue
+		}
+		n, err := b.bulk.Replay()
+		if err != nil {
+			a.logger.Warnf("%s: replaying %s: %v", a.id, b.name, err)
+		}
+		if n > 0 {
+			a.logger.Infof("%s: replayed %v %s from spool", a.id, n, b.name)
+		}
+	}
+}
+
+func (a *Archiver) syncBulks() []error {
+	errs := make([]error, 0, 2)
+	var err error
+	err = a.bulkConns.Flush()
+	if err != nil {
+		a.dbError(err)
+		errs = append(errs, fmt.Errorf("sync connections: %v", err))
+	}
+	err = a.bulkRecords.Flush()
+	if err != nil {
+		a.dbError(err)
+		errs = append(errs, fmt.Errorf("sync records: %v", err))
+	}
+	return errs
+}
+
+func (a *Archiver) newWriter(name string, size int) mongoutil.Writer {
+	var w mongoutil.Writer
+	if a.opts.workers == 0 {
+		w = mongodriverutil.NewBulk(a.getCollection(name), size)
+	} else {
+		w = mongodriverutil.NewPipeline(a.getCollection(name),
+			a.opts.queueDepth, a.opts.workers, size, a.opts.queuePolicy,
+			func(err error) {
+				a.dbError(err)
+				a.logger.Warnf("%s: inserting %s: %v", a.id, name, err)
+			})
+	}
+	w.SetMetrics(a.id, name)
+	return w
+}
+
+func (a *Archiver) closeWriters() {
+	for _, w := range []mongoutil.Writer{a.bulkConns, a.bulkRecords} {
+		err := w.Close()
+		if err != nil {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+}
+
+func (a *Archiver) getCollection(name string) *mongo.Collection {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return a.client.Database(a.database).Collection(name)
+}
+
+// available returns false if database is down.
+func (a *Archiver) available() bool {
+	return a.opts.health == nil || a.opts.health.Available()
+}
+
+// dbError reports the error to the health monitor and returns the api
+// error.
+func (a *Archiver) dbError(err error) error {
+	if a.opts.health != nil {
+		a.opts.health.Report(err)
+	}
+	if mongodriverutil.IsUnavailable(err) {
+		return tlsutil.ErrUnavailable
+	}
+	return tlsutil.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.TLSAPI}
+}
+
+func createFilter(filters []tlsfinder.ConnectionsFilter) bson.M {
+	switch len(filters) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return bsonFilter(filters[0])
+	}
+	mfilters := make([]bson.M, 0, len(filters))
+	for _, f := range filters {
+		mfilters = append(mfilters, bsonFilter(f))
+	}
+	return bson.M{"$or": mfilters}
+}
+
+func bsonFilter(f tlsfinder.ConnectionsFilter) bson.M {
+	m := make(bson.M)
+	if !f.Since.IsZero() || !f.To.IsZero() {
+		tfilter := bson.M{}
+		if !f.Since.IsZero() {
+			tfilter["$gt"] = f.Since
+		}
+		if !f.To.IsZero() {
+			tfilter["$lt"] = f.To
+		}
+		m["info.start"] = tfilter
+	}
+	if f.Client != nil {
+		m["info.clientip"] = f.Client.String()
+	}
+	if f.Server != nil {
+		m["info.serverip"] = f.Server.String()
+	}
+	if f.SNI != "" {
+		m["clienthello.extensioninfo.sni"] = f.SNI
+	}
+	if f.JA3Digest != "" {
+		m["clienthello.ja3digest"] = f.JA3Digest
+	}
+	return m
+}
+
+// next token is composed by the start time in milliseconds (precision
+// used by mongodb) and the connection id. It is compatible with tlsmdb.
+func formatNext(start time.Time, id string) string {
+	ms := start.UnixNano() / int64(time.Millisecond)
+	return fmt.Sprintf("%d_%s", ms, id)
+}
+
+func parseNext(next string) (time.Time, string, error) {
+	fields := strings.SplitN(next, "_", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s'", next)
+	}
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid next '%s': %v", next, err)
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), fields[1], nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package tlsmongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/luids-io/archive/pkg/mongodriverutil"
+)
+
+func (a *Archiver) createIdx() error {
+	err := a.createIdxConnections()
+	if err != nil {
+		return err
+	}
+	err = a.createIdxCertificates()
+	if err != nil {
+		return err
+	}
+	return a.createIdxRecords()
+}
+
+func (a *Archiver) createIdxConnections() error {
+	return a.ensureIndexes(ConnectionColName, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "info.start", Value: 1}}},
+		{Keys: bson.D{{Key: "info.start", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "info.clientip", Value: 1}}},
+		{Keys: bson.D{{Key: "info.serverip", Value: 1}}},
+		{Keys: bson.D{{Key: "clienthello.extensioninfo.sni", Value: 1}}},
+		{Keys: bson.D{{Key: "clienthello.ja3digest", Value: 1}}},
+	})
+}
+
+func (a *Archiver) createIdxCertificates() error {
+	return a.ensureIndexes(CertificateColName, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "digest", Value: 1}}},
+	})
+}
+
+func (a *Archiver) createIdxRecords() error {
+	return a.ensureIndexes(RecordsColName, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "streamid", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: 1}}},
+	})
+}
+
+func (a *Archiver) ensureIndexes(name string, indexes []mongo.IndexModel) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongodriverutil.DefaultTimeout)
+	defer cancel()
+	_, err := a.getCollection(name).Indexes().CreateMany(ctx, indexes)
+	return err
+}
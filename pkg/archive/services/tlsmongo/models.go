@@ -0,0 +1,24 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package tlsmongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/luids-io/api/tlsutil"
+)
+
+// certificates are stored marshalling x509.Certificate, so only raw data is
+// decoded and then parsed
+type mdbCertificateData struct {
+	ID     string `bson:"id"`
+	Digest string `bson:"digest"`
+	Data   struct {
+		Raw []byte `bson:"raw"`
+	} `bson:"data"`
+}
+
+type mdbRecordData struct {
+	StorageID          primitive.ObjectID `bson:"_id"`
+	tlsutil.RecordData `bson:",inline"`
+}
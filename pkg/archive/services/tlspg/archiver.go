@@ -0,0 +1,312 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package tlspg implements tlsutil.Archive using postgres backend.
+//
+// This package is a work in progress and makes no API stability promises.
+package tlspg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+
+	"github.com/luids-io/api/tlsutil"
+	"github.com/luids-io/archive/pkg/archive"
+	"github.com/luids-io/archive/pkg/sqlutil"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ServiceClass registered.
+const ServiceClass = "tlspg"
+
+// Table names.
+const (
+	ConnectionTableName  = "connections"
+	CertificateTableName = "certificates"
+	RecordTableName      = "records"
+)
+
+// Default values.
+const (
+	DefaultConnsBulkSize        = 256
+	DefaultRecordsBulkSize      = 1024
+	DefaultSyncSeconds          = 5
+	DefaultCacheCertsExpiration = 30 * time.Minute
+	DefaultCacheCertsCleanUp    = 5 * time.Minute
+)
+
+// Archiver implements tls archive backend using a postgres database.
+type Archiver struct {
+	id     string
+	opts   options
+	logger yalogi.Logger
+	//database
+	db *sql.DB
+	//control
+	mu      sync.Mutex
+	started bool
+	close   chan struct{}
+	//bulks & caches
+	bulkConns   *sqlutil.Bulk
+	bulkRecords *sqlutil.Bulk
+	cacheCerts  *cache.Cache
+}
+
+// New creates a new storage.
+func New(id string, db *sql.DB, opt ...Option) *Archiver {
+	opts := defaultOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+	s := &Archiver{
+		id:     id,
+		opts:   opts,
+		logger: opts.logger,
+		db:     db,
+	}
+	return s
+}
+
+// Option encapsules options.
+type Option func(*options)
+
+type options struct {
+	logger          yalogi.Logger
+	connsBulkSize   int
+	recordsBulkSize int
+	syncSecs        int
+	prefix          string
+}
+
+var defaultOptions = options{
+	logger:          yalogi.LogNull,
+	connsBulkSize:   DefaultConnsBulkSize,
+	recordsBulkSize: DefaultRecordsBulkSize,
+	syncSecs:        DefaultSyncSeconds,
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// SetPrefix option allows set a prefix to tables.
+func SetPrefix(s string) Option {
+	return func(o *options) {
+		o.prefix = s
+	}
+}
+
+// Start the archiver.
+func (a *Archiver) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		return fmt.Errorf("archiver started")
+	}
+	a.logger.Infof("%s: starting postgres tls archiver", a.id)
+	//migrate schema
+	err := a.migrate()
+	if err != nil {
+		return err
+	}
+	//init bulks & caches
+	a.bulkConns = sqlutil.NewBulk(a.db,
+		a.getTableName(ConnectionTableName), connColumns,
+		a.opts.connsBulkSize,
+	)
+	a.bulkRecords = sqlutil.NewBulk(a.db,
+		a.getTableName(RecordTableName), recordColumns,
+		a.opts.recordsBulkSize,
+	)
+	a.cacheCerts = cache.New(DefaultCacheCertsExpiration, DefaultCacheCertsCleanUp)
+	//init control
+	a.close = make(chan struct{})
+	go a.doSync()
+	a.started = true
+	return nil
+}
+
+// SaveConnection implements tlsutil.Archiver interface.
+func (a *Archiver) SaveConnection(ctx context.Context, cn *tlsutil.ConnectionData) (string, error) {
+	if !a.started {
+		return "", tlsutil.ErrUnavailable
+	}
+	data, err := json.Marshal(cn)
+	if err != nil {
+		a.logger.Warnf("%s: saving connection '%s': encoding: %v", a.id, cn.ID, err)
+		return "", tlsutil.ErrBadRequest
+	}
+	var start, end, client, server, sni, ja3 interface{}
+	var clientPort, serverPort int
+	if cn.Info != nil {
+		start, end = cn.Info.Start, cn.Info.End
+		client, server = ipValue(cn.Info.ClientIP), ipValue(cn.Info.ServerIP)
+		clientPort, serverPort = cn.Info.ClientPort, cn.Info.ServerPort
+	}
+	if cn.ClientHello != nil {
+		ja3 = cn.ClientHello.JA3digest
+		if cn.ClientHello.ExtensionInfo != nil {
+			sni = cn.ClientHello.ExtensionInfo.SNI
+		}
+	}
+	err = a.bulkConns.Insert(cn.ID, start, end, client, clientPort, server, serverPort,
+		sni, ja3, string(data))
+	if err != nil {
+		a.logger.Warnf("%s: saving connection '%s': %v", a.id, cn.ID, err)
+		return "", dbError(err)
+	}
+	return cn.ID, nil
+}
+
+// SaveCertificate implements tlsutil.Archiver interface.
+func (a *Archiver) SaveCertificate(ctx context.Context, cert *tlsutil.CertificateData) (string, error) {
+	if !a.started {
+		return "", tlsutil.ErrUnavailable
+	}
+	// check in cache
+	ccert, ok := a.cacheCerts.Get(cert.Digest)
+	if ok {
+		cert, _ = ccert.(*tlsutil.CertificateData)
+		return cert.ID, nil
+	}
+	// insert if not exists
+	var raw []byte
+	if cert.Data != nil {
+		raw = cert.Data.Raw
+	}
+	query := fmt.Sprintf(`INSERT INTO %[1]s (id, digest, raw) VALUES ($1, $2, $3)
+		ON CONFLICT (digest) DO UPDATE SET digest = EXCLUDED.digest RETURNING id`,
+		a.getTableName(CertificateTableName))
+	var id string
+	err := a.db.QueryRowContext(ctx, query, cert.ID, cert.Digest, raw).Scan(&id)
+	if err != nil {
+		a.logger.Warnf("%s: saving cert '%s': %v", a.id, cert.Digest, err)
+		return "", dbError(err)
+	}
+	// add to cache with the stored id
+	if id != cert.ID {
+		cert = &tlsutil.CertificateData{ID: id, Digest: cert.Digest, Data: cert.Data}
+	}
+	a.cacheCerts.Add(cert.Digest, cert, cache.DefaultExpiration)
+	return id, nil
+}
+
+// StoreRecord implements tlsutil.Archiver interface.
+func (a *Archiver) StoreRecord(r *tlsutil.RecordData) error {
+	if !a.started {
+		return tlsutil.ErrUnavailable
+	}
+	err := a.bulkRecords.Insert(r.StreamID, r.Timestamp, int(r.Type), int(r.Len),
+		r.Ciphered, r.Fragmented, r.NumMsg)
+	if err != nil {
+		a.logger.Warnf("%s: saving record: %v", a.id, err)
+		return dbError(err)
+	}
+	return nil
+}
+
+// Shutdown flushes pending data, database is closed by the backend.
+func (a *Archiver) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.started {
+		a.logger.Infof("%s: shutting down tls archiver", a.id)
+		a.started = false
+		close(a.close)
+		errs := a.syncBulks()
+		for _, err := range errs {
+			a.logger.Warnf("%s: %v", a.id, err)
+		}
+	}
+	return
+}
+
+// Ping tests the connection with the storage.
+func (a *Archiver) Ping() error {
+	a.logger.Debugf("ping")
+	if !a.started {
+		return errors.New("archiver not started")
+	}
+	return a.db.Ping()
+}
+
+func (a *Archiver) doSync() {
+	tick := time.NewTicker(time.Duration(a.opts.syncSecs) * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			errs := a.syncBulks()
+			for _, err := range errs {
+				a.logger.Warnf("%s: %v", a.id, err)
+			}
+		case <-a.close:
+			return
+		}
+	}
+}
+
+func (a *Archiver) syncBulks() []error {
+	errs := make([]error, 0, 2)
+	var err error
+	err = a.bulkConns.Flush()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sync connections: %v", err))
+	}
+	err = a.bulkRecords.Flush()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sync records: %v", err))
+	}
+	return errs
+}
+
+func (a *Archiver) getTableName(name string) string {
+	if a.opts.prefix != "" {
+		name = a.opts.prefix + "_" + name
+	}
+	return name
+}
+
+// dbError returns the api error for a database error.
+func dbError(err error) error {
+	if err == sqlutil.ErrBulkFull || sqlutil.IsUnavailable(err) {
+		return tlsutil.ErrUnavailable
+	}
+	return tlsutil.ErrInternal
+}
+
+// ID implements archive.Service interface.
+func (a *Archiver) ID() string {
+	return a.id
+}
+
+// Class implements archive.Service interface.
+func (a *Archiver) Class() string {
+	return ServiceClass
+}
+
+// Implements implements archive.Service interface.
+func (a *Archiver) Implements() []archive.API {
+	return []archive.API{archive.TLSAPI}
+}
+
+func ipValue(s string) interface{} {
+	if net.ParseIP(s) == nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package tlspg
+
+import (
+	"fmt"
+
+	"github.com/luids-io/archive/pkg/sqlutil"
+)
+
+// connColumns used in bulk inserts.
+var connColumns = []string{
+	"id", "start", "end_", "client_ip", "client_port", "server_ip", "server_port",
+	"sni", "ja3_digest", "data",
+}
+
+// recordColumns used in bulk inserts.
+var recordColumns = []string{
+	"stream_id", "timestamp", "type", "len", "ciphered", "fragmented", "num_msg",
+}
+
+// tlsMigrations returns schema migrations, indexes are equivalent to
+// tlsmdb indexes.
+func tlsMigrations(conns, certs, records string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+			id TEXT PRIMARY KEY,
+			start TIMESTAMPTZ,
+			end_ TIMESTAMPTZ,
+			client_ip INET,
+			client_port INTEGER,
+			server_ip INET,
+			server_port INTEGER,
+			sni TEXT,
+			ja3_digest TEXT,
+			data JSONB NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS %[1]s_start_idx ON %[1]s (start);
+		CREATE INDEX IF NOT EXISTS %[1]s_client_ip_idx ON %[1]s (client_ip);
+		CREATE INDEX IF NOT EXISTS %[1]s_server_ip_idx ON %[1]s (server_ip);
+		CREATE INDEX IF NOT EXISTS %[1]s_sni_idx ON %[1]s (sni);
+		CREATE INDEX IF NOT EXISTS %[1]s_ja3_digest_idx ON %[1]s (ja3_digest);
+		CREATE TABLE IF NOT EXISTS %[2]s (
+			id TEXT PRIMARY KEY,
+			digest TEXT NOT NULL UNIQUE,
+			raw BYTEA
+		);
+		CREATE TABLE IF NOT EXISTS %[3]s (
+			sid BIGSERIAL PRIMARY KEY,
+			stream_id TEXT NOT NULL,
+			timestamp TIMESTAMPTZ NOT NULL,
+			type INTEGER NOT NULL,
+			len INTEGER NOT NULL,
+			ciphered BOOLEAN NOT NULL,
+			fragmented BOOLEAN NOT NULL,
+			num_msg INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS %[3]s_stream_id_idx ON %[3]s (stream_id);
+		CREATE INDEX IF NOT EXISTS %[3]s_timestamp_idx ON %[3]s (timestamp);`, conns, certs, records),
+	}
+}
+
+func (a *Archiver) migrate() error {
+	return sqlutil.Migrate(a.db, a.getTableName(ConnectionTableName),
+		tlsMigrations(
+			a.getTableName(ConnectionTableName),
+			a.getTableName(CertificateTableName),
+			a.getTableName(RecordTableName),
+		))
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// OptionType defines the type of the value of an option.
+type OptionType string
+
+// List of option types.
+const (
+	OptString   OptionType = "string"
+	OptInt      OptionType = "integer"
+	OptBool     OptionType = "boolean"
+	OptStrings  OptionType = "strings"
+	OptServices OptionType = "services" // list of service ids
+)
+
+// OptionSpec declares an option accepted by a class.
+type OptionSpec struct {
+	Name        string
+	Type        OptionType
+	Required    bool
+	Enum        []string
+	Description string
+}
+
+// BackendSpec declares the definition accepted by a backend class.
+type BackendSpec struct {
+	// URL is required
+	URL bool
+	// TLS client configuration is accepted
+	TLS     bool
+	Options []OptionSpec
+	// Exclusive is true if instances hold resources, like files or locks,
+	// that can't be shared with another instance of the same definition
+	Exclusive bool
+}
+
+// ServiceSpec declares the definition accepted by a service class.
+type ServiceSpec struct {
+	// Backends stores the accepted backend classes, if empty the service
+	// doesn't use a backend
+	Backends   []string
+	Implements []API
+	Options    []OptionSpec
+	// Exclusive is true if instances hold resources, like files or locks,
+	// that can't be shared with another instance of the same definition
+	Exclusive bool
+}
+
+// RegisterBackendSpec registers the spec of a backend class.
+func RegisterBackendSpec(class string, spec BackendSpec) {
+	regBackendSpec[class] = spec
+}
+
+// RegisterServiceSpec registers the spec of a service class.
+func RegisterServiceSpec(class string, spec ServiceSpec) {
+	regServiceSpec[class] = spec
+}
+
+var regBackendSpec = make(map[string]BackendSpec)
+var regServiceSpec = make(map[string]ServiceSpec)
+
+// ValidateDefs checks the definitions without building them. It checks
+// ids, classes, options and the references between services and backends.
+// It returns a MultiError with all errors found.
+func ValidateDefs(backends []BackendDef, services []ServiceDef) error {
+	var errs MultiError
+	add := func(r ref, err error) {
+		errs = append(errs, newComponentError("validate", r, err))
+	}
+	backendClass := make(map[string]string)
+	for _, def := range backends {
+		r := ref{id: def.ID}
+		if def.ID == "" {
+			add(r, errors.New("id field is required"))
+			continue
+		}
+		if _, ok := backendClass[def.ID]; ok {
+			add(r, errors.New("duplicated id"))
+			continue
+		}
+		if def.Disabled {
+			backendClass[def.ID] = ""
+			continue
+		}
+		backendClass[def.ID] = def.Class
+		if _, ok := regBackendBuilder[def.Class]; !ok {
+			add(r, fmt.Errorf("class '%s' not registered", def.Class))
+			continue
+		}
+		spec, ok := regBackendSpec[def.Class]
+		if !ok {
+			continue
+		}
+		if spec.URL && def.URL == "" {
+			add(r, errors.New("'url' is required"))
+		}
+		if !spec.TLS && def.Client != nil {
+			add(r, errors.New("'tls' is not supported"))
+		}
+		for _, err := range validateOpts(spec.Options, def.Opts) {
+			add(r, err)
+		}
+	}
+	// services must reference services defined before
+	serviceAPIs := make(map[string][]API)
+	for _, def := range services {
+		r := ref{service: true, id: def.ID}
+		if def.ID == "" {
+			add(r, errors.New("id field is required"))
+			continue
+		}
+		if _, ok := serviceAPIs[def.ID]; ok {
+			add(r, errors.New("duplicated id"))
+			continue
+		}
+		if def.Disabled {
+			serviceAPIs[def.ID] = nil
+			continue
+		}
+		serviceAPIs[def.ID] = []API{}
+		if _, ok := regServiceBuilder[def.Class]; !ok {
+			add(r, fmt.Errorf("class '%s' not registered", def.Class))
+			continue
+		}
+		spec, ok := regServiceSpec[def.Class]
+		if !ok {
+			continue
+		}
+		serviceAPIs[def.ID] = spec.Implements
+		if len(spec.Backends) > 0 {
+			class, ok := backendClass[def.Backend]
+			switch {
+			case def.Backend == "":
+				add(r, errors.New("'backend' is required"))
+			case !ok:
+				add(r, fmt.Errorf("'backend' '%s' not found", def.Backend))
+			case class == "":
+				add(r, fmt.Errorf("'backend' '%s' is disabled", def.Backend))
+			case !contains(spec.Backends, class):
+				add(r, fmt.Errorf("'backend' class '%s' not supported", class))
+			}
+		} else if def.Backend != "" {
+			add(r, errors.New("'backend' is not supported"))
+		}
+		for _, err := range validateOpts(spec.Options, def.Opts) {
+			add(r, err)
+		}
+		for _, o := range spec.Options {
+			if o.Type != OptServices {
+				continue
+			}
+			for _, id := range toStrings(def.Opts[o.Name]) {
+				apis, ok := serviceAPIs[id]
+				switch {
+				case id == def.ID:
+					add(r, fmt.Errorf("'%s' can't reference itself", o.Name))
+				case !ok:
+					add(r, fmt.Errorf("'%s': '%s' not found", o.Name, id))
+				case apis == nil:
+					add(r, fmt.Errorf("'%s': '%s' is disabled", o.Name, id))
+				}
+			}
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// ServiceAPIs returns the apis implemented by the services as declared in
+// the specs of their classes. Composite services, declared without apis,
+// implement the apis common to the services they reference.
+func ServiceAPIs(services []ServiceDef) map[string][]API {
+	apis := make(map[string][]API, len(services))
+	for _, def := range services {
+		if def.Disabled {
+			continue
+		}
+		spec, ok := regServiceSpec[def.Class]
+		if !ok {
+			continue
+		}
+		if len(spec.Implements) > 0 {
+			apis[def.ID] = spec.Implements
+			continue
+		}
+		var common []API
+		first := true
+		for _, o := range spec.Options {
+			if o.Type != OptServices {
+				continue
+			}
+			for _, id := range toStrings(def.Opts[o.Name]) {
+				if first {
+					common, first = apis[id], false
+					continue
+				}
+				common = intersectAPIs(common, apis[id])
+			}
+		}
+		apis[def.ID] = common
+	}
+	return apis
+}
+
+func intersectAPIs(a, b []API) []API {
+	ret := make([]API, 0, len(a))
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				ret = append(ret, x)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func validateOpts(specs []OptionSpec, opts map[string]interface{}) []error {
+	errs := make([]error, 0)
+	declared := make(map[string]OptionSpec, len(specs))
+	for _, s := range specs {
+		declared[s.Name] = s
+		if _, ok := opts[s.Name]; s.Required && !ok {
+			errs = append(errs, fmt.Errorf("'opts.%s' is required", s.Name))
+		}
+	}
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s, ok := declared[k]
+		if !ok {
+			errs = append(errs, fmt.Errorf("'opts.%s' is unknown", k))
+			continue
+		}
+		err := s.check(opts[k])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("'opts.%s': %v", k, err))
+		}
+	}
+	return errs
+}
+
+func (s OptionSpec) check(v interface{}) error {
+	switch s.Type {
+	case OptString:
+		str, ok := v.(string)
+		if !ok {
+			return errors.New("string expected")
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return fmt.Errorf("'%s' is not one of %v", str, s.Enum)
+		}
+	case OptInt:
+		switch n := v.(type) {
+		case int:
+		case float64:
+			if n != float64(int(n)) {
+				return errors.New("integer expected")
+			}
+		default:
+			return errors.New("integer expected")
+		}
+	case OptBool:
+		if _, ok := v.(bool); !ok {
+			return errors.New("boolean expected")
+		}
+	case OptStrings, OptServices:
+		switch list := v.(type) {
+		case []string:
+		case []interface{}:
+			for _, item := range list {
+				if _, ok := item.(string); !ok {
+					return errors.New("list of strings expected")
+				}
+			}
+		default:
+			return errors.New("list of strings expected")
+		}
+	}
+	return nil
+}
+
+func toStrings(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		ret := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
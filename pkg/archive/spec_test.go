@@ -0,0 +1,186 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package archive
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	RegisterBackendBuilder("test-spec-db", func(b *Builder, def BackendDef) (Backend, error) {
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+	RegisterBackendSpec("test-spec-db", BackendSpec{
+		URL: true,
+		Options: []OptionSpec{
+			{Name: "size", Type: OptInt},
+			{Name: "mode", Type: OptString, Enum: []string{"sync", "async"}},
+		},
+	})
+	RegisterBackendBuilder("test-spec-other", func(b *Builder, def BackendDef) (Backend, error) {
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+	RegisterServiceBuilder("test-spec-svc", func(b *Builder, def ServiceDef) (Service, error) {
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+	RegisterServiceSpec("test-spec-svc", ServiceSpec{
+		Backends:   []string{"test-spec-db"},
+		Implements: []API{DNSAPI},
+		Options: []OptionSpec{
+			{Name: "prefix", Type: OptString, Required: true},
+			{Name: "sync", Type: OptBool},
+			{Name: "tags", Type: OptStrings},
+		},
+	})
+	RegisterServiceBuilder("test-spec-multi", func(b *Builder, def ServiceDef) (Service, error) {
+		return &testItem{id: def.ID, class: def.Class}, nil
+	})
+	RegisterServiceSpec("test-spec-multi", ServiceSpec{
+		Options: []OptionSpec{{Name: "services", Type: OptServices, Required: true}},
+	})
+}
+
+// validationErrors returns the errors of the components as strings.
+func validationErrors(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var merr MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("ValidateDefs() returned %T, want MultiError", err)
+	}
+	ret := make([]string, 0, len(merr))
+	for _, e := range merr {
+		ret = append(ret, e.Error())
+	}
+	return ret
+}
+
+func TestValidateDefs(t *testing.T) {
+	db := BackendDef{ID: "db", Class: "test-spec-db", URL: "test://db"}
+	other := BackendDef{ID: "other", Class: "test-spec-other"}
+	svc := ServiceDef{ID: "svc", Class: "test-spec-svc", Backend: "db",
+		Opts: map[string]interface{}{"prefix": "test"}}
+	tests := []struct {
+		name     string
+		backends []BackendDef
+		services []ServiceDef
+		want     []string
+	}{
+		{"valid", []BackendDef{db, other}, []ServiceDef{svc}, nil},
+		{"valid opts",
+			[]BackendDef{{ID: "db", Class: "test-spec-db", URL: "test://db",
+				Opts: map[string]interface{}{"size": float64(10), "mode": "async"}}},
+			[]ServiceDef{{ID: "svc", Class: "test-spec-svc", Backend: "db",
+				Opts: map[string]interface{}{"prefix": "test", "sync": true, "tags": []interface{}{"a", "b"}}}},
+			nil},
+		{"missing id", []BackendDef{{Class: "test-spec-db"}}, []ServiceDef{{Class: "test-spec-svc"}},
+			[]string{"validate: id field is required", "validate: id field is required"}},
+		{"duplicated backend", []BackendDef{db, db}, nil,
+			[]string{"validate backend 'db': duplicated id"}},
+		{"duplicated service", []BackendDef{db}, []ServiceDef{svc, svc},
+			[]string{"validate service 'svc': duplicated id"}},
+		{"unregistered backend class", []BackendDef{{ID: "db", Class: "unknown"}}, nil,
+			[]string{"validate backend 'db': class 'unknown' not registered"}},
+		{"unregistered service class", nil, []ServiceDef{{ID: "svc", Class: "unknown"}},
+			[]string{"validate service 'svc': class 'unknown' not registered"}},
+		{"missing url", []BackendDef{{ID: "db", Class: "test-spec-db"}}, nil,
+			[]string{"validate backend 'db': 'url' is required"}},
+		{"missing backend", nil,
+			[]ServiceDef{{ID: "svc", Class: "test-spec-svc", Opts: svc.Opts}},
+			[]string{"validate service 'svc': 'backend' is required"}},
+		{"backend not found", []BackendDef{db},
+			[]ServiceDef{{ID: "svc", Class: "test-spec-svc", Backend: "nodb", Opts: svc.Opts}},
+			[]string{"validate service 'svc': 'backend' 'nodb' not found"}},
+		{"backend disabled", []BackendDef{{ID: "db", Class: "test-spec-db", Disabled: true}}, []ServiceDef{svc},
+			[]string{"validate service 'svc': 'backend' 'db' is disabled"}},
+		{"backend wrong class", []BackendDef{other},
+			[]ServiceDef{{ID: "svc", Class: "test-spec-svc", Backend: "other", Opts: svc.Opts}},
+			[]string{"validate service 'svc': 'backend' class 'test-spec-other' not supported"}},
+		{"backend not supported", []BackendDef{db},
+			[]ServiceDef{svc, {ID: "multi", Class: "test-spec-multi", Backend: "db",
+				Opts: map[string]interface{}{"services": []interface{}{"svc"}}}},
+			[]string{"validate service 'multi': 'backend' is not supported"}},
+		{"service references", []BackendDef{db},
+			[]ServiceDef{
+				{ID: "multi", Class: "test-spec-multi",
+					Opts: map[string]interface{}{"services": []interface{}{"multi", "svc", "off"}}},
+				svc,
+				{ID: "off", Class: "test-spec-svc", Disabled: true},
+				{ID: "multi2", Class: "test-spec-multi",
+					Opts: map[string]interface{}{"services": []interface{}{"svc", "off"}}},
+			},
+			[]string{
+				"validate service 'multi': 'services' can't reference itself",
+				"validate service 'multi': 'services': 'svc' not found",
+				"validate service 'multi': 'services': 'off' not found",
+				"validate service 'multi2': 'services': 'off' is disabled",
+			}},
+		{"opts", []BackendDef{{ID: "db", Class: "test-spec-db", URL: "test://db",
+			Opts: map[string]interface{}{"size": "10", "mode": "batch", "extra": 1}}},
+			[]ServiceDef{{ID: "svc", Class: "test-spec-svc", Backend: "db"}},
+			[]string{
+				"validate backend 'db': 'opts.extra' is unknown",
+				"validate backend 'db': 'opts.mode': 'batch' is not one of [sync async]",
+				"validate backend 'db': 'opts.size': integer expected",
+				"validate service 'svc': 'opts.prefix' is required",
+			}},
+	}
+	for _, tt := range tests {
+		got := validationErrors(t, ValidateDefs(tt.backends, tt.services))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ValidateDefs() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOpts(t *testing.T) {
+	specs := []OptionSpec{
+		{Name: "str", Type: OptString},
+		{Name: "enum", Type: OptString, Enum: []string{"a", "b"}},
+		{Name: "int", Type: OptInt},
+		{Name: "bool", Type: OptBool},
+		{Name: "list", Type: OptStrings},
+		{Name: "svcs", Type: OptServices},
+	}
+	tests := []struct {
+		key   string
+		value interface{}
+		want  string
+	}{
+		{"str", "value", ""},
+		{"str", 1, "'opts.str': string expected"},
+		{"enum", "b", ""},
+		{"enum", "c", "'opts.enum': 'c' is not one of [a b]"},
+		{"enum", true, "'opts.enum': string expected"},
+		{"int", 10, ""},
+		{"int", float64(10), ""},
+		{"int", 1.5, "'opts.int': integer expected"},
+		{"int", "10", "'opts.int': integer expected"},
+		{"bool", false, ""},
+		{"bool", "true", "'opts.bool': boolean expected"},
+		{"list", []string{"a"}, ""},
+		{"list", []interface{}{"a", "b"}, ""},
+		{"list", []interface{}{"a", 1}, "'opts.list': list of strings expected"},
+		{"list", "a", "'opts.list': list of strings expected"},
+		{"svcs", []interface{}{"s1"}, ""},
+		{"svcs", map[string]interface{}{}, "'opts.svcs': list of strings expected"},
+		{"unknown", "x", "'opts.unknown' is unknown"},
+	}
+	for _, tt := range tests {
+		errs := validateOpts(specs, map[string]interface{}{tt.key: tt.value})
+		var got string
+		if len(errs) > 1 {
+			t.Errorf("validateOpts(%s=%v) returned %v errors", tt.key, tt.value, len(errs))
+		}
+		if len(errs) > 0 {
+			got = errs[0].Error()
+		}
+		if got != tt.want {
+			t.Errorf("validateOpts(%s=%v) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package dnsfilter implements in memory matching of dnsutil.ResolvsFilter.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsfilter
+
+import (
+	"github.com/luids-io/api/dnsutil"
+)
+
+// Match returns true if resolv matches any of the filters. Filters are
+// OR-ed, an empty slice matches everything.
+func Match(r dnsutil.ResolvData, filters []dnsutil.ResolvsFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if MatchFilter(r, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFilter returns true if resolv matches all the fields of the filter.
+func MatchFilter(r dnsutil.ResolvData, f dnsutil.ResolvsFilter) bool {
+	if !f.Since.IsZero() && !r.Timestamp.After(f.Since) {
+		return false
+	}
+	if !f.To.IsZero() && !r.Timestamp.Before(f.To) {
+		return false
+	}
+	if f.Client != nil && !f.Client.Equal(r.Client) {
+		return false
+	}
+	if f.Server != nil && !f.Server.Equal(r.Server) {
+		return false
+	}
+	if f.Name != "" && f.Name != r.Name {
+		return false
+	}
+	if f.ResolvedIP != nil {
+		found := false
+		for _, ip := range r.ResolvedIPs {
+			if f.ResolvedIP.Equal(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ResolvedCNAME != "" {
+		found := false
+		for _, cname := range r.ResolvedCNAMEs {
+			if f.ResolvedCNAME == cname {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.QID > 0 && f.QID != int(r.QID) {
+		return false
+	}
+	if f.ReturnCode > 0 && f.ReturnCode != r.ReturnCode {
+		return false
+	}
+	if f.TLD != "" && f.TLD != r.TLD {
+		return false
+	}
+	if f.TLDPlusOne != "" && f.TLDPlusOne != r.TLDPlusOne {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"fmt"
+	"net"
+
+	dnsencoding "github.com/luids-io/api/dnsutil/grpc/encoding"
+	dnspb "github.com/luids-io/api/dnsutil/grpc/pb"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	"github.com/luids-io/archive/pkg/dnsquery/grpc/pb"
+)
+
+// Filter copy info from pb
+func Filter(src *pb.Filter, dst *dnsquery.Filter) (err error) {
+	if src.GetBase() != nil {
+		err = dnsencoding.ResolvsFilter(src.GetBase(), &dst.ResolvsFilter)
+		if err != nil {
+			return
+		}
+	}
+	if src.GetClientNet() != "" {
+		_, dst.ClientNet, err = net.ParseCIDR(src.GetClientNet())
+		if err != nil {
+			return fmt.Errorf("client net: %v", err)
+		}
+	}
+	if src.GetServerNet() != "" {
+		_, dst.ServerNet, err = net.ParseCIDR(src.GetServerNet())
+		if err != nil {
+			return fmt.Errorf("server net: %v", err)
+		}
+	}
+	if src.GetResolvedNet() != "" {
+		_, dst.ResolvedNet, err = net.ParseCIDR(src.GetResolvedNet())
+		if err != nil {
+			return fmt.Errorf("resolved net: %v", err)
+		}
+	}
+	dst.NamePattern = src.GetNamePattern()
+	dst.NameRegex = src.GetNameRegex()
+	dst.Domain = src.GetDomain()
+	return
+}
+
+// FilterPB copy info to pb
+func FilterPB(src *dnsquery.Filter, dst *pb.Filter) (err error) {
+	dst.Base = &dnspb.ResolvsFilter{}
+	err = dnsencoding.ResolvsFilterPB(&src.ResolvsFilter, dst.Base)
+	if err != nil {
+		return
+	}
+	if src.ClientNet != nil {
+		dst.ClientNet = src.ClientNet.String()
+	}
+	if src.ServerNet != nil {
+		dst.ServerNet = src.ServerNet.String()
+	}
+	if src.ResolvedNet != nil {
+		dst.ResolvedNet = src.ResolvedNet.String()
+	}
+	dst.NamePattern = src.NamePattern
+	dst.NameRegex = src.NameRegex
+	dst.Domain = src.Domain
+	return
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsencoding "github.com/luids-io/api/dnsutil/grpc/encoding"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	"github.com/luids-io/archive/pkg/dnsquery/grpc/encoding"
+	"github.com/luids-io/archive/pkg/dnsquery/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Client provides a grpc client.
+type Client struct {
+	logger yalogi.Logger
+	//grpc connection
+	conn   *grpcdns.Conn
+	client pb.FinderClient
+}
+
+// ClientOption encapsules options for client.
+type ClientOption = grpcdns.ClientOption
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return grpcdns.CloseConnection(b)
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return grpcdns.SetLogger(l)
+}
+
+// NewClient returns a new client.
+func NewClient(conn *grpc.ClientConn, opt ...ClientOption) *Client {
+	opts := grpcdns.NewClientOpts(opt...)
+	return &Client{
+		logger: opts.Logger,
+		conn:   grpcdns.NewConn(conn, opts),
+		client: pb.NewFinderClient(conn),
+	}
+}
+
+// QueryResolvs implements dnsquery.Finder interface
+func (c *Client) QueryResolvs(ctx context.Context, filters []dnsquery.Filter,
+	rev bool, max int, next string) ([]dnsutil.ResolvData, string, error) {
+	if c.conn.Closed() {
+		c.logger.Warnf("client.dnsutil.query: queryresolvs(): client is closed")
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	if max < 0 {
+		c.logger.Warnf("client.dnsutil.query: queryresolvs(): invalid max")
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	//create request
+	req := &pb.QueryResolvsRequest{
+		Max:     int32(max),
+		Next:    next,
+		Reverse: rev,
+		Filters: make([]*pb.Filter, 0, len(filters)),
+	}
+	for _, f := range filters {
+		fpb := &pb.Filter{}
+		err := encoding.FilterPB(&f, fpb)
+		if err != nil {
+			c.logger.Warnf("client.dnsutil.query: queryresolvs(): bad filter: %v", err)
+			return nil, "", dnsutil.ErrBadRequest
+		}
+		req.Filters = append(req.Filters, fpb)
+	}
+	//do query
+	resp, err := c.client.QueryResolvs(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.dnsutil.query: queryresolvs(): %v", err)
+		return nil, "", grpcdns.ClientError(err)
+	}
+	//process response
+	data := make([]dnsutil.ResolvData, 0, len(resp.GetData()))
+	for _, rpb := range resp.GetData() {
+		var r dnsutil.ResolvData
+		err := dnsencoding.ResolvData(rpb, &r)
+		if err != nil {
+			c.logger.Errorf("client.dnsutil.query: queryresolvs(): decoding resolv: %v", err)
+			return nil, "", dnsutil.ErrInternal
+		}
+		data = append(data, r)
+	}
+	return data, resp.GetNext(), nil
+}
+
+// FollowResolvs implements dnsquery.Follower interface
+func (c *Client) FollowResolvs(ctx context.Context, filters []dnsquery.Filter, fn func(dnsutil.ResolvData) error) error {
+	if c.conn.Closed() {
+		c.logger.Warnf("client.dnsutil.query: followresolvs(): client is closed")
+		return dnsutil.ErrUnavailable
+	}
+	//create request
+	req := &pb.FollowResolvsRequest{Filters: make([]*pb.Filter, 0, len(filters))}
+	for _, f := range filters {
+		fpb := &pb.Filter{}
+		err := encoding.FilterPB(&f, fpb)
+		if err != nil {
+			c.logger.Warnf("client.dnsutil.query: followresolvs(): bad filter: %v", err)
+			return dnsutil.ErrBadRequest
+		}
+		req.Filters = append(req.Filters, fpb)
+	}
+	//do follow
+	stream, err := c.client.FollowResolvs(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.dnsutil.query: followresolvs(): %v", err)
+		return grpcdns.ClientError(err)
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			c.logger.Warnf("client.dnsutil.query: followresolvs(): %v", err)
+			return grpcdns.ClientError(err)
+		}
+		var r dnsutil.ResolvData
+		err = dnsencoding.ResolvData(resp.GetData(), &r)
+		if err != nil {
+			c.logger.Errorf("client.dnsutil.query: followresolvs(): decoding resolv: %v", err)
+			return dnsutil.ErrInternal
+		}
+		err = fn(r)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Ping checks connectivity with the api
+func (c *Client) Ping() error {
+	return c.conn.Ping()
+}
+
+// API returns API service name implemented
+func (c *Client) API() string {
+	return ServiceName()
+}
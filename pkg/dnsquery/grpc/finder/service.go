@@ -0,0 +1,125 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsencoding "github.com/luids-io/api/dnsutil/grpc/encoding"
+	dnspb "github.com/luids-io/api/dnsutil/grpc/pb"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/dnsquery"
+	"github.com/luids-io/archive/pkg/dnsquery/grpc/encoding"
+	"github.com/luids-io/archive/pkg/dnsquery/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Service implements a grpc service wrapper.
+type Service struct {
+	logger yalogi.Logger
+	finder dnsquery.Finder
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption = grpcdns.ServiceOption
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return grpcdns.SetServiceLogger(l)
+}
+
+// NewService returns a new Service.
+func NewService(f dnsquery.Finder, opt ...ServiceOption) *Service {
+	opts := grpcdns.NewServiceOpts(opt...)
+	return &Service{finder: f, logger: opts.Logger}
+}
+
+// RegisterServer registers a service in the grpc server.
+func RegisterServer(server *grpc.Server, service *Service) {
+	pb.RegisterFinderServer(server, service)
+}
+
+// QueryResolvs implements grpc interface.
+func (s *Service) QueryResolvs(ctx context.Context, req *pb.QueryResolvsRequest) (*pb.QueryResolvsResponse, error) {
+	// get request
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.dnsutil.query: [peer=%s] queryresolvs(): bad max", grpcdns.PeerAddr(ctx))
+		return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+	}
+	filters := make([]dnsquery.Filter, 0, len(req.GetFilters()))
+	for _, fpb := range req.GetFilters() {
+		var f dnsquery.Filter
+		err := encoding.Filter(fpb, &f)
+		if err != nil {
+			s.logger.Warnf("service.dnsutil.query: [peer=%s] queryresolvs(): bad filter: %v", grpcdns.PeerAddr(ctx), err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+		}
+		filters = append(filters, f)
+	}
+	//do query
+	data, next, err := s.finder.QueryResolvs(ctx, filters, req.GetReverse(), max, req.GetNext())
+	if err != nil {
+		s.logger.Warnf("service.dnsutil.query: [peer=%s] queryresolvs(): %v", grpcdns.PeerAddr(ctx), err)
+		return nil, grpcdns.ServiceError(err)
+	}
+	//prepare response
+	resp := &pb.QueryResolvsResponse{
+		Next: next,
+		Data: make([]*dnspb.ResolvData, 0, len(data)),
+	}
+	for _, r := range data {
+		rpb := &dnspb.ResolvData{}
+		err := dnsencoding.ResolvDataPB(&r, rpb)
+		if err != nil {
+			s.logger.Errorf("service.dnsutil.query: [peer=%s] queryresolvs(): encoding resolv: %v", grpcdns.PeerAddr(ctx), err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrInternal)
+		}
+		resp.Data = append(resp.Data, rpb)
+	}
+	return resp, nil
+}
+
+// FollowResolvs implements grpc interface.
+func (s *Service) FollowResolvs(req *pb.FollowResolvsRequest, stream pb.Finder_FollowResolvsServer) error {
+	ctx := stream.Context()
+	follower, ok := s.finder.(dnsquery.Follower)
+	if !ok {
+		s.logger.Warnf("service.dnsutil.query: [peer=%s] followresolvs(): not supported", grpcdns.PeerAddr(ctx))
+		return grpcdns.ServiceError(dnsutil.ErrNotSupported)
+	}
+	filters := make([]dnsquery.Filter, 0, len(req.GetFilters()))
+	for _, fpb := range req.GetFilters() {
+		var f dnsquery.Filter
+		err := encoding.Filter(fpb, &f)
+		if err != nil {
+			s.logger.Warnf("service.dnsutil.query: [peer=%s] followresolvs(): bad filter: %v", grpcdns.PeerAddr(ctx), err)
+			return grpcdns.ServiceError(dnsutil.ErrBadRequest)
+		}
+		filters = append(filters, f)
+	}
+	//do follow
+	var sendErr error
+	err := follower.FollowResolvs(ctx, filters, func(r dnsutil.ResolvData) error {
+		rpb := &dnspb.ResolvData{}
+		err := dnsencoding.ResolvDataPB(&r, rpb)
+		if err != nil {
+			s.logger.Errorf("service.dnsutil.query: [peer=%s] followresolvs(): encoding resolv: %v", grpcdns.PeerAddr(ctx), err)
+			return dnsutil.ErrInternal
+		}
+		sendErr = stream.Send(&pb.FollowResolvsResponse{Data: rpb})
+		return sendErr
+	})
+	if sendErr != nil {
+		// stream closed by peer
+		return sendErr
+	}
+	if err != nil {
+		s.logger.Warnf("service.dnsutil.query: [peer=%s] followresolvs(): %v", grpcdns.PeerAddr(ctx), err)
+		return grpcdns.ServiceError(err)
+	}
+	return nil
+}
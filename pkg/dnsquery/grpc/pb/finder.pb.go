@@ -0,0 +1,692 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.23.0
+// 	protoc        v3.6.1
+// source: github.com/luids-io/archive/schemas/dnsquery/finder.proto
+
+package pb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	pb "github.com/luids-io/api/dnsutil/grpc/pb"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type Filter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Base        *pb.ResolvsFilter `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	ClientNet   string            `protobuf:"bytes,2,opt,name=client_net,json=clientNet,proto3" json:"client_net,omitempty"`
+	ServerNet   string            `protobuf:"bytes,3,opt,name=server_net,json=serverNet,proto3" json:"server_net,omitempty"`
+	NamePattern string            `protobuf:"bytes,4,opt,name=name_pattern,json=namePattern,proto3" json:"name_pattern,omitempty"`
+	ResolvedNet string            `protobuf:"bytes,5,opt,name=resolved_net,json=resolvedNet,proto3" json:"resolved_net,omitempty"`
+	NameRegex   string            `protobuf:"bytes,6,opt,name=name_regex,json=nameRegex,proto3" json:"name_regex,omitempty"`
+	Domain      string            `protobuf:"bytes,7,opt,name=domain,proto3" json:"domain,omitempty"`
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Filter) GetBase() *pb.ResolvsFilter {
+	if x != nil {
+		return x.Base
+	}
+	return nil
+}
+
+func (x *Filter) GetClientNet() string {
+	if x != nil {
+		return x.ClientNet
+	}
+	return ""
+}
+
+func (x *Filter) GetServerNet() string {
+	if x != nil {
+		return x.ServerNet
+	}
+	return ""
+}
+
+func (x *Filter) GetNamePattern() string {
+	if x != nil {
+		return x.NamePattern
+	}
+	return ""
+}
+
+func (x *Filter) GetResolvedNet() string {
+	if x != nil {
+		return x.ResolvedNet
+	}
+	return ""
+}
+
+func (x *Filter) GetNameRegex() string {
+	if x != nil {
+		return x.NameRegex
+	}
+	return ""
+}
+
+func (x *Filter) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type QueryResolvsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Max     int32     `protobuf:"varint,1,opt,name=max,proto3" json:"max,omitempty"`
+	Next    string    `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	Filters []*Filter `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty"`
+	Reverse bool      `protobuf:"varint,4,opt,name=reverse,proto3" json:"reverse,omitempty"`
+}
+
+func (x *QueryResolvsRequest) Reset() {
+	*x = QueryResolvsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryResolvsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResolvsRequest) ProtoMessage() {}
+
+func (x *QueryResolvsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResolvsRequest.ProtoReflect.Descriptor instead.
+func (*QueryResolvsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *QueryResolvsRequest) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *QueryResolvsRequest) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+func (x *QueryResolvsRequest) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *QueryResolvsRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+type QueryResolvsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []*pb.ResolvData `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	Next string           `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+}
+
+func (x *QueryResolvsResponse) Reset() {
+	*x = QueryResolvsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryResolvsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResolvsResponse) ProtoMessage() {}
+
+func (x *QueryResolvsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResolvsResponse.ProtoReflect.Descriptor instead.
+func (*QueryResolvsResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueryResolvsResponse) GetData() []*pb.ResolvData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *QueryResolvsResponse) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+type FollowResolvsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filters []*Filter `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (x *FollowResolvsRequest) Reset() {
+	*x = FollowResolvsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FollowResolvsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowResolvsRequest) ProtoMessage() {}
+
+func (x *FollowResolvsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowResolvsRequest.ProtoReflect.Descriptor instead.
+func (*FollowResolvsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FollowResolvsRequest) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type FollowResolvsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data *pb.ResolvData `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *FollowResolvsResponse) Reset() {
+	*x = FollowResolvsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FollowResolvsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowResolvsResponse) ProtoMessage() {}
+
+func (x *FollowResolvsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowResolvsResponse.ProtoReflect.Descriptor instead.
+func (*FollowResolvsResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FollowResolvsResponse) GetData() *pb.ResolvData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_github_com_luids_io_archive_schemas_dnsquery_finder_proto protoreflect.FileDescriptor
+
+var file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDesc = []byte{
+	0x0a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x69,
+	0x64, 0x73, 0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x2f, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x73, 0x2f, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2f, 0x66,
+	0x69, 0x6e, 0x64, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11, 0x6c, 0x75, 0x69,
+	0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x1a, 0x34,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x69, 0x64, 0x73,
+	0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x73, 0x2f,
+	0x64, 0x6e, 0x73, 0x75, 0x74, 0x69, 0x6c, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x73, 0x2f, 0x64, 0x6e, 0x73, 0x75, 0x74, 0x69, 0x6c, 0x2f, 0x66, 0x69,
+	0x6e, 0x64, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf8, 0x01, 0x0a, 0x06, 0x46,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x33, 0x0a, 0x04, 0x62, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73, 0x75,
+	0x74, 0x69, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x46, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x52, 0x04, 0x62, 0x61, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x5f, 0x6e, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4e, 0x65, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x5f, 0x6e, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x61, 0x6d, 0x65,
+	0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x6e, 0x61, 0x6d, 0x65, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x72,
+	0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x5f, 0x6e, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x4e, 0x65, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x65, 0x67, 0x65, 0x78, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x22, 0x8a, 0x01, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x6d, 0x61, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6d, 0x61, 0x78, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x65, 0x78, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52,
+	0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x76, 0x65,
+	0x72, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72,
+	0x73, 0x65, 0x22, 0x5c, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x6c,
+	0x76, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73,
+	0x2e, 0x64, 0x6e, 0x73, 0x75, 0x74, 0x69, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f,
+	0x6c, 0x76, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x65, 0x78, 0x74,
+	0x22, 0x4b, 0x0a, 0x14, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6c, 0x75, 0x69, 0x64,
+	0x73, 0x2e, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x22, 0x49, 0x0a,
+	0x15, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73,
+	0x75, 0x74, 0x69, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x44, 0x61,
+	0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0xd3, 0x01, 0x0a, 0x06, 0x46, 0x69, 0x6e,
+	0x64, 0x65, 0x72, 0x12, 0x61, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x6f,
+	0x6c, 0x76, 0x73, 0x12, 0x26, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73,
+	0x6f, 0x6c, 0x76, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x6c, 0x75,
+	0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x66, 0x0a, 0x0d, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77,
+	0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x12, 0x27, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e,
+	0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x77, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x28, 0x2e, 0x6c, 0x75, 0x69, 0x64, 0x73, 0x2e, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x6f, 0x6c,
+	0x76, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x32,
+	0x5a, 0x30, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x69,
+	0x64, 0x73, 0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x2f, 0x70, 0x6b,
+	0x67, 0x2f, 0x64, 0x6e, 0x73, 0x71, 0x75, 0x65, 0x72, 0x79, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescOnce sync.Once
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescData = file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDesc
+)
+
+func file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescGZIP() []byte {
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescOnce.Do(func() {
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescData = protoimpl.X.CompressGZIP(file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescData)
+	})
+	return file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDescData
+}
+
+var file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_goTypes = []interface{}{
+	(*Filter)(nil),                // 0: luids.dnsquery.v1.Filter
+	(*QueryResolvsRequest)(nil),   // 1: luids.dnsquery.v1.QueryResolvsRequest
+	(*QueryResolvsResponse)(nil),  // 2: luids.dnsquery.v1.QueryResolvsResponse
+	(*FollowResolvsRequest)(nil),  // 3: luids.dnsquery.v1.FollowResolvsRequest
+	(*FollowResolvsResponse)(nil), // 4: luids.dnsquery.v1.FollowResolvsResponse
+	(*pb.ResolvsFilter)(nil),      // 5: luids.dnsutil.v1.ResolvsFilter
+	(*pb.ResolvData)(nil),         // 6: luids.dnsutil.v1.ResolvData
+}
+var file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_depIdxs = []int32{
+	5, // 0: luids.dnsquery.v1.Filter.base:type_name -> luids.dnsutil.v1.ResolvsFilter
+	0, // 1: luids.dnsquery.v1.QueryResolvsRequest.filters:type_name -> luids.dnsquery.v1.Filter
+	6, // 2: luids.dnsquery.v1.QueryResolvsResponse.data:type_name -> luids.dnsutil.v1.ResolvData
+	0, // 3: luids.dnsquery.v1.FollowResolvsRequest.filters:type_name -> luids.dnsquery.v1.Filter
+	6, // 4: luids.dnsquery.v1.FollowResolvsResponse.data:type_name -> luids.dnsutil.v1.ResolvData
+	1, // 5: luids.dnsquery.v1.Finder.QueryResolvs:input_type -> luids.dnsquery.v1.QueryResolvsRequest
+	3, // 6: luids.dnsquery.v1.Finder.FollowResolvs:input_type -> luids.dnsquery.v1.FollowResolvsRequest
+	2, // 7: luids.dnsquery.v1.Finder.QueryResolvs:output_type -> luids.dnsquery.v1.QueryResolvsResponse
+	4, // 8: luids.dnsquery.v1.Finder.FollowResolvs:output_type -> luids.dnsquery.v1.FollowResolvsResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_init() }
+func file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_init() {
+	if File_github_com_luids_io_archive_schemas_dnsquery_finder_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Filter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryResolvsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryResolvsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FollowResolvsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FollowResolvsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_goTypes,
+		DependencyIndexes: file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_depIdxs,
+		MessageInfos:      file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_msgTypes,
+	}.Build()
+	File_github_com_luids_io_archive_schemas_dnsquery_finder_proto = out.File
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_rawDesc = nil
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_goTypes = nil
+	file_github_com_luids_io_archive_schemas_dnsquery_finder_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// FinderClient is the client API for Finder service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FinderClient interface {
+	QueryResolvs(ctx context.Context, in *QueryResolvsRequest, opts ...grpc.CallOption) (*QueryResolvsResponse, error)
+	FollowResolvs(ctx context.Context, in *FollowResolvsRequest, opts ...grpc.CallOption) (Finder_FollowResolvsClient, error)
+}
+
+type finderClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFinderClient(cc grpc.ClientConnInterface) FinderClient {
+	return &finderClient{cc}
+}
+
+func (c *finderClient) QueryResolvs(ctx context.Context, in *QueryResolvsRequest, opts ...grpc.CallOption) (*QueryResolvsResponse, error) {
+	out := new(QueryResolvsResponse)
+	err := c.cc.Invoke(ctx, "/luids.dnsquery.v1.Finder/QueryResolvs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *finderClient) FollowResolvs(ctx context.Context, in *FollowResolvsRequest, opts ...grpc.CallOption) (Finder_FollowResolvsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Finder_serviceDesc.Streams[0], "/luids.dnsquery.v1.Finder/FollowResolvs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &finderFollowResolvsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Finder_FollowResolvsClient interface {
+	Recv() (*FollowResolvsResponse, error)
+	grpc.ClientStream
+}
+
+type finderFollowResolvsClient struct {
+	grpc.ClientStream
+}
+
+func (x *finderFollowResolvsClient) Recv() (*FollowResolvsResponse, error) {
+	m := new(FollowResolvsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FinderServer is the server API for Finder service.
+type FinderServer interface {
+	QueryResolvs(context.Context, *QueryResolvsRequest) (*QueryResolvsResponse, error)
+	FollowResolvs(*FollowResolvsRequest, Finder_FollowResolvsServer) error
+}
+
+// UnimplementedFinderServer can be embedded to have forward compatible implementations.
+type UnimplementedFinderServer struct {
+}
+
+func (*UnimplementedFinderServer) QueryResolvs(context.Context, *QueryResolvsRequest) (*QueryResolvsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryResolvs not implemented")
+}
+func (*UnimplementedFinderServer) FollowResolvs(*FollowResolvsRequest, Finder_FollowResolvsServer) error {
+	return status.Errorf(codes.Unimplemented, "method FollowResolvs not implemented")
+}
+
+func RegisterFinderServer(s *grpc.Server, srv FinderServer) {
+	s.RegisterService(&_Finder_serviceDesc, srv)
+}
+
+func _Finder_QueryResolvs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryResolvsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinderServer).QueryResolvs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/luids.dnsquery.v1.Finder/QueryResolvs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinderServer).QueryResolvs(ctx, req.(*QueryResolvsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Finder_FollowResolvs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FollowResolvsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FinderServer).FollowResolvs(m, &finderFollowResolvsServer{stream})
+}
+
+type Finder_FollowResolvsServer interface {
+	Send(*FollowResolvsResponse) error
+	grpc.ServerStream
+}
+
+type finderFollowResolvsServer struct {
+	grpc.ServerStream
+}
+
+func (x *finderFollowResolvsServer) Send(m *FollowResolvsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Finder_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "luids.dnsquery.v1.Finder",
+	HandlerType: (*FinderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryResolvs",
+			Handler:    _Finder_QueryResolvs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FollowResolvs",
+			Handler:       _Finder_FollowResolvs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/luids-io/archive/schemas/dnsquery/finder.proto",
+}
@@ -0,0 +1,422 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package dnsquery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxFilters is the max number of filters created by a query.
+const MaxFilters = 64
+
+// Parse returns the filters of a query expression. Filters are OR-ed, so
+// they can be used with Finder.
+//
+// Expressions are conditions 'field=value' combined with 'and', 'or' and
+// parentheses, 'and' has precedence over 'or'. Values with spaces or
+// special characters can be quoted with '"'. Fields are:
+//
+//	client, server     ip or network in cidr format
+//	name               name, with '*' wildcards
+//	domain             domain and its subdomains
+//	resolvedip, ip     resolved ip or network in cidr format
+//	resolvedcname, cname
+//	qid
+//	returncode, rcode  number or name (formerr, servfail, nxdomain, notimp, refused)
+//	tld, tldplusone
+//	since, to          time in RFC3339 format, 'now' or relative to now (-1h, -30m, -2d, -1w)
+//
+// Names can also be matched with a regular expression using 'name~regex'.
+func Parse(query string, now time.Time) ([]Filter, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+	p := &parser{tokens: tokens, now: now}
+	conjs, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s' at position %v", p.tokens[p.pos].value, p.tokens[p.pos].offset)
+	}
+	filters := make([]Filter, 0, len(conjs))
+	for _, conj := range conjs {
+		var f Filter
+		for _, c := range conj {
+			err := c.apply(&f)
+			if err != nil {
+				return nil, err
+			}
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokLParen
+	tokRParen
+	tokEqual
+	tokMatch
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	quoted bool
+	offset int
+}
+
+func (t token) keyword(k string) bool {
+	return t.kind == tokWord && !t.quoted && strings.EqualFold(t.value, k)
+}
+
+func lex(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, value: "(", offset: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, value: ")", offset: i})
+			i++
+		case r == '=':
+			tokens = append(tokens, token{kind: tokEqual, value: "=", offset: i})
+			i++
+		case r == '~':
+			tokens = append(tokens, token{kind: tokMatch, value: "~", offset: i})
+			i++
+		case r == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %v", start)
+			}
+			tokens = append(tokens, token{kind: tokWord, value: sb.String(), quoted: true, offset: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()=~\"", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokWord, value: string(runes[start:i]), offset: start})
+		}
+	}
+	return tokens, nil
+}
+
+// parser returns the expression in disjunctive normal form: a list of
+// conjunctions of conditions.
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+type condition struct {
+	field, op, value string
+	offset           int
+	now              time.Time
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseExpr() ([][]condition, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || !t.keyword("or") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = append(left, right...)
+		if len(left) > MaxFilters {
+			return nil, fmt.Errorf("query creates more than %v filters", MaxFilters)
+		}
+	}
+}
+
+func (p *parser) parseTerm() ([][]condition, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || !t.keyword("and") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		if len(left)*len(right) > MaxFilters {
+			return nil, fmt.Errorf("query creates more than %v filters", MaxFilters)
+		}
+		product := make([][]condition, 0, len(left)*len(right))
+		for _, l := range left {
+			for _, r := range right {
+				conj := make([]condition, 0, len(l)+len(r))
+				conj = append(conj, l...)
+				conj = append(conj, r...)
+				product = append(product, conj)
+			}
+		}
+		left = product
+	}
+}
+
+func (p *parser) parseFactor() ([][]condition, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of query")
+	}
+	if t.kind == tokLParen {
+		lparen := t
+		p.pos++
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		t, ok = p.peek()
+		if !ok || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing ')' for '(' at position %v", lparen.offset)
+		}
+		p.pos++
+		return expr, nil
+	}
+	if t.kind != tokWord || t.quoted || t.keyword("and") || t.keyword("or") {
+		return nil, fmt.Errorf("expected field at position %v", t.offset)
+	}
+	field := t
+	p.pos++
+	t, ok = p.peek()
+	if !ok || (t.kind != tokEqual && t.kind != tokMatch) {
+		return nil, fmt.Errorf("expected '=' or '~' after '%s' at position %v", field.value, field.offset)
+	}
+	op := t.value
+	p.pos++
+	t, ok = p.peek()
+	if !ok || t.kind != tokWord {
+		return nil, fmt.Errorf("expected value for '%s' at position %v", field.value, field.offset)
+	}
+	p.pos++
+	c := condition{field: strings.ToLower(field.value), op: op, value: t.value, offset: field.offset, now: p.now}
+	return [][]condition{{c}}, nil
+}
+
+var rcodes = map[string]int{
+	"formerr":  1,
+	"servfail": 2,
+	"nxdomain": 3,
+	"notimp":   4,
+	"refused":  5,
+}
+
+// apply sets the condition in the filter.
+func (c condition) apply(f *Filter) error {
+	var err error
+	if c.op == "~" {
+		if c.field != "name" {
+			return c.errorf("operator '~' not supported")
+		}
+		_, err = regexp.Compile(c.value)
+		if err != nil {
+			return c.errorf("invalid regular expression: %v", err)
+		}
+		f.NameRegex, err = c.setString(f.NameRegex)
+		return err
+	}
+	switch c.field {
+	case "client":
+		f.ResolvsFilter.Client, f.ClientNet, err = c.setIPOrNet(f.ResolvsFilter.Client, f.ClientNet)
+	case "server":
+		f.ResolvsFilter.Server, f.ServerNet, err = c.setIPOrNet(f.ResolvsFilter.Server, f.ServerNet)
+	case "name":
+		if strings.Contains(c.value, "*") {
+			f.NamePattern, err = c.setString(f.NamePattern)
+		} else {
+			f.Name, err = c.setString(f.Name)
+		}
+	case "domain":
+		c.value = strings.ToLower(strings.TrimSuffix(c.value, "."))
+		f.Domain, err = c.setString(f.Domain)
+	case "resolvedip", "ip":
+		f.ResolvedIP, f.ResolvedNet, err = c.setIPOrNet(f.ResolvedIP, f.ResolvedNet)
+	case "resolvedcname", "cname":
+		f.ResolvedCNAME, err = c.setString(f.ResolvedCNAME)
+	case "qid":
+		f.QID, err = c.setInt(f.QID, nil)
+	case "returncode", "rcode":
+		f.ReturnCode, err = c.setInt(f.ReturnCode, rcodes)
+	case "tld":
+		f.TLD, err = c.setString(f.TLD)
+	case "tldplusone":
+		f.TLDPlusOne, err = c.setString(f.TLDPlusOne)
+	case "since":
+		t, err := parseTime(c.value, c.now)
+		if err != nil {
+			return c.errorf("%v", err)
+		}
+		if f.Since.IsZero() || t.After(f.Since) {
+			f.Since = t
+		}
+	case "to":
+		t, err := parseTime(c.value, c.now)
+		if err != nil {
+			return c.errorf("%v", err)
+		}
+		if f.To.IsZero() || t.Before(f.To) {
+			f.To = t
+		}
+	default:
+		return c.errorf("unknown field")
+	}
+	return err
+}
+
+func (c condition) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("'%s' at position %v: %s", c.field, c.offset, fmt.Sprintf(format, args...))
+}
+
+func (c condition) setString(current string) (string, error) {
+	if c.value == "" {
+		return current, c.errorf("empty value")
+	}
+	if current != "" && current != c.value {
+		return current, c.errorf("conflicting values")
+	}
+	return c.value, nil
+}
+
+// setInt parses value, zero values can't be filtered.
+func (c condition) setInt(current int, names map[string]int) (int, error) {
+	v, ok := names[strings.ToLower(c.value)]
+	if !ok {
+		var err error
+		v, err = strconv.Atoi(c.value)
+		if err != nil {
+			return current, c.errorf("invalid number")
+		}
+	}
+	if v <= 0 {
+		return current, c.errorf("value must be greater than zero")
+	}
+	if current != 0 && current != v {
+		return current, c.errorf("conflicting values")
+	}
+	return v, nil
+}
+
+func (c condition) setIPOrNet(ip net.IP, ipnet *net.IPNet) (net.IP, *net.IPNet, error) {
+	if ip != nil || ipnet != nil {
+		if ip != nil && ip.Equal(net.ParseIP(c.value)) {
+			return ip, ipnet, nil
+		}
+		if ipnet != nil && ipnet.String() == c.value {
+			return ip, ipnet, nil
+		}
+		return ip, ipnet, c.errorf("conflicting values")
+	}
+	if strings.Contains(c.value, "/") {
+		_, n, err := net.ParseCIDR(c.value)
+		if err != nil {
+			return nil, nil, c.errorf("invalid network")
+		}
+		return nil, n, nil
+	}
+	parsed := net.ParseIP(c.value)
+	if parsed == nil {
+		return nil, nil, c.errorf("invalid ip")
+	}
+	return parsed, nil, nil
+}
+
+// parseTime parses absolute or relative times.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(s, "now") {
+		return now, nil
+	}
+	if strings.HasPrefix(s, "-") {
+		d, err := parseDuration(s[1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s'", s)
+	}
+	return t, nil
+}
+
+// parseDuration supports days and weeks.
+func parseDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(strings.TrimRight(s[:len(s)-1], " "))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration '%s'", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration '%s'", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package dnsquery
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []token
+		wantErr bool
+	}{
+		{"", []token{}, false},
+		{`name=www.example.com`, []token{
+			{kind: tokWord, value: "name", offset: 0},
+			{kind: tokEqual, value: "=", offset: 4},
+			{kind: tokWord, value: "www.example.com", offset: 5},
+		}, false},
+		{` (ip = 10.0.0.1)or name~"a \"b\""`, []token{
+			{kind: tokLParen, value: "(", offset: 1},
+			{kind: tokWord, value: "ip", offset: 2},
+			{kind: tokEqual, value: "=", offset: 5},
+			{kind: tokWord, value: "10.0.0.1", offset: 7},
+			{kind: tokRParen, value: ")", offset: 15},
+			{kind: tokWord, value: "or", offset: 16},
+			{kind: tokWord, value: "name", offset: 19},
+			{kind: tokMatch, value: "~", offset: 23},
+			{kind: tokWord, value: `a "b"`, quoted: true, offset: 24},
+		}, false},
+		{`name="unterminated`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := lex(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("lex(%q) err = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lex(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	_, net10, _ := net.ParseCIDR("10.0.0.0/8")
+	tests := []struct {
+		query string
+		want  []Filter
+	}{
+		{"name=www.example.com", []Filter{
+			mkFilter(func(f *Filter) { f.Name = "www.example.com" }),
+		}},
+		{"NAME=*.example.com and rcode=nxdomain", []Filter{
+			mkFilter(func(f *Filter) { f.NamePattern = "*.example.com"; f.ReturnCode = 3 }),
+		}},
+		{"client=10.0.0.0/8 or ip=192.0.2.1", []Filter{
+			mkFilter(func(f *Filter) { f.ClientNet = net10 }),
+			mkFilter(func(f *Filter) { f.ResolvedIP = net.ParseIP("192.0.2.1") }),
+		}},
+		{"domain=Example.COM. and name~^www", []Filter{
+			mkFilter(func(f *Filter) { f.Domain = "example.com"; f.NameRegex = "^www" }),
+		}},
+		// and has precedence over or
+		{"qid=1 or qid=2 and rcode=3", []Filter{
+			mkFilter(func(f *Filter) { f.QID = 1 }),
+			mkFilter(func(f *Filter) { f.QID = 2; f.ReturnCode = 3 }),
+		}},
+		// disjunctive normal form
+		{"(qid=1 or qid=2) and (rcode=2 or tld=com)", []Filter{
+			mkFilter(func(f *Filter) { f.QID = 1; f.ReturnCode = 2 }),
+			mkFilter(func(f *Filter) { f.QID = 1; f.TLD = "com" }),
+			mkFilter(func(f *Filter) { f.QID = 2; f.ReturnCode = 2 }),
+			mkFilter(func(f *Filter) { f.QID = 2; f.TLD = "com" }),
+		}},
+		// same value is not a conflict
+		{"cname=a.com and resolvedcname=a.com", []Filter{
+			mkFilter(func(f *Filter) { f.ResolvedCNAME = "a.com" }),
+		}},
+		// times are narrowed
+		{"since=-1h and since=-2d and to=now and to=2021-03-10T11:00:00Z", []Filter{
+			mkFilter(func(f *Filter) {
+				f.Since = now.Add(-time.Hour)
+				f.To = time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC)
+			}),
+		}},
+		{"since=-1w", []Filter{
+			mkFilter(func(f *Filter) { f.Since = now.Add(-7 * 24 * time.Hour) }),
+		}},
+		{"since=-30m", []Filter{
+			mkFilter(func(f *Filter) { f.Since = now.Add(-30 * time.Minute) }),
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.query, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"", "empty query"},
+		{"   ", "empty query"},
+		{"name", "expected '=' or '~'"},
+		{"name=", "expected value"},
+		{"=a", "expected field"},
+		{`"name"=a`, "expected field"},
+		{"(name=a", "missing ')'"},
+		{"name=a)", "unexpected ')'"},
+		{"name=a and", "unexpected end of query"},
+		{"foo=a", "unknown field"},
+		{"qid~1", "operator '~' not supported"},
+		{`name~"("`, "invalid regular expression"},
+		{`name=""`, "empty value"},
+		{"qid=0", "greater than zero"},
+		{"qid=x", "invalid number"},
+		{"rcode=unknown", "invalid number"},
+		{"client=10.0.0.300", "invalid ip"},
+		{"client=10.0.0.0/33", "invalid network"},
+		{"since=yesterday", "invalid time"},
+		{"since=-xh", "invalid duration"},
+		{"since=-2x", "invalid duration"},
+		// conflicting values
+		{"name=a and name=b", "conflicting values"},
+		{"rcode=2 and rcode=nxdomain", "conflicting values"},
+		{"server=10.0.0.1 and server=10.0.0.0/8", "conflicting values"},
+		{"name~a and name~b", "conflicting values"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query, now)
+		if err == nil {
+			t.Errorf("Parse(%q) expected error", tt.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) err = %v, want %q", tt.query, err, tt.err)
+		}
+	}
+}
+
+func TestParseMaxFilters(t *testing.T) {
+	ors := func(field string, n int) string {
+		terms := make([]string, 0, n)
+		for i := 1; i <= n; i++ {
+			terms = append(terms, fmt.Sprintf("%s=%v", field, i))
+		}
+		return "(" + strings.Join(terms, " or ") + ")"
+	}
+	tests := []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{ors("qid", MaxFilters), MaxFilters, false},
+		{ors("qid", MaxFilters+1), 0, true},
+		{ors("qid", 8) + " and " + ors("tld", 8), 64, false},
+		{ors("qid", 8) + " and " + ors("tld", 9), 0, true},
+		{ors("qid", 4) + " and " + ors("tld", 4) + " and " + ors("cname", 4) + " and " + ors("rcode", 2), 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.query, time.Now())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%.30q...) err = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && len(got) != tt.want {
+			t.Errorf("Parse(%.30q...) returned %v filters, want %v", tt.query, len(got), tt.want)
+		}
+	}
+}
+
+func mkFilter(fn func(f *Filter)) Filter {
+	var f Filter
+	fn(&f)
+	return f
+}
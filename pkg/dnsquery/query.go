@@ -0,0 +1,58 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package dnsquery defines the interface for finding archived dns
+// resolutions with extended filters and a query language to create them.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsquery
+
+import (
+	"context"
+	"net"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+// Finder is the interface for archive finder with extended filters.
+type Finder interface {
+	QueryResolvs(ctx context.Context, filters []Filter, rev bool, max int, next string) ([]dnsutil.ResolvData, string, error)
+}
+
+// Follower is the interface for following the resolutions archived that
+// match the filters. FollowResolvs calls fn with each new resolution until
+// the context is done or fn returns an error.
+type Follower interface {
+	FollowResolvs(ctx context.Context, filters []Filter, fn func(dnsutil.ResolvData) error) error
+}
+
+// Filter extends dnsutil.ResolvsFilter with networks of ips, domains and
+// patterns of names. Empty fields are ignored.
+type Filter struct {
+	dnsutil.ResolvsFilter
+	ClientNet, ServerNet, ResolvedNet *net.IPNet
+	// NamePattern matches names ignoring case, '*' matches any sequence of
+	// characters.
+	NamePattern string
+	// NameRegex matches names with a regular expression.
+	NameRegex string
+	// Domain matches the domain and its subdomains.
+	Domain string
+}
+
+// Filters returns filters from dnsutil filters.
+func Filters(filters []dnsutil.ResolvsFilter) []Filter {
+	ret := make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		ret = append(ret, Filter{ResolvsFilter: f})
+	}
+	return ret
+}
+
+// BaseFilters returns the dnsutil filters of the filters.
+func BaseFilters(filters []Filter) []dnsutil.ResolvsFilter {
+	ret := make([]dnsutil.ResolvsFilter, 0, len(filters))
+	for _, f := range filters {
+		ret = append(ret, f.ResolvsFilter)
+	}
+	return ret
+}
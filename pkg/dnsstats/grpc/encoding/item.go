@@ -0,0 +1,54 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/luids-io/archive/pkg/dnsstats"
+	"github.com/luids-io/archive/pkg/dnsstats/grpc/pb"
+)
+
+// Item copy info from pb
+func Item(src *pb.StatsItem, dst *dnsstats.Item) (err error) {
+	dst.Key = src.GetKey()
+	dst.Count = src.GetCount()
+	dst.NXDomain = src.GetNxdomain()
+	if src.FirstSeen != nil {
+		dst.FirstSeen, err = ptypes.Timestamp(src.GetFirstSeen())
+		if err != nil {
+			return
+		}
+	}
+	if src.LastSeen != nil {
+		dst.LastSeen, err = ptypes.Timestamp(src.GetLastSeen())
+	}
+	return
+}
+
+// ItemPB copy info to pb
+func ItemPB(src *dnsstats.Item, dst *pb.StatsItem) (err error) {
+	dst.Key = src.Key
+	dst.Count = src.Count
+	dst.Nxdomain = src.NXDomain
+	if !src.FirstSeen.IsZero() {
+		dst.FirstSeen, err = ptypes.TimestampProto(src.FirstSeen)
+		if err != nil {
+			return
+		}
+	}
+	if !src.LastSeen.IsZero() {
+		dst.LastSeen, err = ptypes.TimestampProto(src.LastSeen)
+	}
+	return
+}
+
+// GroupBy returns value from pb
+func GroupBy(src pb.GroupBy) dnsstats.GroupBy {
+	return dnsstats.GroupBy(src)
+}
+
+// GroupByPB returns value to pb
+func GroupByPB(src dnsstats.GroupBy) pb.GroupBy {
+	return pb.GroupBy(src)
+}
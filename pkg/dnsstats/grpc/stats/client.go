@@ -0,0 +1,110 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package stats
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsencoding "github.com/luids-io/api/dnsutil/grpc/encoding"
+	dnspb "github.com/luids-io/api/dnsutil/grpc/pb"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/dnsstats"
+	"github.com/luids-io/archive/pkg/dnsstats/grpc/encoding"
+	"github.com/luids-io/archive/pkg/dnsstats/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Client provides a grpc client.
+type Client struct {
+	logger yalogi.Logger
+	//grpc connection
+	conn   *grpcdns.Conn
+	client pb.StatsClient
+}
+
+// ClientOption encapsules options for client.
+type ClientOption = grpcdns.ClientOption
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return grpcdns.CloseConnection(b)
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return grpcdns.SetLogger(l)
+}
+
+// NewClient returns a new client.
+func NewClient(conn *grpc.ClientConn, opt ...ClientOption) *Client {
+	opts := grpcdns.NewClientOpts(opt...)
+	return &Client{
+		logger: opts.Logger,
+		conn:   grpcdns.NewConn(conn, opts),
+		client: pb.NewStatsClient(conn),
+	}
+}
+
+// Aggregate implements dnsstats.Aggregator interface
+func (c *Client) Aggregate(ctx context.Context, filters []dnsutil.ResolvsFilter,
+	groupBy dnsstats.GroupBy, max int) ([]dnsstats.Item, error) {
+	if c.conn.Closed() {
+		c.logger.Warnf("client.dnsutil.stats: aggregate(%v): client is closed", groupBy)
+		return nil, dnsutil.ErrUnavailable
+	}
+	if max < 0 {
+		c.logger.Warnf("client.dnsutil.stats: aggregate(%v): invalid max", groupBy)
+		return nil, dnsutil.ErrBadRequest
+	}
+	//create request
+	req := &pb.AggregateRequest{
+		GroupBy: encoding.GroupByPB(groupBy),
+		Max:     int32(max),
+		Filters: make([]*dnspb.ResolvsFilter, 0, len(filters)),
+	}
+	for _, f := range filters {
+		fpb := &dnspb.ResolvsFilter{}
+		err := dnsencoding.ResolvsFilterPB(&f, fpb)
+		if err != nil {
+			c.logger.Warnf("client.dnsutil.stats: aggregate(%v): bad filter: %v", groupBy, err)
+			return nil, dnsutil.ErrBadRequest
+		}
+		req.Filters = append(req.Filters, fpb)
+	}
+	//do aggregation
+	resp, err := c.client.Aggregate(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.dnsutil.stats: aggregate(%v): %v", groupBy, err)
+		return nil, grpcdns.ClientError(err)
+	}
+	//process response
+	items := make([]dnsstats.Item, 0, len(resp.GetItems()))
+	for _, ipb := range resp.GetItems() {
+		var item dnsstats.Item
+		err := encoding.Item(ipb, &item)
+		if err != nil {
+			c.logger.Errorf("client.dnsutil.stats: aggregate(%v): decoding item: %v", groupBy, err)
+			return nil, dnsutil.ErrInternal
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Ping checks connectivity with the api
+func (c *Client) Ping() error {
+	return c.conn.Ping()
+}
+
+// API returns API service name implemented
+func (c *Client) API() string {
+	return ServiceName()
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package stats implements a dnsstats.Aggregator client and a ready to use
+// service component.
+//
+// This package is a work in progress and makes no API stability promises.
+package stats
+
+import "github.com/luids-io/archive/internal/grpcdns"
+
+// Constants for api description.
+const (
+	APIName    = "luids.dnsstats"
+	APIVersion = "v1"
+	APIService = "Stats"
+)
+
+// ServiceName returns service name.
+func ServiceName() string {
+	return grpcdns.ServiceName(APIName, APIVersion, APIService)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package stats
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	dnsencoding "github.com/luids-io/api/dnsutil/grpc/encoding"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/dnsstats"
+	"github.com/luids-io/archive/pkg/dnsstats/grpc/encoding"
+	"github.com/luids-io/archive/pkg/dnsstats/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Service implements a grpc service wrapper.
+type Service struct {
+	logger     yalogi.Logger
+	aggregator dnsstats.Aggregator
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption = grpcdns.ServiceOption
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return grpcdns.SetServiceLogger(l)
+}
+
+// NewService returns a new Service.
+func NewService(a dnsstats.Aggregator, opt ...ServiceOption) *Service {
+	opts := grpcdns.NewServiceOpts(opt...)
+	return &Service{aggregator: a, logger: opts.Logger}
+}
+
+// RegisterServer registers a service in the grpc server.
+func RegisterServer(server *grpc.Server, service *Service) {
+	pb.RegisterStatsServer(server, service)
+}
+
+// Aggregate implements grpc interface.
+func (s *Service) Aggregate(ctx context.Context, req *pb.AggregateRequest) (*pb.AggregateResponse, error) {
+	// get request
+	groupBy := encoding.GroupBy(req.GetGroupBy())
+	if groupBy < dnsstats.ByName || groupBy > dnsstats.ByReturnCode {
+		s.logger.Warnf("service.dnsutil.stats: [peer=%s] aggregate(): bad group by", grpcdns.PeerAddr(ctx))
+		return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+	}
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.dnsutil.stats: [peer=%s] aggregate(%v): bad max", grpcdns.PeerAddr(ctx), groupBy)
+		return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+	}
+	filters := make([]dnsutil.ResolvsFilter, 0, len(req.GetFilters()))
+	for _, fpb := range req.GetFilters() {
+		var f dnsutil.ResolvsFilter
+		err := dnsencoding.ResolvsFilter(fpb, &f)
+		if err != nil {
+			s.logger.Warnf("service.dnsutil.stats: [peer=%s] aggregate(%v): bad filter: %v", grpcdns.PeerAddr(ctx), groupBy, err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+		}
+		filters = append(filters, f)
+	}
+	//do aggregation
+	items, err := s.aggregator.Aggregate(ctx, filters, groupBy, max)
+	if err != nil {
+		s.logger.Warnf("service.dnsutil.stats: [peer=%s] aggregate(%v): %v", grpcdns.PeerAddr(ctx), groupBy, err)
+		return nil, grpcdns.ServiceError(err)
+	}
+	//prepare response
+	resp := &pb.AggregateResponse{Items: make([]*pb.StatsItem, 0, len(items))}
+	for _, item := range items {
+		ipb := &pb.StatsItem{}
+		err := encoding.ItemPB(&item, ipb)
+		if err != nil {
+			s.logger.Errorf("service.dnsutil.stats: [peer=%s] aggregate(%v): encoding item: %v", grpcdns.PeerAddr(ctx), groupBy, err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrInternal)
+		}
+		resp.Items = append(resp.Items, ipb)
+	}
+	return resp, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package dnsstats defines the interface for aggregated statistics of
+// archived dns resolutions.
+//
+// This package is a work in progress and makes no API stability promises.
+package dnsstats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luids-io/api/dnsutil"
+)
+
+// Aggregator is the interface for archive aggregated dns statistics.
+type Aggregator interface {
+	Aggregate(ctx context.Context, filters []dnsutil.ResolvsFilter, groupBy GroupBy, max int) ([]Item, error)
+}
+
+// GroupBy defines the field used for grouping resolutions.
+type GroupBy int
+
+// GroupBy values.
+const (
+	ByName GroupBy = iota
+	ByTLDPlusOne
+	ByClient
+	ByReturnCode
+)
+
+func (g GroupBy) String() string {
+	switch g {
+	case ByName:
+		return "name"
+	case ByTLDPlusOne:
+		return "tldPlusOne"
+	case ByClient:
+		return "client"
+	case ByReturnCode:
+		return "returnCode"
+	}
+	return fmt.Sprintf("unknown(%d)", g)
+}
+
+// ParseGroupBy returns the value from string.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch s {
+	case "name":
+		return ByName, nil
+	case "tldPlusOne", "tldplusone":
+		return ByTLDPlusOne, nil
+	case "client":
+		return ByClient, nil
+	case "returnCode", "returncode":
+		return ByReturnCode, nil
+	}
+	return ByName, fmt.Errorf("invalid group by '%s'", s)
+}
+
+// NXDomain is the return code of non existent domains.
+const NXDomain = 3
+
+// Item stores the statistics of a group. Items are returned sorted by
+// count in descending order.
+type Item struct {
+	Key                 string
+	Count               int64
+	NXDomain            int64
+	FirstSeen, LastSeen time.Time
+}
+
+// NXDomainRate returns the rate of resolutions with nxdomain response.
+func (i Item) NXDomainRate() float64 {
+	if i.Count == 0 {
+		return 0
+	}
+	return float64(i.NXDomain) / float64(i.Count)
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package eventfinder defines the interface for finding archived events.
+//
+// This package is a work in progress and makes no API stability promises.
+package eventfinder
+
+import (
+	"context"
+	"time"
+
+	"github.com/luids-io/api/event"
+)
+
+// Finder is the interface for archive finder events.
+type Finder interface {
+	GetEvent(ctx context.Context, id string) (event.Event, bool, error)
+	ListEvents(ctx context.Context, filters []EventsFilter, rev bool, max int, next string) ([]event.Event, string, error)
+}
+
+// EventsFilter stores filter information. Empty fields are ignored.
+type EventsFilter struct {
+	Since, To time.Time
+	Code      event.Code
+	MinLevel  event.Level
+	Source    event.Source
+	Text      string
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"errors"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/luids-io/api/event"
+	eventencoding "github.com/luids-io/api/event/grpc/encoding"
+	eventpb "github.com/luids-io/api/event/grpc/pb"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/pb"
+)
+
+// EventPB returns a new protobuf from event
+func EventPB(e event.Event) (*pb.Event, error) {
+	var err error
+	dst := &pb.Event{}
+	dst.Id = e.ID
+	dst.Type = eventpb.EventType(e.Type)
+	dst.Code = int32(e.Code)
+	dst.Level = eventpb.EventLevel(e.Level)
+	dst.CreatedTs, _ = ptypes.TimestampProto(e.Created)
+	dst.ReceivedTs, _ = ptypes.TimestampProto(e.Received)
+	dst.Source = eventencoding.SourcePB(e.Source)
+	if len(e.Processors) > 0 {
+		dst.Processors = make([]*eventpb.ProcessInfo, 0, len(e.Processors))
+		for _, p := range e.Processors {
+			dst.Processors = append(dst.Processors, eventencoding.ProcessInfoPB(p))
+		}
+	}
+	dst.Data, err = eventencoding.EventDataPB(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	dst.Duplicates = int32(e.Duplicates)
+	dst.Codename = e.Codename
+	dst.Description = e.Description
+	if len(e.Tags) > 0 {
+		dst.Tags = make([]string, len(e.Tags), len(e.Tags))
+		copy(dst.Tags, e.Tags)
+	}
+	return dst, nil
+}
+
+// Event returns event from protobuf
+func Event(src *pb.Event) (event.Event, error) {
+	var err error
+	e := event.Event{}
+	e.ID = src.GetId()
+	e.Type = event.Type(src.GetType())
+	e.Code = event.Code(src.GetCode())
+	e.Level = event.Level(src.GetLevel())
+	e.Created, _ = ptypes.Timestamp(src.GetCreatedTs())
+	e.Received, _ = ptypes.Timestamp(src.GetReceivedTs())
+	// get source
+	pbsource := src.GetSource()
+	if pbsource == nil {
+		return event.Event{}, errors.New("source is empty")
+	}
+	e.Source = eventencoding.Source(pbsource)
+	// get processors
+	pbprocessors := src.GetProcessors()
+	if len(pbprocessors) > 0 {
+		e.Processors = make([]event.ProcessInfo, 0, len(pbprocessors))
+		for _, p := range pbprocessors {
+			e.Processors = append(e.Processors, eventencoding.ProcessInfo(p))
+		}
+	}
+	//decode event data
+	pbdata := src.GetData()
+	if pbdata == nil {
+		return event.Event{}, errors.New("data is empty")
+	}
+	e.Data, err = eventencoding.EventData(pbdata)
+	if err != nil {
+		return event.Event{}, err
+	}
+	e.Duplicates = int(src.GetDuplicates())
+	e.Codename = src.GetCodename()
+	e.Description = src.GetDescription()
+	tags := src.GetTags()
+	if len(tags) > 0 {
+		e.Tags = make([]string, len(tags), len(tags))
+		copy(e.Tags, tags)
+	}
+	return e, nil
+}
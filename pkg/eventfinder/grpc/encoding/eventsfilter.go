@@ -0,0 +1,47 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/luids-io/api/event"
+	eventencoding "github.com/luids-io/api/event/grpc/encoding"
+	eventpb "github.com/luids-io/api/event/grpc/pb"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/pb"
+)
+
+// EventsFilter copy info from pb
+func EventsFilter(src *pb.EventsFilter, dst *eventfinder.EventsFilter) (err error) {
+	if src.Since != nil {
+		dst.Since, _ = ptypes.Timestamp(src.GetSince())
+	}
+	if src.To != nil {
+		dst.To, _ = ptypes.Timestamp(src.GetTo())
+	}
+	dst.Code = event.Code(src.GetCode())
+	dst.MinLevel = event.Level(src.GetMinLevel())
+	if src.Source != nil {
+		dst.Source = eventencoding.Source(src.GetSource())
+	}
+	dst.Text = src.GetText()
+	return
+}
+
+// EventsFilterPB copy info to pb
+func EventsFilterPB(src *eventfinder.EventsFilter, dst *pb.EventsFilter) (err error) {
+	if !src.Since.IsZero() {
+		dst.Since, _ = ptypes.TimestampProto(src.Since)
+	}
+	if !src.To.IsZero() {
+		dst.To, _ = ptypes.TimestampProto(src.To)
+	}
+	dst.Code = int32(src.Code)
+	dst.MinLevel = eventpb.EventLevel(src.MinLevel)
+	if !src.Source.Equals(event.Source{}) {
+		dst.Source = eventencoding.SourcePB(src.Source)
+	}
+	dst.Text = src.Text
+	return
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/encoding"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Client provides a grpc client.
+type Client struct {
+	opts   clientOpts
+	logger yalogi.Logger
+	//grpc connection
+	conn   *grpc.ClientConn
+	client pb.FinderClient
+	//control
+	closed bool
+}
+
+// ClientOption encapsules options for client.
+type ClientOption func(*clientOpts)
+
+type clientOpts struct {
+	logger    yalogi.Logger
+	closeConn bool
+}
+
+var defaultClientOpts = clientOpts{
+	logger:    yalogi.LogNull,
+	closeConn: true,
+}
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return func(o *clientOpts) {
+		o.closeConn = b
+	}
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return func(o *clientOpts) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// NewClient returns a new client.
+func NewClient(conn *grpc.ClientConn, opt ...ClientOption) *Client {
+	opts := defaultClientOpts
+	for _, o := range opt {
+		o(&opts)
+	}
+	return &Client{
+		opts:   opts,
+		logger: opts.logger,
+		conn:   conn,
+		client: pb.NewFinderClient(conn),
+	}
+}
+
+// GetEvent implements eventfinder.Finder interface
+func (c *Client) GetEvent(ctx context.Context, id string) (event.Event, bool, error) {
+	if c.closed {
+		c.logger.Warnf("client.event.finder: getevent(%s): client is closed", id)
+		return event.Event{}, false, event.ErrUnavailable
+	}
+	//check req
+	if id == "" {
+		c.logger.Warnf("client.event.finder: getevent(): id is empty")
+		return event.Event{}, false, event.ErrBadRequest
+	}
+	//do req
+	resp, err := c.client.GetEvent(ctx, &pb.GetEventRequest{Id: id})
+	if errNotFound(err) {
+		return event.Event{}, false, nil
+	}
+	if err != nil {
+		c.logger.Warnf("client.event.finder: getevent(%s): %v", id, err)
+		return event.Event{}, false, c.mapError(err)
+	}
+	epb := resp.GetData()
+	if epb == nil {
+		c.logger.Errorf("client.event.finder: getevent(%s): unexpected data empty", id)
+		return event.Event{}, false, event.ErrInternal
+	}
+	//map event to object
+	e, err := encoding.Event(epb)
+	if err != nil {
+		c.logger.Warnf("client.event.finder: getevent(%s): converting data: %v", id, err)
+		return event.Event{}, false, event.ErrInternal
+	}
+	return e, true, nil
+}
+
+// ListEvents implements eventfinder.Finder interface
+func (c *Client) ListEvents(ctx context.Context, filters []eventfinder.EventsFilter,
+	rev bool, max int, next string) ([]event.Event, string, error) {
+	if c.closed {
+		c.logger.Warnf("client.event.finder: listevents(): client is closed")
+		return nil, "", event.ErrUnavailable
+	}
+	if max < 0 {
+		c.logger.Warnf("client.event.finder: listevents(): invalid max")
+		return nil, "", event.ErrBadRequest
+	}
+	//create request
+	req := &pb.ListEventsRequest{
+		Max:     int32(max),
+		Next:    next,
+		Reverse: rev,
+		Filters: make([]*pb.EventsFilter, 0, len(filters)),
+	}
+	for _, f := range filters {
+		fpb := &pb.EventsFilter{}
+		err := encoding.EventsFilterPB(&f, fpb)
+		if err != nil {
+			c.logger.Warnf("client.event.finder: listevents(): bad filter: %v", err)
+			return nil, "", event.ErrBadRequest
+		}
+		req.Filters = append(req.Filters, fpb)
+	}
+	//do list
+	resp, err := c.client.ListEvents(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.event.finder: listevents(): %v", err)
+		return nil, "", c.mapError(err)
+	}
+	//process response
+	data := make([]event.Event, 0, len(resp.GetData()))
+	for _, epb := range resp.GetData() {
+		e, err := encoding.Event(epb)
+		if err != nil {
+			c.logger.Errorf("client.event.finder: listevents(): encoding returned data: %v", err)
+			return nil, "", c.mapError(event.ErrInternal)
+		}
+		data = append(data, e)
+	}
+	return data, resp.GetNext(), nil
+}
+
+//mapping errors.
+func (c *Client) mapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Canceled:
+		return event.ErrCanceledRequest
+	case codes.InvalidArgument:
+		return event.ErrBadRequest
+	case codes.PermissionDenied:
+		return event.ErrUnauthorized
+	case codes.Unimplemented:
+		return event.ErrNotSupported
+	case codes.Internal:
+		return event.ErrInternal
+	case codes.Unavailable:
+		return event.ErrUnavailable
+	default:
+		return event.ErrUnavailable
+	}
+}
+
+func errNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	if st.Code() == codes.NotFound {
+		return true
+	}
+	return false
+}
+
+//Close closes the client
+func (c *Client) Close() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	c.closed = true
+	if c.opts.closeConn {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// Ping checks connectivity with the api
+func (c *Client) Ping() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	st := c.conn.GetState()
+	switch st {
+	case connectivity.TransientFailure:
+		return fmt.Errorf("connection state: %v", st)
+	case connectivity.Shutdown:
+		return fmt.Errorf("connection state: %v", st)
+	}
+	return nil
+}
+
+//API returns API service name implemented
+func (c *Client) API() string {
+	return ServiceName()
+}
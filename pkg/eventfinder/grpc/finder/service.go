@@ -0,0 +1,145 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/luids-io/api/event"
+	"github.com/luids-io/archive/pkg/eventfinder"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/encoding"
+	"github.com/luids-io/archive/pkg/eventfinder/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Service implements a grpc service wrapper.
+type Service struct {
+	logger yalogi.Logger
+	finder eventfinder.Finder
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption func(*serviceOpts)
+
+type serviceOpts struct {
+	logger yalogi.Logger
+}
+
+var defaultServiceOpts = serviceOpts{logger: yalogi.LogNull}
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return func(o *serviceOpts) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// NewService returns a new Service.
+func NewService(f eventfinder.Finder, opt ...ServiceOption) *Service {
+	opts := defaultServiceOpts
+	for _, o := range opt {
+		o(&opts)
+	}
+	return &Service{finder: f, logger: opts.logger}
+}
+
+// RegisterServer registers a service in the grpc server.
+func RegisterServer(server *grpc.Server, service *Service) {
+	pb.RegisterFinderServer(server, service)
+}
+
+// GetEvent implements grpc interface.
+func (s *Service) GetEvent(ctx context.Context, req *pb.GetEventRequest) (*pb.GetEventResponse, error) {
+	id := req.GetId()
+	if id == "" {
+		s.logger.Warnf("service.event.finder: [peer=%s] getevent(): id is empty", getPeerAddr(ctx))
+		return nil, s.mapError(event.ErrBadRequest)
+	}
+	e, exists, err := s.finder.GetEvent(ctx, id)
+	if err != nil {
+		s.logger.Warnf("service.event.finder: [peer=%s] getevent(%s): %v", getPeerAddr(ctx), id, err)
+		return nil, s.mapError(err)
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "event not found")
+	}
+	epb, err := encoding.EventPB(e)
+	if err != nil {
+		s.logger.Warnf("service.event.finder: [peer=%s] getevent(%s): encoding pb: %v", getPeerAddr(ctx), id, err)
+		return nil, s.mapError(err)
+	}
+	return &pb.GetEventResponse{Data: epb}, nil
+}
+
+// ListEvents implements grpc interface.
+func (s *Service) ListEvents(ctx context.Context, req *pb.ListEventsRequest) (*pb.ListEventsResponse, error) {
+	// get request
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.event.finder: [peer=%s] listevents(): bad max", getPeerAddr(ctx))
+		return nil, s.mapError(event.ErrBadRequest)
+	}
+	filters := make([]eventfinder.EventsFilter, 0, len(req.GetFilters()))
+	for _, efpb := range req.GetFilters() {
+		var ef eventfinder.EventsFilter
+		err := encoding.EventsFilter(efpb, &ef)
+		if err != nil {
+			s.logger.Warnf("service.event.finder: [peer=%s] listevents(): bad filter: %v", getPeerAddr(ctx), err)
+			return nil, s.mapError(event.ErrBadRequest)
+		}
+		filters = append(filters, ef)
+	}
+	//do list
+	data, next, err := s.finder.ListEvents(ctx, filters, req.GetReverse(), max, req.GetNext())
+	if err != nil {
+		s.logger.Warnf("service.event.finder: [peer=%s] listevents(): %v", getPeerAddr(ctx), err)
+		return nil, s.mapError(err)
+	}
+	//prepare response
+	resp := &pb.ListEventsResponse{
+		Next: next,
+		Data: make([]*pb.Event, 0, len(data)),
+	}
+	for _, e := range data {
+		epb, err := encoding.EventPB(e)
+		if err != nil {
+			s.logger.Warnf("service.event.finder: [peer=%s] listevents(): %v", getPeerAddr(ctx), err)
+			return nil, s.mapError(err)
+		}
+		resp.Data = append(resp.Data, epb)
+	}
+	return resp, nil
+}
+
+//mapping errors
+func (s *Service) mapError(err error) error {
+	switch err {
+	case event.ErrCanceledRequest:
+		return status.Error(codes.Canceled, err.Error())
+	case event.ErrBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case event.ErrUnauthorized:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case event.ErrNotSupported:
+		return status.Error(codes.Unimplemented, err.Error())
+	case event.ErrUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, event.ErrInternal.Error())
+	}
+}
+
+func getPeerAddr(ctx context.Context) (paddr string) {
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		paddr = p.Addr.String()
+	}
+	return
+}
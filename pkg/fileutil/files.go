@@ -0,0 +1,152 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package fileutil
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	extJSONL   = ".jsonl"
+	extGzip    = ".gz"
+	timeLayout = "20060102T1504"
+)
+
+// FileInfo stores information about an archived file.
+type FileInfo struct {
+	Path  string
+	Name  string
+	Start time.Time
+	Seq   int
+}
+
+// Files returns archived files with prefix in dir sorted by start time and
+// sequence. If a file exists compressed and uncompressed, the compressed
+// one is returned.
+func Files(dir, prefix string) ([]FileInfo, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading dir '%s': %v", dir, err)
+	}
+	found := make(map[string]FileInfo)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		fname := e.Name()
+		compressed := strings.HasSuffix(fname, extJSONL+extGzip)
+		if !compressed && !strings.HasSuffix(fname, extJSONL) {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(fname, extGzip), extJSONL)
+		start, seq, ok := parseFileName(prefix, base)
+		if !ok {
+			continue
+		}
+		if _, dup := found[base]; dup && !compressed {
+			continue
+		}
+		found[base] = FileInfo{
+			Path:  filepath.Join(dir, fname),
+			Name:  base,
+			Start: start,
+			Seq:   seq,
+		}
+	}
+	files := make([]FileInfo, 0, len(found))
+	for _, f := range found {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Start.Equal(files[j].Start) {
+			return files[i].Seq < files[j].Seq
+		}
+		return files[i].Start.Before(files[j].Start)
+	})
+	return files, nil
+}
+
+// Open opens an archived file, decompressing it if required. If an
+// uncompressed file has been compressed since it was listed, the compressed
+// one is opened.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && strings.HasSuffix(path, extJSONL) {
+		path = path + extGzip
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, extGzip) {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: zr, file: f}, nil
+}
+
+// ScanLines reads the lines of an archived file and calls fn with the
+// number of line, starting at zero, and its content. The content is only
+// valid until fn returns. Reading stops when fn returns false.
+func ScanLines(path string, fn func(n int, line []byte) bool) error {
+	r, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 0; scanner.Scan(); n++ {
+		if !fn(n, scanner.Bytes()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+func fileName(prefix string, start time.Time, seq int) string {
+	return fmt.Sprintf("%s-%s-%04d", prefix, start.UTC().Format(timeLayout), seq)
+}
+
+func parseFileName(prefix, base string) (time.Time, int, bool) {
+	if !strings.HasPrefix(base, prefix+"-") {
+		return time.Time{}, 0, false
+	}
+	fields := strings.Split(strings.TrimPrefix(base, prefix+"-"), "-")
+	if len(fields) != 2 {
+		return time.Time{}, 0, false
+	}
+	start, err := time.Parse(timeLayout, fields[0])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	seq, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return start, seq, true
+}
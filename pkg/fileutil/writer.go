@@ -0,0 +1,238 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package fileutil provides utilities to archive data in json lines files.
+//
+// This package is a work in progress and makes no API stability promises.
+package fileutil
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Writer appends json lines to files rotated by period and size. Rotated
+// files can be compressed with gzip, compression is done in background.
+type Writer struct {
+	mutex    sync.Mutex
+	dir      string
+	prefix   string
+	period   time.Duration
+	maxSize  int64
+	compress bool
+	// current file
+	file    *os.File
+	buf     *bufio.Writer
+	start   time.Time
+	seq     int
+	written int64
+	// background compression
+	compressWg  sync.WaitGroup
+	compressErr error
+	// renames of compressed files are done holding the write lock, so
+	// listings don't miss files
+	filesMu sync.RWMutex
+}
+
+// NewWriter returns a new writer for files with prefix in dir.
+// If maxSize is zero, files are only rotated by period.
+func NewWriter(dir, prefix string, period time.Duration, maxSize int64, compress bool) *Writer {
+	return &Writer{
+		dir:      dir,
+		prefix:   prefix,
+		period:   period,
+		maxSize:  maxSize,
+		compress: compress,
+	}
+}
+
+// Write encodes v as a json line and appends it to the current file.
+func (w *Writer) Write(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding json: %v", err)
+	}
+	line = append(line, '\n')
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now().UTC()
+	if w.file == nil || !now.Truncate(w.period).Equal(w.start) ||
+		(w.maxSize > 0 && w.written+int64(len(line)) > w.maxSize) {
+		err = w.rotate(now)
+		if err != nil {
+			return err
+		}
+	}
+	n, err := w.buf.Write(line)
+	w.written += int64(n)
+	return err
+}
+
+// Flush writes buffered data to the current file. It also returns the
+// error of the last compression that failed, if any.
+func (w *Writer) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.takeCompressErr(); err != nil {
+		return err
+	}
+	if w.file == nil {
+		return nil
+	}
+	return w.buf.Flush()
+}
+
+// Files flushes buffered data and returns the files written, see Files.
+func (w *Writer) Files() ([]FileInfo, error) {
+	err := w.Flush()
+	if err != nil {
+		return nil, err
+	}
+	w.filesMu.RLock()
+	defer w.filesMu.RUnlock()
+	return Files(w.dir, w.prefix)
+}
+
+// Close flushes and closes the current file. It waits until pending
+// compressions are finished.
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	err := w.closeCurrent()
+	w.mutex.Unlock()
+
+	w.compressWg.Wait()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if cerr := w.takeCompressErr(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *Writer) rotate(now time.Time) error {
+	err := w.closeCurrent()
+	if err != nil {
+		return err
+	}
+	start := now.Truncate(w.period)
+	seq := 0
+	if start.Equal(w.start) {
+		seq = w.seq + 1
+	} else {
+		// continue sequence if there are files of this period
+		w.filesMu.RLock()
+		files, err := Files(w.dir, w.prefix)
+		w.filesMu.RUnlock()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.Start.Equal(start) && f.Seq >= seq {
+				seq = f.Seq + 1
+			}
+		}
+	}
+	path := filepath.Join(w.dir, fileName(w.prefix, start, seq)+extJSONL)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("opening '%s': %v", path, err)
+	}
+	w.file = file
+	w.buf = bufio.NewWriter(file)
+	w.start = start
+	w.seq = seq
+	w.written = 0
+	return nil
+}
+
+func (w *Writer) closeCurrent() error {
+	if w.file == nil {
+		return nil
+	}
+	path := w.file.Name()
+	err := w.buf.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing '%s': %v", path, err)
+	}
+	err = w.file.Close()
+	if err != nil {
+		return fmt.Errorf("closing '%s': %v", path, err)
+	}
+	w.file, w.buf = nil, nil
+	if w.compress {
+		w.compressWg.Add(1)
+		go func() {
+			defer w.compressWg.Done()
+			err := w.compressFile(path)
+			if err != nil {
+				w.mutex.Lock()
+				w.compressErr = err
+				w.mutex.Unlock()
+			}
+		}()
+	}
+	return nil
+}
+
+// takeCompressErr returns the error of the last compression that failed
+// and clears it. It must be called holding the lock.
+func (w *Writer) takeCompressErr() error {
+	err := w.compressErr
+	w.compressErr = nil
+	return err
+}
+
+// compressFile compresses path to a temporal file that is renamed when
+// finished, so readers never see an incomplete compressed file.
+func (w *Writer) compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("compressing '%s': %v", path, err)
+	}
+	defer src.Close()
+	tmpPath := path + extGzip + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("compressing '%s': %v", path, err)
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("compressing '%s': %v", path, err)
+	}
+	w.filesMu.Lock()
+	defer w.filesMu.Unlock()
+	err = os.Rename(tmpPath, path+extGzip)
+	if err != nil {
+		return fmt.Errorf("compressing '%s': %v", path, err)
+	}
+	return os.Remove(path)
+}
+
+// ParsePeriod returns the rotation period from its name.
+func ParsePeriod(s string) (time.Duration, error) {
+	switch s {
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	}
+	return 0, errors.New("invalid period")
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package fileutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir, "test", time.Hour, 30, true)
+	for i := 0; i < 10; i++ {
+		if err := w.Write(map[string]int{"n": i}); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	files, err := Files(dir, "test")
+	if err != nil {
+		t.Fatalf("Files(): %v", err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("Files() returned %v files, want rotated files", len(files))
+	}
+	var lines []string
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, extJSONL+extGzip) {
+			t.Errorf("file '%s' is not compressed", f.Path)
+		}
+		err := ScanLines(f.Path, func(_ int, line []byte) bool {
+			lines = append(lines, string(line))
+			return true
+		})
+		if err != nil {
+			t.Fatalf("ScanLines(): %v", err)
+		}
+	}
+	if len(lines) != 10 || lines[0] != `{"n":0}` || lines[9] != `{"n":9}` {
+		t.Errorf("lines = %v", lines)
+	}
+}
+
+func TestScanLinesStop(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir, "test", time.Hour, 0, false)
+	for i := 0; i < 5; i++ {
+		w.Write(i)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	files, _ := Files(dir, "test")
+	if len(files) != 1 {
+		t.Fatalf("Files() returned %v files, want 1", len(files))
+	}
+	var got []int
+	err := ScanLines(files[0].Path, func(n int, _ []byte) bool {
+		got = append(got, n)
+		return n < 2
+	})
+	if err != nil {
+		t.Fatalf("ScanLines(): %v", err)
+	}
+	if len(got) != 3 || got[2] != 2 {
+		t.Errorf("ScanLines() read lines %v, want [0 1 2]", got)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongodriverutil
+
+import (
+	"context"
+	"time"
+
+	mgobson "github.com/globalsign/mgo/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/luids-io/archive/pkg/mongoutil"
+)
+
+// DefaultTimeout is used in operations without context.
+const DefaultTimeout = 30 * time.Second
+
+// NewBulk returns a new bulk for collection with size.
+func NewBulk(c *mongo.Collection, size int) *mongoutil.Bulk {
+	return mongoutil.NewBulkInserter(Inserter{col: c}, size)
+}
+
+// NewPipeline returns a pipeline for collection with queue depth, workers
+// and bulks of size. Errors of flushes made by workers are passed to onError.
+func NewPipeline(c *mongo.Collection, depth, workers, size int, policy mongoutil.QueuePolicy, onError func(error)) *mongoutil.Pipeline {
+	return mongoutil.NewPipelineInserter(Inserter{col: c}, depth, workers, size, policy, onError)
+}
+
+// Inserter implements mongoutil.Inserter using the official driver.
+type Inserter struct {
+	col *mongo.Collection
+}
+
+// NewInserter returns an inserter for the collection.
+func NewInserter(c *mongo.Collection) Inserter {
+	return Inserter{col: c}
+}
+
+// Name implements mongoutil.Inserter interface.
+func (i Inserter) Name() string {
+	return FullName(i.col)
+}
+
+// Insert implements mongoutil.Inserter interface.
+func (i Inserter) Insert(docs []interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	_, err := i.col.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// Replay implements mongoutil.Inserter interface. Documents are raw
+// documents read from the spool.
+func (i Inserter) Replay(docs []interface{}) error {
+	raws := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		if raw, ok := doc.(mgobson.Raw); ok {
+			raws = append(raws, bson.Raw(raw.Data))
+			continue
+		}
+		raws = append(raws, doc)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	_, err := i.col.InsertMany(ctx, raws, options.InsertMany().SetOrdered(false))
+	// batch could be partially inserted before the failure
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// Encode implements mongoutil.Inserter interface. Documents are encoded by
+// this driver and stored as raw documents, the spool writes them as they
+// are.
+func (i Inserter) Encode(docs []interface{}) ([]interface{}, error) {
+	raws := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, mgobson.Raw{Kind: 0x03, Data: data})
+	}
+	return raws, nil
+}
+
+// IsUnavailable implements mongoutil.Inserter interface.
+func (i Inserter) IsUnavailable(err error) bool {
+	return IsUnavailable(err)
+}
+
+// FullName returns the name of the collection with the database name.
+func FullName(c *mongo.Collection) string {
+	return c.Database().Name() + "." + c.Name()
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongodriverutil
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/luids-io/archive/pkg/mongoutil"
+)
+
+// NewMonitor creates and starts a monitor of the client. The driver
+// reconnects by itself, so the monitor only marks the client as unavailable
+// until a ping succeeds. Function onChange is called when the availability
+// of the client changes.
+func NewMonitor(client *mongo.Client, interval, maxBackoff time.Duration, onChange func(bool, error)) *mongoutil.Monitor {
+	timeout := interval
+	if timeout <= 0 {
+		timeout = mongoutil.DefaultCheckInterval
+	}
+	ping := func(bool) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return client.Ping(ctx, nil)
+	}
+	return mongoutil.NewMonitorFunc(ping, IsUnavailable, interval, maxBackoff, onChange)
+}
+
+// IsUnavailable returns true if the error is caused by a lost connection
+// or a server that is not available for the operation.
+func IsUnavailable(err error) bool {
+	if err == nil || err == mongo.ErrNoDocuments {
+		return false
+	}
+	if errors.Is(err, mongo.ErrClientDisconnected) {
+		return true
+	}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+	return mongoutil.IsUnavailable(err)
+}
@@ -4,43 +4,101 @@ package mongoutil
 
 import (
 	"sync"
+	"time"
 
 	"github.com/globalsign/mgo"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
 )
 
+// Inserter inserts batches of documents in a collection using a driver.
+// Bulk and Pipeline don't depend on the driver used.
+type Inserter interface {
+	// Name returns the name of the collection with the database name.
+	Name() string
+	// Insert the documents in order.
+	Insert(docs []interface{}) error
+	// Replay inserts documents read from the spool, documents already
+	// inserted must be ignored.
+	Replay(docs []interface{}) error
+	// Encode returns the documents in a form that can be stored in the
+	// spool.
+	Encode(docs []interface{}) ([]interface{}, error)
+	// IsUnavailable returns true if the error is caused by an unavailable
+	// database.
+	IsUnavailable(err error) bool
+}
+
+// StampFunc is called just before a document is inserted, also when it is
+// replayed from the spool (in this case as a bson.Raw). It returns the
+// document to insert.
+type StampFunc func(doc interface{}) (interface{}, error)
+
 // Bulk is used for massive inserts
 type Bulk struct {
 	mutex sync.Mutex
-	col   *mgo.Collection
-	bulk  *mgo.Bulk
-	count int
+	ins   Inserter
+	docs  []interface{}
 	size  int
+	spool *Spool
+	stamp StampFunc
+	// metric labels
+	service    string
+	collection string
 }
 
 // NewBulk returns a new bulk for collection with size
 func NewBulk(c *mgo.Collection, size int) *Bulk {
+	return NewBulkInserter(mgoInserter{col: c}, size)
+}
+
+// NewBulkInserter returns a new bulk that uses the inserter.
+func NewBulkInserter(ins Inserter, size int) *Bulk {
 	bk := &Bulk{
-		col:  c,
-		bulk: c.Bulk(),
-		size: size,
+		ins:        ins,
+		docs:       make([]interface{}, 0, size),
+		size:       size,
+		collection: ins.Name(),
 	}
 	return bk
 }
 
-// Insert a doc in the bulk
+// SetSpool sets a spool where batches are stored when flush fails.
+func (bk *Bulk) SetSpool(s *Spool) {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+	bk.spool = s
+}
+
+// SetStamp sets a function that modifies documents before inserting them.
+func (bk *Bulk) SetStamp(fn StampFunc) {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+	bk.stamp = fn
+}
+
+// SetMetrics sets the labels of the metrics. Inserted documents are
+// counted for service only if it isn't empty. Collection should be the
+// logical name, without database, prefix or partition suffix, so the
+// number of series is bounded.
+func (bk *Bulk) SetMetrics(service, collection string) {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+	bk.service = service
+	bk.collection = collection
+}
+
+// Insert a doc in the bulk. If spool is full, it returns ErrSpoolFull.
 func (bk *Bulk) Insert(doc interface{}) error {
 	bk.mutex.Lock()
 	defer bk.mutex.Unlock()
 
-	bk.bulk.Insert(doc)
-	bk.count++
-	if bk.count >= bk.size {
-		_, err := bk.bulk.Run()
-		if err != nil {
-			return err
-		}
-		bk.count = 0
-		bk.bulk = bk.col.Bulk()
+	if bk.spool != nil && bk.spool.Full() {
+		return ErrSpoolFull
+	}
+	bk.docs = append(bk.docs, doc)
+	if len(bk.docs) >= bk.size {
+		return bk.run()
 	}
 	return nil
 }
@@ -50,13 +108,148 @@ func (bk *Bulk) Flush() error {
 	bk.mutex.Lock()
 	defer bk.mutex.Unlock()
 
-	if bk.count > 0 {
-		_, err := bk.bulk.Run()
+	if len(bk.docs) > 0 {
+		return bk.run()
+	}
+	return nil
+}
+
+// Close flushes the bulk.
+func (bk *Bulk) Close() error {
+	return bk.Flush()
+}
+
+// Pending returns true if there are batches in the spool.
+func (bk *Bulk) Pending() bool {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+	return bk.spool != nil && !bk.spool.Empty()
+}
+
+// Replay inserts in collection the batches stored in the spool. It returns
+// the number of documents replayed.
+func (bk *Bulk) Replay() (int, error) {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+
+	if bk.spool == nil {
+		return 0, nil
+	}
+	n, err := bk.spool.Replay(func(docs []interface{}) error {
+		docs, err := bk.stampDocs(docs)
 		if err != nil {
 			return err
 		}
-		bk.count = 0
-		bk.bulk = bk.col.Bulk()
+		return bk.ins.Replay(docs)
+	}, bk.ins.IsUnavailable)
+	bk.countDocs(n)
+	return n, err
+}
+
+// run inserts the documents, the bulk is always reset. If there is a spool
+// and it has pending batches, documents are spooled to keep the order.
+// Only batches that fail because the database is unavailable are spooled,
+// other errors are permanent and the batch is discarded.
+func (bk *Bulk) run() error {
+	defer bk.reset()
+	if bk.spool != nil {
+		if !bk.spool.Empty() {
+			return bk.spoolDocs()
+		}
+		err := bk.runBulk()
+		if err != nil && bk.ins.IsUnavailable(err) {
+			return bk.spoolDocs()
+		}
+		return err
+	}
+	return bk.runBulk()
+}
+
+func (bk *Bulk) runBulk() error {
+	docs, err := bk.stampDocs(bk.docs)
+	if err != nil {
+		metrics.BulkFlushErrors.WithLabelValues(bk.collection).Inc()
+		return err
+	}
+	start := time.Now()
+	err = bk.ins.Insert(docs)
+	if err != nil {
+		metrics.BulkFlushErrors.WithLabelValues(bk.collection).Inc()
+		return err
+	}
+	metrics.BulkFlushDuration.WithLabelValues(bk.collection).Observe(time.Since(start).Seconds())
+	metrics.BulkFlushSize.WithLabelValues(bk.collection).Observe(float64(len(bk.docs)))
+	bk.countDocs(len(bk.docs))
+	return nil
+}
+
+// countDocs adds the documents inserted to the documents metric.
+func (bk *Bulk) countDocs(n int) {
+	if bk.service != "" && n > 0 {
+		metrics.Documents.WithLabelValues(bk.service, bk.collection).Add(float64(n))
+	}
+}
+
+func (bk *Bulk) spoolDocs() error {
+	docs, err := bk.ins.Encode(bk.docs)
+	if err != nil {
+		return err
+	}
+	return bk.spool.Write(docs)
+}
+
+func (bk *Bulk) stampDocs(docs []interface{}) ([]interface{}, error) {
+	if bk.stamp == nil {
+		return docs, nil
+	}
+	stamped := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		s, err := bk.stamp(doc)
+		if err != nil {
+			return nil, err
+		}
+		stamped = append(stamped, s)
+	}
+	return stamped, nil
+}
+
+func (bk *Bulk) reset() {
+	bk.docs = make([]interface{}, 0, bk.size)
+}
+
+// mgoInserter implements Inserter using mgo driver.
+type mgoInserter struct {
+	col *mgo.Collection
+}
+
+func (i mgoInserter) Name() string {
+	return i.col.FullName
+}
+
+func (i mgoInserter) Insert(docs []interface{}) error {
+	b := i.col.Bulk()
+	b.Insert(docs...)
+	_, err := b.Run()
+	return err
+}
+
+func (i mgoInserter) Replay(docs []interface{}) error {
+	b := i.col.Bulk()
+	b.Unordered()
+	b.Insert(docs...)
+	_, err := b.Run()
+	// batch could be partially inserted before the failure
+	if err != nil && !mgo.IsDup(err) {
+		return err
 	}
 	return nil
 }
+
+// Encode returns the documents, the spool uses the same encoding.
+func (i mgoInserter) Encode(docs []interface{}) ([]interface{}, error) {
+	return docs, nil
+}
+
+func (i mgoInserter) IsUnavailable(err error) bool {
+	return IsUnavailable(err)
+}
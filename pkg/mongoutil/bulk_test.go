@@ -0,0 +1,146 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
+)
+
+// testInserter counts the documents inserted, inserts fail with err if set
+// and wait for block if it isn't nil.
+type testInserter struct {
+	mu       sync.Mutex
+	err      error
+	inserted int
+	block    chan struct{}
+}
+
+func (i *testInserter) Name() string { return "db.test_20210310" }
+
+func (i *testInserter) Insert(docs []interface{}) error {
+	if i.block != nil {
+		<-i.block
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.err != nil {
+		return i.err
+	}
+	i.inserted += len(docs)
+	return nil
+}
+
+func (i *testInserter) setErr(err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.err = err
+}
+
+func (i *testInserter) count() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.inserted
+}
+
+func (i *testInserter) Replay(docs []interface{}) error { return i.Insert(docs) }
+
+func (i *testInserter) Encode(docs []interface{}) ([]interface{}, error) { return docs, nil }
+
+func (i *testInserter) IsUnavailable(err error) bool { return isRetry(err) }
+
+func TestBulkDocumentsMetric(t *testing.T) {
+	// metrics are global, so deltas are checked
+	docs := metrics.Documents.WithLabelValues("bulktest", "test")
+	base := testutil.ToFloat64(docs)
+	errsBase := testutil.ToFloat64(metrics.BulkFlushErrors.WithLabelValues("test"))
+	ins := &testInserter{}
+	bk := NewBulkInserter(ins, 3)
+	bk.SetMetrics("bulktest", "test")
+	for i := 0; i < 2; i++ {
+		if err := bk.Insert(bson.M{"n": i}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(docs) - base; got != 0 {
+		t.Errorf("documents before flush = %v, want 0", got)
+	}
+	if err := bk.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := testutil.ToFloat64(docs) - base; got != 2 {
+		t.Errorf("documents after flush = %v, want 2", got)
+	}
+	// failed inserts aren't counted
+	ins.err = errPermanent
+	bk.Insert(bson.M{"n": 2})
+	if err := bk.Flush(); err != errPermanent {
+		t.Fatalf("flush err = %v, want %v", err, errPermanent)
+	}
+	if got := testutil.ToFloat64(docs) - base; got != 2 {
+		t.Errorf("documents after failed flush = %v, want 2", got)
+	}
+	errs := testutil.ToFloat64(metrics.BulkFlushErrors.WithLabelValues("test")) - errsBase
+	if errs != 1 {
+		t.Errorf("flush errors = %v, want 1", errs)
+	}
+}
+
+func TestBulkDocumentsReplay(t *testing.T) {
+	docs := metrics.Documents.WithLabelValues("bulkreplay", "test")
+	base := testutil.ToFloat64(docs)
+	s, err := NewSpool(t.TempDir(), "test", 0)
+	if err != nil {
+		t.Fatalf("new spool: %v", err)
+	}
+	ins := &testInserter{err: errRetry}
+	bk := NewBulkInserter(ins, 2)
+	bk.SetSpool(s)
+	bk.SetMetrics("bulkreplay", "test")
+	for i := 0; i < 4; i++ {
+		if err := bk.Insert(bson.M{"n": i}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(docs) - base; got != 0 {
+		t.Errorf("documents spooled = %v, want 0", got)
+	}
+	ins.err = nil
+	n, err := bk.Replay()
+	if err != nil || n != 4 {
+		t.Fatalf("replay = %v, %v; want 4", n, err)
+	}
+	if got := testutil.ToFloat64(docs) - base; got != 4 {
+		t.Errorf("documents after replay = %v, want 4", got)
+	}
+}
+
+func TestBulkPendingConcurrent(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), "test", 0)
+	if err != nil {
+		t.Fatalf("new spool: %v", err)
+	}
+	ins := &testInserter{err: errRetry}
+	bk := NewBulkInserter(ins, 1)
+	// checked by the race detector
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			bk.Pending()
+		}
+	}()
+	bk.SetSpool(s)
+	if err := bk.Insert(bson.M{"n": 1}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	<-done
+	if !bk.Pending() {
+		t.Error("Pending() = false, want true")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+package mongoutil
+
+import (
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/globalsign/mgo"
+)
+
+// Health is implemented by components that report the availability of a
+// database.
+type Health interface {
+	Available() bool
+	// Report informs about an error returned by the database.
+	Report(err error)
+}
+
+// Default values for monitors.
+const (
+	DefaultCheckInterval = 5 * time.Second
+	DefaultMinBackoff    = time.Second
+	DefaultMaxBackoff    = 30 * time.Second
+)
+
+// Monitor checks periodically the health of a database. When the
+// connection is lost, it marks the database as unavailable and checks it
+// using an exponential backoff until the connection is restored.
+type Monitor struct {
+	ping        PingFunc
+	unavailable func(error) bool
+	interval    time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	onChange    func(available bool, err error)
+
+	down    int32
+	checkc  chan struct{}
+	close   chan struct{}
+	closeWg sync.WaitGroup
+}
+
+// PingFunc checks the connection with a database. If refresh is true,
+// connections must be discarded before the check.
+type PingFunc func(refresh bool) error
+
+// NewMonitor creates and starts a monitor of the session. Function onChange
+// is called when the availability of the session changes.
+func NewMonitor(session *mgo.Session, interval, maxBackoff time.Duration, onChange func(bool, error)) *Monitor {
+	ping := func(refresh bool) error {
+		if refresh {
+			session.Refresh()
+		}
+		return session.Ping()
+	}
+	return NewMonitorFunc(ping, IsUnavailable, interval, maxBackoff, onChange)
+}
+
+// NewMonitorFunc creates and starts a monitor that uses ping to check the
+// database. Errors reported are checked with unavailable.
+func NewMonitorFunc(ping PingFunc, unavailable func(error) bool, interval, maxBackoff time.Duration, onChange func(bool, error)) *Monitor {
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	if maxBackoff < DefaultMinBackoff {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if onChange == nil {
+		onChange = func(bool, error) {}
+	}
+	m := &Monitor{
+		ping:        ping,
+		unavailable: unavailable,
+		interval:    interval,
+		minBackoff:  DefaultMinBackoff,
+		maxBackoff:  maxBackoff,
+		onChange:    onChange,
+		checkc:      make(chan struct{}, 1),
+		close:       make(chan struct{}),
+	}
+	m.closeWg.Add(1)
+	go m.run()
+	return m
+}
+
+// Available returns true if the connection is not lost.
+func (m *Monitor) Available() bool {
+	return atomic.LoadInt32(&m.down) == 0
+}
+
+// Report forces a check of the connection if the error is caused by an
+// unavailable database.
+func (m *Monitor) Report(err error) {
+	if !m.unavailable(err) {
+		return
+	}
+	select {
+	case m.checkc <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the monitor.
+func (m *Monitor) Close() {
+	close(m.close)
+	m.closeWg.Wait()
+}
+
+func (m *Monitor) run() {
+	defer m.closeWg.Done()
+	tick := time.NewTicker(m.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			m.check(false)
+		case <-m.checkc:
+			m.check(true)
+		case <-m.close:
+			return
+		}
+	}
+}
+
+// check pings the database and waits until it is restored if it fails.
+func (m *Monitor) check(reported bool) {
+	// errors like "not master" require discarding sockets
+	err := m.ping(reported)
+	if err == nil {
+		return
+	}
+	atomic.StoreInt32(&m.down, 1)
+	m.onChange(false, err)
+	backoff := m.minBackoff
+	for {
+		select {
+		case <-time.After(backoff):
+		case <-m.close:
+			return
+		}
+		err = m.ping(true)
+		if err == nil {
+			atomic.StoreInt32(&m.down, 0)
+			m.onChange(true, nil)
+			return
+		}
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+// IsUnavailable returns true if the error is caused by a lost connection
+// or a server that is not available for the operation.
+func IsUnavailable(err error) bool {
+	if err == nil || err == mgo.ErrNotFound {
+		return false
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	msg := err.Error()
+	for _, s := range []string{
+		"no reachable servers",
+		"Closed explicitly",
+		"not master",
+		"node is recovering",
+		"connection refused",
+		"connection reset",
+		"i/o timeout",
+		"server selection",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
+)
+
+// Writer is the interface implemented by Bulk and Pipeline.
+type Writer interface {
+	Insert(doc interface{}) error
+	Flush() error
+	Close() error
+	SetSpool(s *Spool)
+	SetStamp(fn StampFunc)
+	SetMetrics(service, collection string)
+	Pending() bool
+	Replay() (int, error)
+}
+
+// Pipeline errors.
+var (
+	ErrQueueFull = errors.New("queue is full")
+	ErrClosed    = errors.New("pipeline is closed")
+)
+
+// QueuePolicy defines the behaviour of Insert when queue is full.
+type QueuePolicy int
+
+// QueuePolicy values.
+const (
+	// Block waits until there is space in the queue.
+	Block QueuePolicy = iota
+	// Drop returns ErrQueueFull and the document is discarded.
+	Drop
+)
+
+func (p QueuePolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case Drop:
+		return "drop"
+	}
+	return fmt.Sprintf("unknown(%d)", p)
+}
+
+// ParseQueuePolicy returns policy from string.
+func ParseQueuePolicy(s string) (QueuePolicy, error) {
+	switch s {
+	case "block":
+		return Block, nil
+	case "drop":
+		return Drop, nil
+	}
+	return Block, fmt.Errorf("invalid queue policy '%s'", s)
+}
+
+// Pipeline inserts documents asynchronously. Documents are queued in a
+// bounded channel and inserted by workers, each one with its own bulk.
+type Pipeline struct {
+	name    string
+	size    int
+	policy  QueuePolicy
+	onError func(error)
+	queue   chan interface{}
+	workers []*pipelineWorker
+	spool   *Spool
+	replay  *Bulk
+	//control
+	mu     sync.RWMutex
+	closed bool
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// spoolRetryInterval is the interval used by pipeline workers to retry
+// inserts of documents while the spool is full.
+var spoolRetryInterval = time.Second
+
+type pipelineWorker struct {
+	bulk   *Bulk
+	flushc chan chan error
+}
+
+// NewPipeline returns a pipeline for collection with queue depth, workers
+// and bulks of size. Errors of flushes made by workers are passed to onError.
+func NewPipeline(c *mgo.Collection, depth, workers, size int, policy QueuePolicy, onError func(error)) *Pipeline {
+	return NewPipelineInserter(mgoInserter{col: c}, depth, workers, size, policy, onError)
+}
+
+// NewPipelineInserter returns a pipeline that uses the inserter, the
+// inserter is shared by the workers.
+func NewPipelineInserter(ins Inserter, depth, workers, size int, policy QueuePolicy, onError func(error)) *Pipeline {
+	if workers <= 0 {
+		workers = 1
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	p := &Pipeline{
+		name:    ins.Name(),
+		size:    size,
+		policy:  policy,
+		onError: onError,
+		queue:   make(chan interface{}, depth),
+		stop:    make(chan struct{}),
+		workers: make([]*pipelineWorker, 0, workers),
+		replay:  NewBulkInserter(ins, size),
+	}
+	for i := 0; i < workers; i++ {
+		w := &pipelineWorker{
+			bulk:   NewBulkInserter(ins, size),
+			flushc: make(chan chan error),
+		}
+		p.workers = append(p.workers, w)
+		p.wg.Add(1)
+		go p.doWork(w)
+	}
+	return p
+}
+
+// Insert queues a doc.
+func (p *Pipeline) Insert(doc interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	if p.spool != nil && p.spool.Full() {
+		return ErrSpoolFull
+	}
+	if p.policy == Drop {
+		select {
+		case p.queue <- doc:
+			metrics.QueueDepth.WithLabelValues(p.name).Set(float64(len(p.queue)))
+			return nil
+		default:
+			metrics.QueueDropped.WithLabelValues(p.name).Inc()
+			return ErrQueueFull
+		}
+	}
+	p.queue <- doc
+	metrics.QueueDepth.WithLabelValues(p.name).Set(float64(len(p.queue)))
+	return nil
+}
+
+// Flush the bulks of the workers, queued documents are not flushed.
+func (p *Pipeline) Flush() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return nil
+	}
+	var first error
+	for _, w := range p.workers {
+		reply := make(chan error)
+		w.flushc <- reply
+		err := <-reply
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close stops accepting documents and waits until workers insert the
+// queued documents. Documents that can't be inserted or spooled because
+// the spool is full are dropped.
+func (p *Pipeline) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	close(p.stop)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return nil
+}
+
+// SetSpool sets a spool shared by the bulks of the workers. It must be
+// called before inserting documents.
+func (p *Pipeline) SetSpool(s *Spool) {
+	p.spool = s
+	p.replay.SetSpool(s)
+	for _, w := range p.workers {
+		w.bulk.SetSpool(s)
+	}
+}
+
+// SetStamp sets the function used by the bulks of the workers. It must be
+// called before inserting documents.
+func (p *Pipeline) SetStamp(fn StampFunc) {
+	p.replay.SetStamp(fn)
+	for _, w := range p.workers {
+		w.bulk.SetStamp(fn)
+	}
+}
+
+// SetMetrics sets the labels of the metrics of the pipeline and its bulks.
+// It must be called before inserting documents.
+func (p *Pipeline) SetMetrics(service, collection string) {
+	p.name = collection
+	p.replay.SetMetrics(service, collection)
+	for _, w := range p.workers {
+		w.bulk.SetMetrics(service, collection)
+	}
+}
+
+// Pending returns true if there are batches in the spool.
+func (p *Pipeline) Pending() bool {
+	return p.replay.Pending()
+}
+
+// Replay inserts in collection the batches stored in the spool.
+func (p *Pipeline) Replay() (int, error) {
+	return p.replay.Replay()
+}
+
+func (p *Pipeline) doWork(w *pipelineWorker) {
+	defer p.wg.Done()
+	for {
+		select {
+		case doc, ok := <-p.queue:
+			if !ok {
+				err := w.bulk.Flush()
+				if err != nil {
+					p.onError(err)
+				}
+				return
+			}
+			metrics.QueueDepth.WithLabelValues(p.name).Set(float64(len(p.queue)))
+			p.insert(w, doc)
+		case reply := <-w.flushc:
+			reply <- w.bulk.Flush()
+		}
+	}
+}
+
+// insert adds the doc to the bulk of the worker. Documents were accepted
+// by the pipeline, so if the spool is full the insert is retried until the
+// spool is replayed. Flushes are served meanwhile, because replays are done
+// after them. If the pipeline is closed, the doc is dropped.
+func (p *Pipeline) insert(w *pipelineWorker, doc interface{}) {
+	for {
+		err := w.bulk.Insert(doc)
+		if err == nil {
+			return
+		}
+		if err != ErrSpoolFull {
+			p.onError(err)
+			return
+		}
+		retry := time.NewTimer(spoolRetryInterval)
+	WAIT:
+		for {
+			select {
+			case <-retry.C:
+				break WAIT
+			case reply := <-w.flushc:
+				reply <- w.bulk.Flush()
+			case <-p.stop:
+				retry.Stop()
+				metrics.QueueDropped.WithLabelValues(p.name).Inc()
+				p.onError(err)
+				return
+			}
+		}
+	}
+}
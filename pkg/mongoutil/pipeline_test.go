@@ -0,0 +1,181 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
+)
+
+// waitFor polls cond until it returns true.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPipelineDrop(t *testing.T) {
+	dropped := metrics.QueueDropped.WithLabelValues("pipedrop")
+	base := testutil.ToFloat64(dropped)
+	ins := &testInserter{block: make(chan struct{})}
+	p := NewPipelineInserter(ins, 1, 1, 1, Drop, nil)
+	p.SetMetrics("", "pipedrop")
+	// worker blocks inserting the first doc and the second one fills the queue
+	if err := p.Insert(bson.M{"n": 1}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	waitFor(t, "worker", func() bool { return len(p.queue) == 0 })
+	if err := p.Insert(bson.M{"n": 2}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := p.Insert(bson.M{"n": 3}); err != ErrQueueFull {
+		t.Errorf("insert in full queue err = %v, want %v", err, ErrQueueFull)
+	}
+	if got := testutil.ToFloat64(dropped) - base; got != 1 {
+		t.Errorf("dropped = %v, want 1", got)
+	}
+	close(ins.block)
+	p.Close()
+	if got := ins.count(); got != 2 {
+		t.Errorf("inserted = %v, want 2", got)
+	}
+}
+
+func TestPipelineBlock(t *testing.T) {
+	ins := &testInserter{block: make(chan struct{})}
+	p := NewPipelineInserter(ins, 1, 1, 1, Block, nil)
+	p.SetMetrics("", "pipeblock")
+	if err := p.Insert(bson.M{"n": 1}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	waitFor(t, "worker", func() bool { return len(p.queue) == 0 })
+	if err := p.Insert(bson.M{"n": 2}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	done := make(chan error)
+	go func() { done <- p.Insert(bson.M{"n": 3}) }()
+	select {
+	case err := <-done:
+		t.Fatalf("insert in full queue returned %v, want blocked", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(ins.block)
+	if err := <-done; err != nil {
+		t.Errorf("blocked insert err = %v", err)
+	}
+	p.Close()
+	if got := ins.count(); got != 3 {
+		t.Errorf("inserted = %v, want 3", got)
+	}
+}
+
+func TestPipelineClose(t *testing.T) {
+	ins := &testInserter{}
+	p := NewPipelineInserter(ins, 100, 2, 10, Block, nil)
+	for i := 0; i < 25; i++ {
+		if err := p.Insert(bson.M{"n": i}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	// queued documents and bulks are inserted
+	if err := p.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := ins.count(); got != 25 {
+		t.Errorf("inserted = %v, want 25", got)
+	}
+	if err := p.Insert(bson.M{"n": 26}); err != ErrClosed {
+		t.Errorf("insert after close err = %v, want %v", err, ErrClosed)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+}
+
+func TestPipelineFlush(t *testing.T) {
+	ins := &testInserter{}
+	p := NewPipelineInserter(ins, 100, 3, 100, Block, nil)
+	defer p.Close()
+	for i := 0; i < 30; i++ {
+		if err := p.Insert(bson.M{"n": i}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	// workers serve flushes after inserting the dequeued doc
+	waitFor(t, "queue", func() bool { return len(p.queue) == 0 })
+	if got := ins.count(); got != 0 {
+		t.Fatalf("inserted before flush = %v, want 0", got)
+	}
+	if err := p.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := ins.count(); got != 30 {
+		t.Errorf("inserted after flush = %v, want 30", got)
+	}
+}
+
+func TestPipelineSpoolFull(t *testing.T) {
+	defer func(d time.Duration) { spoolRetryInterval = d }(spoolRetryInterval)
+	spoolRetryInterval = 5 * time.Millisecond
+	dropped := metrics.QueueDropped.WithLabelValues("pipespool")
+	base := testutil.ToFloat64(dropped)
+
+	// fill spool with the first doc while others are queued
+	setup := func() (*testInserter, *Pipeline) {
+		s, err := NewSpool(t.TempDir(), "test", 1)
+		if err != nil {
+			t.Fatalf("new spool: %v", err)
+		}
+		ins := &testInserter{err: errRetry, block: make(chan struct{})}
+		p := NewPipelineInserter(ins, 10, 1, 1, Block, nil)
+		p.SetSpool(s)
+		p.SetMetrics("", "pipespool")
+		for i := 0; i < 3; i++ {
+			if err := p.Insert(bson.M{"n": i}); err != nil {
+				t.Fatalf("insert: %v", err)
+			}
+		}
+		close(ins.block)
+		waitFor(t, "spool", s.Full)
+		if err := p.Insert(bson.M{"n": 3}); err != ErrSpoolFull {
+			t.Errorf("insert with full spool err = %v, want %v", err, ErrSpoolFull)
+		}
+		return ins, p
+	}
+
+	// accepted docs wait until the spool is replayed
+	ins, p := setup()
+	if err := p.Flush(); err != nil {
+		t.Errorf("flush while waiting: %v", err)
+	}
+	ins.setErr(nil)
+	n, err := p.Replay()
+	if err != nil || n != 1 {
+		t.Fatalf("replay = %v, %v; want 1", n, err)
+	}
+	waitFor(t, "inserts", func() bool { return ins.count() == 3 })
+	p.Close()
+	if got := testutil.ToFloat64(dropped) - base; got != 0 {
+		t.Errorf("dropped = %v, want 0", got)
+	}
+
+	// docs waiting are dropped and counted on close
+	ins, p = setup()
+	p.Close()
+	if got := testutil.ToFloat64(dropped) - base; got != 2 {
+		t.Errorf("dropped on close = %v, want 2", got)
+	}
+	if got := ins.count(); got != 0 {
+		t.Errorf("inserted = %v, want 0", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// DefaultPurgeInterval is the interval between purges.
+const DefaultPurgeInterval = time.Hour
+
+// Purge removes documents from collection with field older than before.
+// It returns the number of documents removed.
+func Purge(c *mgo.Collection, field string, before time.Time) (int, error) {
+	info, err := c.RemoveAll(bson.M{field: bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return info.Removed, nil
+}
+
+// Purger calls periodically a function that removes the documents older
+// than the retention. The first purge is done when it is started.
+type Purger struct {
+	retention time.Duration
+	interval  time.Duration
+	purge     func(before time.Time)
+
+	close   chan struct{}
+	closeWg sync.WaitGroup
+}
+
+// NewPurger creates and starts a purger that calls fn every interval with
+// the time before which documents must be removed.
+func NewPurger(retention, interval time.Duration, fn func(before time.Time)) *Purger {
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+	p := &Purger{
+		retention: retention,
+		interval:  interval,
+		purge:     fn,
+		close:     make(chan struct{}),
+	}
+	p.closeWg.Add(1)
+	go p.run()
+	return p
+}
+
+// Close stops the purger, it waits if a purge is running.
+func (p *Purger) Close() {
+	close(p.close)
+	p.closeWg.Wait()
+}
+
+func (p *Purger) run() {
+	defer p.closeWg.Done()
+	tick := time.NewTicker(p.interval)
+	defer tick.Stop()
+	p.purge(time.Now().Add(-p.retention))
+	for {
+		select {
+		case <-tick.C:
+			p.purge(time.Now().Add(-p.retention))
+		case <-p.close:
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPurger(t *testing.T) {
+	retention := 24 * time.Hour
+	calls := make(chan time.Time, 10)
+	start := time.Now()
+	p := NewPurger(retention, 10*time.Millisecond, func(before time.Time) {
+		select {
+		case calls <- before:
+		default:
+		}
+	})
+	// first purge is done when it starts
+	for i := 0; i < 2; i++ {
+		select {
+		case before := <-calls:
+			if before.After(time.Now().Add(-retention)) || before.Before(start.Add(-retention)) {
+				t.Errorf("purge %v: unexpected before %v", i, before)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("purge %v not called", i)
+		}
+	}
+	p.Close()
+	// no purges after close
+	for len(calls) > 0 {
+		<-calls
+	}
+	time.Sleep(30 * time.Millisecond)
+	if len(calls) > 0 {
+		t.Errorf("purge called after close")
+	}
+}
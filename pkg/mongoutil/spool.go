@@ -0,0 +1,221 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
+)
+
+// ErrSpoolFull is returned when spool size exceeds its limit.
+var ErrSpoolFull = errors.New("spool is full")
+
+// Extensions of segment files.
+const (
+	spoolExt      = ".spool"
+	quarantineExt = ".failed"
+)
+
+// Spool is an append-only journal of document batches stored in files.
+// Each batch is stored in a segment file and segments are replayed in
+// order.
+type Spool struct {
+	mutex    sync.Mutex
+	dir      string
+	name     string
+	maxSize  int64
+	size     int64
+	segments []spoolSegment
+	seq      uint64
+}
+
+type spoolSegment struct {
+	path string
+	seq  uint64
+	size int64
+}
+
+// NewSpool returns a spool for name in dir, it loads existing segments.
+// If maxSize is zero, spool is unlimited.
+func NewSpool(dir, name string, maxSize int64) (*Spool, error) {
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, fmt.Errorf("creating spool dir: %v", err)
+	}
+	s := &Spool{dir: dir, name: name, maxSize: maxSize}
+	entries, err := filepath.Glob(filepath.Join(dir, name+"-*"+spoolExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range entries {
+		base := strings.TrimSuffix(filepath.Base(path), spoolExt)
+		seq, err := strconv.ParseUint(strings.TrimPrefix(base, name+"-"), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, spoolSegment{path: path, seq: seq, size: info.Size()})
+		s.size += info.Size()
+		if seq > s.seq {
+			s.seq = seq
+		}
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	return s, nil
+}
+
+// Write appends a batch of documents to the spool. The limit is not
+// checked, callers must stop writing when Full returns true.
+func (s *Spool) Write(docs []interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.seq++
+	path := filepath.Join(s.dir, fmt.Sprintf("%s-%020d%s", s.name, s.seq, spoolExt))
+	size, err := writeSegment(path, docs)
+	if err != nil {
+		return err
+	}
+	s.segments = append(s.segments, spoolSegment{path: path, seq: s.seq, size: size})
+	s.size += size
+	return nil
+}
+
+// Replay calls fn with the batches in order. Segments are removed when fn
+// returns no error. If fn fails and retry returns true for the error,
+// replay stops and the segment is kept. Otherwise the segment can't be
+// inserted and it is moved to a quarantine file with extension '.failed',
+// so the replay continues with the next ones.
+func (s *Spool) Replay(fn func(docs []interface{}) error, retry func(error) bool) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replayed := 0
+	quarantined := make([]string, 0)
+	var lastErr error
+	for len(s.segments) > 0 {
+		seg := s.segments[0]
+		docs, err := readSegment(seg.path)
+		if err == nil {
+			err = fn(docs)
+			if err != nil && retry(err) {
+				return replayed, err
+			}
+		} else {
+			err = fmt.Errorf("reading segment: %v", err)
+		}
+		if err != nil {
+			lastErr = err
+			qpath := strings.TrimSuffix(seg.path, spoolExt) + quarantineExt
+			if rerr := os.Rename(seg.path, qpath); rerr != nil {
+				return replayed, rerr
+			}
+			quarantined = append(quarantined, qpath)
+		} else {
+			if rerr := os.Remove(seg.path); rerr != nil {
+				return replayed, rerr
+			}
+			replayed += len(docs)
+		}
+		s.segments = s.segments[1:]
+		s.size -= seg.size
+	}
+	if len(quarantined) > 0 {
+		metrics.SpoolQuarantined.Add(float64(len(quarantined)))
+		return replayed, fmt.Errorf("%v segments moved to quarantine (%s): %v",
+			len(quarantined), strings.Join(quarantined, ", "), lastErr)
+	}
+	return replayed, nil
+}
+
+// Empty returns true if there are no pending batches.
+func (s *Spool) Empty() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.segments) == 0
+}
+
+// Full returns true if spool size exceeds the limit.
+func (s *Spool) Full() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.maxSize > 0 && s.size >= s.maxSize
+}
+
+// segments are a sequence of bson documents prefixed by its length.
+func writeSegment(path string, docs []interface{}) (int64, error) {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(f)
+	var size int64
+	lbuf := make([]byte, 4)
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return 0, fmt.Errorf("encoding bson: %v", err)
+		}
+		binary.BigEndian.PutUint32(lbuf, uint32(len(data)))
+		w.Write(lbuf)
+		w.Write(data)
+		size += int64(len(data) + 4)
+	}
+	err = w.Flush()
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return size, os.Rename(tmp, path)
+}
+
+func readSegment(path string) ([]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	docs := make([]interface{}, 0)
+	lbuf := make([]byte, 4)
+	for {
+		_, err := io.ReadFull(r, lbuf)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(lbuf))
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, bson.Raw{Kind: 0x03, Data: data})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package mongoutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+var (
+	errRetry     = errors.New("retry")
+	errPermanent = errors.New("permanent")
+)
+
+func isRetry(err error) bool { return err == errRetry }
+
+func writeBatches(t *testing.T, s *Spool, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := s.Write([]interface{}{bson.M{"n": i}, bson.M{"n": i}})
+		if err != nil {
+			t.Fatalf("writing batch %v: %v", i, err)
+		}
+	}
+}
+
+func batchN(t *testing.T, docs []interface{}) int {
+	t.Helper()
+	var m bson.M
+	err := docs[0].(bson.Raw).Unmarshal(&m)
+	if err != nil {
+		t.Fatalf("decoding doc: %v", err)
+	}
+	return m["n"].(int)
+}
+
+func TestSpoolReplayOrder(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeBatches(t, s, 3)
+	// a new spool loads the existing segments
+	s, err = NewSpool(dir, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Empty() {
+		t.Fatal("spool is empty")
+	}
+	got := make([]int, 0)
+	n, err := s.Replay(func(docs []interface{}) error {
+		got = append(got, batchN(t, docs))
+		return nil
+	}, isRetry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 6 {
+		t.Errorf("replayed = %v, want 6", n)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Errorf("batch %v = %v", i, v)
+		}
+	}
+	if !s.Empty() {
+		t.Error("spool is not empty after replay")
+	}
+}
+
+func TestSpoolReplayRetry(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeBatches(t, s, 2)
+	_, err = s.Replay(func(docs []interface{}) error { return errRetry }, isRetry)
+	if err != errRetry {
+		t.Fatalf("err = %v, want %v", err, errRetry)
+	}
+	if s.Empty() {
+		t.Fatal("segments removed after retryable error")
+	}
+	n, err := s.Replay(func(docs []interface{}) error { return nil }, isRetry)
+	if err != nil || n != 4 {
+		t.Errorf("replay = %v, %v; want 4, nil", n, err)
+	}
+}
+
+func TestSpoolReplayQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeBatches(t, s, 3)
+	got := make([]int, 0)
+	n, err := s.Replay(func(docs []interface{}) error {
+		v := batchN(t, docs)
+		if v == 1 {
+			return errPermanent
+		}
+		got = append(got, v)
+		return nil
+	}, isRetry)
+	if err == nil {
+		t.Error("expected error reporting quarantine")
+	}
+	if n != 4 || len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("replayed %v docs, batches %v; want 4, [0 2]", n, got)
+	}
+	if !s.Empty() {
+		t.Error("spool is not empty")
+	}
+	failed, _ := filepath.Glob(filepath.Join(dir, "test-*"+quarantineExt))
+	if len(failed) != 1 {
+		t.Fatalf("quarantine files = %v, want 1", failed)
+	}
+	// quarantined segments are not loaded again
+	s, err = NewSpool(dir, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Empty() {
+		t.Error("quarantined segment loaded")
+	}
+	if _, err := os.Stat(failed[0]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSpoolFull(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), "test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Full() {
+		t.Fatal("empty spool is full")
+	}
+	writeBatches(t, s, 1)
+	if !s.Full() {
+		t.Error("spool is not full")
+	}
+	s.Replay(func(docs []interface{}) error { return nil }, isRetry)
+	if s.Full() {
+		t.Error("spool is full after replay")
+	}
+}
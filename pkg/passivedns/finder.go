@@ -0,0 +1,50 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package passivedns defines the interface for finding historical mappings
+// between names and resolved values of archived dns resolutions.
+//
+// This package is a work in progress and makes no API stability promises.
+package passivedns
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Finder is the interface for archive finder passive dns information.
+type Finder interface {
+	ListRecords(ctx context.Context, filter RecordsFilter, max int, next string) ([]Record, string, error)
+}
+
+// Record types.
+const (
+	TypeA     = "A"
+	TypeAAAA  = "AAAA"
+	TypeCNAME = "CNAME"
+)
+
+// Record stores a deduplicated mapping between a name and a resolved
+// value.
+type Record struct {
+	Name                string
+	Type                string
+	Value               string
+	FirstSeen, LastSeen time.Time
+	Count               int64
+}
+
+// RecordsFilter stores filter information. At least one of Name, IP or
+// CNAME is required, if IP is set CNAME is ignored. Since and To select
+// the records seen in the range.
+type RecordsFilter struct {
+	Since, To time.Time
+	Name      string
+	IP        net.IP
+	CNAME     string
+}
+
+// Empty returns true if filter has no name, ip or cname.
+func (f RecordsFilter) Empty() bool {
+	return f.Name == "" && f.IP == nil && f.CNAME == ""
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"net"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/luids-io/archive/pkg/passivedns"
+	"github.com/luids-io/archive/pkg/passivedns/grpc/pb"
+)
+
+// Record copy info from pb
+func Record(src *pb.Record, dst *passivedns.Record) (err error) {
+	dst.Name = src.GetName()
+	dst.Type = src.GetType()
+	dst.Value = src.GetValue()
+	dst.Count = src.GetCount()
+	if src.FirstSeen != nil {
+		dst.FirstSeen, err = ptypes.Timestamp(src.GetFirstSeen())
+		if err != nil {
+			return
+		}
+	}
+	if src.LastSeen != nil {
+		dst.LastSeen, err = ptypes.Timestamp(src.GetLastSeen())
+	}
+	return
+}
+
+// RecordPB copy info to pb
+func RecordPB(src *passivedns.Record, dst *pb.Record) (err error) {
+	dst.Name = src.Name
+	dst.Type = src.Type
+	dst.Value = src.Value
+	dst.Count = src.Count
+	if !src.FirstSeen.IsZero() {
+		dst.FirstSeen, err = ptypes.TimestampProto(src.FirstSeen)
+		if err != nil {
+			return
+		}
+	}
+	if !src.LastSeen.IsZero() {
+		dst.LastSeen, err = ptypes.TimestampProto(src.LastSeen)
+	}
+	return
+}
+
+// RecordsFilter copy info from pb
+func RecordsFilter(src *pb.RecordsFilter, dst *passivedns.RecordsFilter) (err error) {
+	if src.Since != nil {
+		dst.Since, _ = ptypes.Timestamp(src.GetSince())
+	}
+	if src.To != nil {
+		dst.To, _ = ptypes.Timestamp(src.GetTo())
+	}
+	dst.Name = src.GetName()
+	dst.IP = net.ParseIP(src.GetIp())
+	dst.CNAME = src.GetCname()
+	return
+}
+
+// RecordsFilterPB copy info to pb
+func RecordsFilterPB(src *passivedns.RecordsFilter, dst *pb.RecordsFilter) (err error) {
+	if !src.Since.IsZero() {
+		dst.Since, _ = ptypes.TimestampProto(src.Since)
+	}
+	if !src.To.IsZero() {
+		dst.To, _ = ptypes.TimestampProto(src.To)
+	}
+	dst.Name = src.Name
+	if src.IP != nil {
+		dst.Ip = src.IP.String()
+	}
+	dst.Cname = src.CNAME
+	return
+}
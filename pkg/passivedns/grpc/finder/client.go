@@ -0,0 +1,107 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/passivedns"
+	"github.com/luids-io/archive/pkg/passivedns/grpc/encoding"
+	"github.com/luids-io/archive/pkg/passivedns/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Client provides a grpc client.
+type Client struct {
+	logger yalogi.Logger
+	//grpc connection
+	conn   *grpcdns.Conn
+	client pb.FinderClient
+}
+
+// ClientOption encapsules options for client.
+type ClientOption = grpcdns.ClientOption
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return grpcdns.CloseConnection(b)
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return grpcdns.SetLogger(l)
+}
+
+// NewClient returns a new client.
+func NewClient(conn *grpc.ClientConn, opt ...ClientOption) *Client {
+	opts := grpcdns.NewClientOpts(opt...)
+	return &Client{
+		logger: opts.Logger,
+		conn:   grpcdns.NewConn(conn, opts),
+		client: pb.NewFinderClient(conn),
+	}
+}
+
+// ListRecords implements passivedns.Finder interface
+func (c *Client) ListRecords(ctx context.Context, filter passivedns.RecordsFilter, max int, next string) ([]passivedns.Record, string, error) {
+	if c.conn.Closed() {
+		c.logger.Warnf("client.dnsutil.passivedns: listrecords(): client is closed")
+		return nil, "", dnsutil.ErrUnavailable
+	}
+	if filter.Empty() {
+		c.logger.Warnf("client.dnsutil.passivedns: listrecords(): name, ip or cname is required")
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	if max < 0 {
+		c.logger.Warnf("client.dnsutil.passivedns: listrecords(): invalid max")
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	//create request
+	req := &pb.ListRecordsRequest{
+		Max:    int32(max),
+		Next:   next,
+		Filter: &pb.RecordsFilter{},
+	}
+	err := encoding.RecordsFilterPB(&filter, req.Filter)
+	if err != nil {
+		c.logger.Warnf("client.dnsutil.passivedns: listrecords(): bad filter: %v", err)
+		return nil, "", dnsutil.ErrBadRequest
+	}
+	//do list
+	resp, err := c.client.ListRecords(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.dnsutil.passivedns: listrecords(): %v", err)
+		return nil, "", grpcdns.ClientError(err)
+	}
+	//process response
+	data := make([]passivedns.Record, 0, len(resp.GetData()))
+	for _, rpb := range resp.GetData() {
+		var r passivedns.Record
+		err := encoding.Record(rpb, &r)
+		if err != nil {
+			c.logger.Errorf("client.dnsutil.passivedns: listrecords(): decoding record: %v", err)
+			return nil, "", dnsutil.ErrInternal
+		}
+		data = append(data, r)
+	}
+	return data, resp.GetNext(), nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Ping checks connectivity with the api
+func (c *Client) Ping() error {
+	return c.conn.Ping()
+}
+
+// API returns API service name implemented
+func (c *Client) API() string {
+	return ServiceName()
+}
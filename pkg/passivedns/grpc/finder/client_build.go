@@ -0,0 +1,26 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/core/apiservice"
+	"github.com/luids-io/core/yalogi"
+)
+
+// ClientBuilder returns builder function for the apiservice
+func ClientBuilder(opt ...ClientOption) apiservice.BuildFn {
+	return grpcdns.ClientBuilder(func(conn *grpc.ClientConn, logger yalogi.Logger) apiservice.Service {
+		copt := append([]ClientOption{}, opt...)
+		if logger != nil {
+			copt = append(copt, SetLogger(logger))
+		}
+		return NewClient(conn, copt...)
+	})
+}
+
+func init() {
+	apiservice.RegisterBuilder(ServiceName(), ClientBuilder())
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/luids-io/api/dnsutil"
+	"github.com/luids-io/archive/internal/grpcdns"
+	"github.com/luids-io/archive/pkg/passivedns"
+	"github.com/luids-io/archive/pkg/passivedns/grpc/encoding"
+	"github.com/luids-io/archive/pkg/passivedns/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Service implements a grpc service wrapper.
+type Service struct {
+	logger yalogi.Logger
+	finder passivedns.Finder
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption = grpcdns.ServiceOption
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return grpcdns.SetServiceLogger(l)
+}
+
+// NewService returns a new Service.
+func NewService(f passivedns.Finder, opt ...ServiceOption) *Service {
+	opts := grpcdns.NewServiceOpts(opt...)
+	return &Service{finder: f, logger: opts.Logger}
+}
+
+// RegisterServer registers a service in the grpc server.
+func RegisterServer(server *grpc.Server, service *Service) {
+	pb.RegisterFinderServer(server, service)
+}
+
+// ListRecords implements grpc interface.
+func (s *Service) ListRecords(ctx context.Context, req *pb.ListRecordsRequest) (*pb.ListRecordsResponse, error) {
+	// get request
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.dnsutil.passivedns: [peer=%s] listrecords(): bad max", grpcdns.PeerAddr(ctx))
+		return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+	}
+	var filter passivedns.RecordsFilter
+	if req.GetFilter() != nil {
+		err := encoding.RecordsFilter(req.GetFilter(), &filter)
+		if err != nil {
+			s.logger.Warnf("service.dnsutil.passivedns: [peer=%s] listrecords(): bad filter: %v", grpcdns.PeerAddr(ctx), err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+		}
+	}
+	if filter.Empty() {
+		s.logger.Warnf("service.dnsutil.passivedns: [peer=%s] listrecords(): name, ip or cname is required", grpcdns.PeerAddr(ctx))
+		return nil, grpcdns.ServiceError(dnsutil.ErrBadRequest)
+	}
+	//do list
+	data, next, err := s.finder.ListRecords(ctx, filter, max, req.GetNext())
+	if err != nil {
+		s.logger.Warnf("service.dnsutil.passivedns: [peer=%s] listrecords(): %v", grpcdns.PeerAddr(ctx), err)
+		return nil, grpcdns.ServiceError(err)
+	}
+	//prepare response
+	resp := &pb.ListRecordsResponse{
+		Next: next,
+		Data: make([]*pb.Record, 0, len(data)),
+	}
+	for _, r := range data {
+		rpb := &pb.Record{}
+		err := encoding.RecordPB(&r, rpb)
+		if err != nil {
+			s.logger.Errorf("service.dnsutil.passivedns: [peer=%s] listrecords(): encoding record: %v", grpcdns.PeerAddr(ctx), err)
+			return nil, grpcdns.ServiceError(dnsutil.ErrInternal)
+		}
+		resp.Data = append(resp.Data, rpb)
+	}
+	return resp, nil
+}
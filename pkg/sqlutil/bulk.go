@@ -0,0 +1,149 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+// Package sqlutil provides utilities for sql backends.
+//
+// This package is a work in progress and makes no API stability promises.
+package sqlutil
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/luids-io/archive/pkg/archive/metrics"
+)
+
+// MaxPendingBatches is the number of batches a bulk keeps while the
+// database is unavailable.
+const MaxPendingBatches = 10
+
+// ErrBulkFull is returned when the database is unavailable and the bulk
+// can't keep more rows.
+var ErrBulkFull = errors.New("bulk is full")
+
+// Bulk is used for massive inserts, it uses postgresql copy protocol.
+type Bulk struct {
+	mutex   sync.Mutex
+	db      *sql.DB
+	table   string
+	columns []string
+	rows    [][]interface{}
+	size    int
+	// next copy is tried when rows reach next
+	next int
+}
+
+// NewBulk returns a new bulk for table and columns with size.
+func NewBulk(db *sql.DB, table string, columns []string, size int) *Bulk {
+	bk := &Bulk{
+		db:      db,
+		table:   table,
+		columns: columns,
+		rows:    make([][]interface{}, 0, size),
+		size:    size,
+		next:    size,
+	}
+	return bk
+}
+
+// Insert a row in the bulk, values must be in the order of columns. If the
+// database is unavailable the row is kept and it returns nil, unless the
+// bulk has MaxPendingBatches batches pending, then it returns ErrBulkFull.
+func (bk *Bulk) Insert(values ...interface{}) error {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+
+	if len(bk.rows) >= bk.size*MaxPendingBatches {
+		return ErrBulkFull
+	}
+	bk.rows = append(bk.rows, values)
+	if len(bk.rows) >= bk.next {
+		err := bk.run()
+		if err != nil && IsUnavailable(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Flush bulk
+func (bk *Bulk) Flush() error {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+
+	if len(bk.rows) > 0 {
+		return bk.run()
+	}
+	return nil
+}
+
+// Pending returns the number of rows not copied yet.
+func (bk *Bulk) Pending() int {
+	bk.mutex.Lock()
+	defer bk.mutex.Unlock()
+	return len(bk.rows)
+}
+
+// run copies the rows. If copy fails because the database is unavailable
+// the rows are kept and copied again in the next run, that is tried when a
+// new batch is completed or on flush. Other errors are permanent and the
+// rows are discarded: the transaction is rolled back, so none of them is
+// stored.
+func (bk *Bulk) run() error {
+	start := time.Now()
+	err := bk.copy()
+	if err != nil {
+		metrics.BulkFlushErrors.WithLabelValues(bk.table).Inc()
+		if IsUnavailable(err) {
+			bk.next = len(bk.rows) + bk.size
+			return err
+		}
+		bk.reset()
+		return err
+	}
+	defer bk.reset()
+	metrics.BulkFlushDuration.WithLabelValues(bk.table).Observe(time.Since(start).Seconds())
+	metrics.BulkFlushSize.WithLabelValues(bk.table).Observe(float64(len(bk.rows)))
+	return nil
+}
+
+func (bk *Bulk) copy() error {
+	tx, err := bk.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(pq.CopyIn(bk.table, bk.columns...))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, row := range bk.rows {
+		_, err = stmt.Exec(row...)
+		if err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	_, err = stmt.Exec()
+	if err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return err
+	}
+	err = stmt.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (bk *Bulk) reset() {
+	bk.rows = make([][]interface{}, 0, bk.size)
+	bk.next = bk.size
+}
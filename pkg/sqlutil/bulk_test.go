@@ -0,0 +1,178 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package sqlutil
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"syscall"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+var copyStmt = regexp.QuoteMeta(`COPY "items" ("id", "name") FROM STDIN`)
+
+func expectCopy(mock sqlmock.Sqlmock, rows [][]interface{}, err error) {
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(copyStmt)
+	for _, row := range rows {
+		args := make([]driver.Value, 0, len(row))
+		for _, v := range row {
+			args = append(args, v)
+		}
+		prep.ExpectExec().WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	if err != nil {
+		prep.ExpectExec().WithArgs().WillReturnError(err)
+		mock.ExpectRollback()
+		return
+	}
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, int64(len(rows))))
+	mock.ExpectCommit()
+}
+
+func TestBulkInsert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	bk := NewBulk(db, "items", []string{"id", "name"}, 2)
+	expectCopy(mock, [][]interface{}{{1, "a"}, {2, "b"}}, nil)
+	expectCopy(mock, [][]interface{}{{3, "c"}}, nil)
+	for i, name := range []string{"a", "b", "c"} {
+		err := bk.Insert(i+1, name)
+		if err != nil {
+			t.Fatalf("Insert(): %v", err)
+		}
+	}
+	if err := bk.Flush(); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+	// nothing to flush
+	if err := bk.Flush(); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBulkReset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	bk := NewBulk(db, "items", []string{"id", "name"}, 2)
+	errCopy := errors.New("copy failed")
+	expectCopy(mock, [][]interface{}{{1, "a"}, {2, "b"}}, errCopy)
+	// failed rows are not copied again
+	expectCopy(mock, [][]interface{}{{3, "c"}}, nil)
+	bk.Insert(1, "a")
+	if err := bk.Insert(2, "b"); err != errCopy {
+		t.Fatalf("Insert() err = %v, want %v", err, errCopy)
+	}
+	bk.Insert(3, "c")
+	if err := bk.Flush(); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBulkUnavailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	bk := NewBulk(db, "items", []string{"id", "name"}, 2)
+	errConn := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	expectCopy(mock, [][]interface{}{{1, "a"}, {2, "b"}}, errConn)
+	// rows are kept and copied with the next batch
+	expectCopy(mock, [][]interface{}{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}}, nil)
+	for i, name := range []string{"a", "b", "c"} {
+		if err := bk.Insert(i+1, name); err != nil {
+			t.Fatalf("Insert(): %v", err)
+		}
+	}
+	if got := bk.Pending(); got != 3 {
+		t.Errorf("Pending() = %v, want 3", got)
+	}
+	if err := bk.Insert(4, "d"); err != nil {
+		t.Fatalf("Insert(): %v", err)
+	}
+	if got := bk.Pending(); got != 0 {
+		t.Errorf("Pending() = %v, want 0", got)
+	}
+	// flush returns the error and keeps the rows
+	expectCopy(mock, [][]interface{}{{5, "e"}}, errConn)
+	bk.Insert(5, "e")
+	if err := bk.Flush(); err != errConn {
+		t.Fatalf("Flush() err = %v, want %v", err, errConn)
+	}
+	if got := bk.Pending(); got != 1 {
+		t.Errorf("Pending() = %v, want 1", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBulkFull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New(): %v", err)
+	}
+	defer db.Close()
+
+	bk := NewBulk(db, "items", []string{"id", "name"}, 1)
+	errConn := &pq.Error{Code: "08006"}
+	for i := 0; i < MaxPendingBatches; i++ {
+		// copy fails in the first row
+		expectCopy(mock, nil, errConn)
+		if err := bk.Insert(i, "x"); err != nil {
+			t.Fatalf("Insert(%v): %v", i, err)
+		}
+	}
+	if err := bk.Insert(MaxPendingBatches, "x"); err != ErrBulkFull {
+		t.Errorf("Insert() err = %v, want %v", err, ErrBulkFull)
+	}
+	if got := bk.Pending(); got != MaxPendingBatches {
+		t.Errorf("Pending() = %v, want %v", got, MaxPendingBatches)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("syntax error"), false},
+		{&pq.Error{Code: "23505"}, false},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "57P01"}, true},
+		{driver.ErrBadConn, true},
+		{io.ErrUnexpectedEOF, true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+	}
+	for _, tt := range tests {
+		if got := IsUnavailable(tt.err); got != tt.want {
+			t.Errorf("IsUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package sqlutil
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrationsTable stores the schema version of each component.
+const MigrationsTable = "schema_migrations"
+
+// Migrate applies the pending migrations of component. Each migration is
+// executed in a transaction and its position in the slice is the version.
+func Migrate(db *sql.DB, component string, migrations []string) error {
+	_, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		component TEXT PRIMARY KEY,
+		version INTEGER NOT NULL,
+		updated TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`, MigrationsTable))
+	if err != nil {
+		return fmt.Errorf("creating migrations table: %v", err)
+	}
+	for {
+		applied, err := migrateNext(db, component, migrations)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return nil
+		}
+	}
+}
+
+func migrateNext(db *sql.DB, component string, migrations []string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	// lock row to avoid concurrent migrations
+	_, err = tx.Exec(fmt.Sprintf(`INSERT INTO %s (component, version) VALUES ($1, 0)
+		ON CONFLICT (component) DO NOTHING`, MigrationsTable), component)
+	if err != nil {
+		return false, fmt.Errorf("getting version: %v", err)
+	}
+	var version int
+	err = tx.QueryRow(fmt.Sprintf(`SELECT version FROM %s WHERE component = $1 FOR UPDATE`,
+		MigrationsTable), component).Scan(&version)
+	if err != nil {
+		return false, fmt.Errorf("getting version: %v", err)
+	}
+	if version >= len(migrations) {
+		return false, nil
+	}
+	_, err = tx.Exec(migrations[version])
+	if err != nil {
+		return false, fmt.Errorf("applying migration %v: %v", version+1, err)
+	}
+	_, err = tx.Exec(fmt.Sprintf(`UPDATE %s SET version = $1, updated = now() WHERE component = $2`,
+		MigrationsTable), version+1, component)
+	if err != nil {
+		return false, fmt.Errorf("updating version: %v", err)
+	}
+	return true, tx.Commit()
+}
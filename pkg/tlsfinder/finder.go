@@ -0,0 +1,31 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. View LICENSE.
+
+// Package tlsfinder defines the interface for finding archived tls
+// connections, certificates and records.
+//
+// This package is a work in progress and makes no API stability promises.
+package tlsfinder
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/luids-io/api/tlsutil"
+)
+
+// Finder is the interface for archive finder tls information.
+type Finder interface {
+	GetConnection(ctx context.Context, id string) (*tlsutil.ConnectionData, bool, error)
+	ListConnections(ctx context.Context, filters []ConnectionsFilter, rev bool, max int, next string) ([]*tlsutil.ConnectionData, string, error)
+	GetCertificate(ctx context.Context, digest string) (*tlsutil.CertificateData, bool, error)
+	ListRecords(ctx context.Context, connID string, max int, next string) ([]*tlsutil.RecordData, string, error)
+}
+
+// ConnectionsFilter stores filter information. Empty fields are ignored.
+type ConnectionsFilter struct {
+	Since, To      time.Time
+	Client, Server net.IP
+	SNI            string
+	JA3Digest      string
+}
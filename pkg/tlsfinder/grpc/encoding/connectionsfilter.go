@@ -0,0 +1,46 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package encoding
+
+import (
+	"net"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/luids-io/archive/pkg/tlsfinder"
+	"github.com/luids-io/archive/pkg/tlsfinder/grpc/pb"
+)
+
+// ConnectionsFilter copy info from pb
+func ConnectionsFilter(src *pb.ConnectionsFilter, dst *tlsfinder.ConnectionsFilter) (err error) {
+	if src.Since != nil {
+		dst.Since, _ = ptypes.Timestamp(src.GetSince())
+	}
+	if src.To != nil {
+		dst.To, _ = ptypes.Timestamp(src.GetTo())
+	}
+	dst.Client = net.ParseIP(src.GetClientIp())
+	dst.Server = net.ParseIP(src.GetServerIp())
+	dst.SNI = src.GetSni()
+	dst.JA3Digest = src.GetJa3Digest()
+	return
+}
+
+// ConnectionsFilterPB copy info to pb
+func ConnectionsFilterPB(src *tlsfinder.ConnectionsFilter, dst *pb.ConnectionsFilter) (err error) {
+	if !src.Since.IsZero() {
+		dst.Since, _ = ptypes.TimestampProto(src.Since)
+	}
+	if !src.To.IsZero() {
+		dst.To, _ = ptypes.TimestampProto(src.To)
+	}
+	if src.Client != nil {
+		dst.ClientIp = src.Client.String()
+	}
+	if src.Server != nil {
+		dst.ServerIp = src.Server.String()
+	}
+	dst.Sni = src.SNI
+	dst.Ja3Digest = src.JA3Digest
+	return
+}
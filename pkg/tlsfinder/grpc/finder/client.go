@@ -0,0 +1,270 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+
+	"github.com/luids-io/api/tlsutil"
+	tlsencoding "github.com/luids-io/api/tlsutil/grpc/encoding"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+	"github.com/luids-io/archive/pkg/tlsfinder/grpc/encoding"
+	"github.com/luids-io/archive/pkg/tlsfinder/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Client provides a grpc client.
+type Client struct {
+	opts   clientOpts
+	logger yalogi.Logger
+	//grpc connection
+	conn   *grpc.ClientConn
+	client pb.FinderClient
+	//control
+	closed bool
+}
+
+// ClientOption encapsules options for client.
+type ClientOption func(*clientOpts)
+
+type clientOpts struct {
+	logger    yalogi.Logger
+	closeConn bool
+}
+
+var defaultClientOpts = clientOpts{
+	logger:    yalogi.LogNull,
+	closeConn: true,
+}
+
+// CloseConnection option closes grpc connection on shutdown.
+func CloseConnection(b bool) ClientOption {
+	return func(o *clientOpts) {
+		o.closeConn = b
+	}
+}
+
+// SetLogger option allows set a custom logger.
+func SetLogger(l yalogi.Logger) ClientOption {
+	return func(o *clientOpts) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// NewClient returns a new client.
+func NewClient(conn *grpc.ClientConn, opt ...ClientOption) *Client {
+	opts := defaultClientOpts
+	for _, o := range opt {
+		o(&opts)
+	}
+	return &Client{
+		opts:   opts,
+		logger: opts.logger,
+		conn:   conn,
+		client: pb.NewFinderClient(conn),
+	}
+}
+
+// GetConnection implements tlsfinder.Finder interface
+func (c *Client) GetConnection(ctx context.Context, id string) (*tlsutil.ConnectionData, bool, error) {
+	if c.closed {
+		c.logger.Warnf("client.tlsutil.finder: getconnection(%s): client is closed", id)
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//check req
+	if id == "" {
+		c.logger.Warnf("client.tlsutil.finder: getconnection(): id is empty")
+		return nil, false, tlsutil.ErrBadRequest
+	}
+	//do req
+	resp, err := c.client.GetConnection(ctx, &pb.GetConnectionRequest{Id: id})
+	if errNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		c.logger.Warnf("client.tlsutil.finder: getconnection(%s): %v", id, err)
+		return nil, false, c.mapError(err)
+	}
+	cnpb := resp.GetData()
+	if cnpb == nil {
+		c.logger.Errorf("client.tlsutil.finder: getconnection(%s): unexpected data empty", id)
+		return nil, false, tlsutil.ErrInternal
+	}
+	return tlsencoding.ConnectionData(cnpb), true, nil
+}
+
+// ListConnections implements tlsfinder.Finder interface
+func (c *Client) ListConnections(ctx context.Context, filters []tlsfinder.ConnectionsFilter,
+	rev bool, max int, next string) ([]*tlsutil.ConnectionData, string, error) {
+	if c.closed {
+		c.logger.Warnf("client.tlsutil.finder: listconnections(): client is closed")
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	if max < 0 {
+		c.logger.Warnf("client.tlsutil.finder: listconnections(): invalid max")
+		return nil, "", tlsutil.ErrBadRequest
+	}
+	//create request
+	req := &pb.ListConnectionsRequest{
+		Max:     int32(max),
+		Next:    next,
+		Reverse: rev,
+		Filters: make([]*pb.ConnectionsFilter, 0, len(filters)),
+	}
+	for _, f := range filters {
+		fpb := &pb.ConnectionsFilter{}
+		err := encoding.ConnectionsFilterPB(&f, fpb)
+		if err != nil {
+			c.logger.Warnf("client.tlsutil.finder: listconnections(): bad filter: %v", err)
+			return nil, "", tlsutil.ErrBadRequest
+		}
+		req.Filters = append(req.Filters, fpb)
+	}
+	//do list
+	resp, err := c.client.ListConnections(ctx, req)
+	if err != nil {
+		c.logger.Warnf("client.tlsutil.finder: listconnections(): %v", err)
+		return nil, "", c.mapError(err)
+	}
+	//process response
+	data := make([]*tlsutil.ConnectionData, 0, len(resp.GetData()))
+	for _, cnpb := range resp.GetData() {
+		data = append(data, tlsencoding.ConnectionData(cnpb))
+	}
+	return data, resp.GetNext(), nil
+}
+
+// GetCertificate implements tlsfinder.Finder interface
+func (c *Client) GetCertificate(ctx context.Context, digest string) (*tlsutil.CertificateData, bool, error) {
+	if c.closed {
+		c.logger.Warnf("client.tlsutil.finder: getcertificate(%s): client is closed", digest)
+		return nil, false, tlsutil.ErrUnavailable
+	}
+	//check req
+	if digest == "" {
+		c.logger.Warnf("client.tlsutil.finder: getcertificate(): digest is empty")
+		return nil, false, tlsutil.ErrBadRequest
+	}
+	//do req
+	resp, err := c.client.GetCertificate(ctx, &pb.GetCertificateRequest{Digest: digest})
+	if errNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		c.logger.Warnf("client.tlsutil.finder: getcertificate(%s): %v", digest, err)
+		return nil, false, c.mapError(err)
+	}
+	certpb := resp.GetData()
+	if certpb == nil {
+		c.logger.Errorf("client.tlsutil.finder: getcertificate(%s): unexpected data empty", digest)
+		return nil, false, tlsutil.ErrInternal
+	}
+	cert := tlsencoding.CertificateData(certpb)
+	cert.ID = resp.GetId()
+	return cert, true, nil
+}
+
+// ListRecords implements tlsfinder.Finder interface
+func (c *Client) ListRecords(ctx context.Context, connID string, max int, next string) ([]*tlsutil.RecordData, string, error) {
+	if c.closed {
+		c.logger.Warnf("client.tlsutil.finder: listrecords(%s): client is closed", connID)
+		return nil, "", tlsutil.ErrUnavailable
+	}
+	if connID == "" {
+		c.logger.Warnf("client.tlsutil.finder: listrecords(): connection id is empty")
+		return nil, "", tlsutil.ErrBadRequest
+	}
+	if max < 0 {
+		c.logger.Warnf("client.tlsutil.finder: listrecords(%s): invalid max", connID)
+		return nil, "", tlsutil.ErrBadRequest
+	}
+	//do list
+	resp, err := c.client.ListRecords(ctx, &pb.ListRecordsRequest{ConnectionId: connID, Max: int32(max), Next: next})
+	if err != nil {
+		c.logger.Warnf("client.tlsutil.finder: listrecords(%s): %v", connID, err)
+		return nil, "", c.mapError(err)
+	}
+	//process response
+	data := make([]*tlsutil.RecordData, 0, len(resp.GetData()))
+	for _, rpb := range resp.GetData() {
+		data = append(data, tlsencoding.RecordData(rpb))
+	}
+	return data, resp.GetNext(), nil
+}
+
+//mapping errors.
+func (c *Client) mapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Canceled:
+		return tlsutil.ErrCanceledRequest
+	case codes.InvalidArgument:
+		return tlsutil.ErrBadRequest
+	case codes.Unimplemented:
+		return tlsutil.ErrNotSupported
+	case codes.Internal:
+		return tlsutil.ErrInternal
+	case codes.Unavailable:
+		return tlsutil.ErrUnavailable
+	default:
+		return tlsutil.ErrUnavailable
+	}
+}
+
+func errNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	if st.Code() == codes.NotFound {
+		return true
+	}
+	return false
+}
+
+//Close closes the client
+func (c *Client) Close() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	c.closed = true
+	if c.opts.closeConn {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// Ping checks connectivity with the api
+func (c *Client) Ping() error {
+	if c.closed {
+		return errors.New("client closed")
+	}
+	st := c.conn.GetState()
+	switch st {
+	case connectivity.TransientFailure:
+		return fmt.Errorf("connection state: %v", st)
+	case connectivity.Shutdown:
+		return fmt.Errorf("connection state: %v", st)
+	}
+	return nil
+}
+
+//API returns API service name implemented
+func (c *Client) API() string {
+	return ServiceName()
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Luis Guillén Civera <luisguillenc@gmail.com>. See LICENSE.
+
+package finder
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/luids-io/api/tlsutil"
+	tlsencoding "github.com/luids-io/api/tlsutil/grpc/encoding"
+	tlspb "github.com/luids-io/api/tlsutil/grpc/pb"
+	"github.com/luids-io/archive/pkg/tlsfinder"
+	"github.com/luids-io/archive/pkg/tlsfinder/grpc/encoding"
+	"github.com/luids-io/archive/pkg/tlsfinder/grpc/pb"
+	"github.com/luids-io/core/yalogi"
+)
+
+// Service implements a grpc service wrapper.
+type Service struct {
+	logger yalogi.Logger
+	finder tlsfinder.Finder
+}
+
+// ServiceOption is used for service configuration.
+type ServiceOption func(*serviceOpts)
+
+type serviceOpts struct {
+	logger yalogi.Logger
+}
+
+var defaultServiceOpts = serviceOpts{logger: yalogi.LogNull}
+
+// SetServiceLogger option allows set a custom logger.
+func SetServiceLogger(l yalogi.Logger) ServiceOption {
+	return func(o *serviceOpts) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// NewService returns a new Service.
+func NewService(f tlsfinder.Finder, opt ...ServiceOption) *Service {
+	opts := defaultServiceOpts
+	for _, o := range opt {
+		o(&opts)
+	}
+	return &Service{finder: f, logger: opts.logger}
+}
+
+// RegisterServer registers a service in the grpc server.
+func RegisterServer(server *grpc.Server, service *Service) {
+	pb.RegisterFinderServer(server, service)
+}
+
+// GetConnection implements grpc interface.
+func (s *Service) GetConnection(ctx context.Context, req *pb.GetConnectionRequest) (*pb.GetConnectionResponse, error) {
+	id := req.GetId()
+	if id == "" {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] getconnection(): id is empty", getPeerAddr(ctx))
+		return nil, s.mapError(tlsutil.ErrBadRequest)
+	}
+	cn, exists, err := s.finder.GetConnection(ctx, id)
+	if err != nil {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] getconnection(%s): %v", getPeerAddr(ctx), id, err)
+		return nil, s.mapError(err)
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "connection not found")
+	}
+	return &pb.GetConnectionResponse{Data: tlsencoding.ConnectionDataPB(cn)}, nil
+}
+
+// ListConnections implements grpc interface.
+func (s *Service) ListConnections(ctx context.Context, req *pb.ListConnectionsRequest) (*pb.ListConnectionsResponse, error) {
+	// get request
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] listconnections(): bad max", getPeerAddr(ctx))
+		return nil, s.mapError(tlsutil.ErrBadRequest)
+	}
+	filters := make([]tlsfinder.ConnectionsFilter, 0, len(req.GetFilters()))
+	for _, cfpb := range req.GetFilters() {
+		var cf tlsfinder.ConnectionsFilter
+		err := encoding.ConnectionsFilter(cfpb, &cf)
+		if err != nil {
+			s.logger.Warnf("service.tlsutil.finder: [peer=%s] listconnections(): bad filter: %v", getPeerAddr(ctx), err)
+			return nil, s.mapError(tlsutil.ErrBadRequest)
+		}
+		filters = append(filters, cf)
+	}
+	//do list
+	data, next, err := s.finder.ListConnections(ctx, filters, req.GetReverse(), max, req.GetNext())
+	if err != nil {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] listconnections(): %v", getPeerAddr(ctx), err)
+		return nil, s.mapError(err)
+	}
+	//prepare response
+	resp := &pb.ListConnectionsResponse{
+		Next: next,
+		Data: make([]*tlspb.ConnectionData, 0, len(data)),
+	}
+	for _, cn := range data {
+		resp.Data = append(resp.Data, tlsencoding.ConnectionDataPB(cn))
+	}
+	return resp, nil
+}
+
+// GetCertificate implements grpc interface.
+func (s *Service) GetCertificate(ctx context.Context, req *pb.GetCertificateRequest) (*pb.GetCertificateResponse, error) {
+	digest := req.GetDigest()
+	if digest == "" {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] getcertificate(): digest is empty", getPeerAddr(ctx))
+		return nil, s.mapError(tlsutil.ErrBadRequest)
+	}
+	cert, exists, err := s.finder.GetCertificate(ctx, digest)
+	if err != nil {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] getcertificate(%s): %v", getPeerAddr(ctx), digest, err)
+		return nil, s.mapError(err)
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "certificate not found")
+	}
+	return &pb.GetCertificateResponse{Data: tlsencoding.CertificateDataPB(cert), Id: cert.ID}, nil
+}
+
+// ListRecords implements grpc interface.
+func (s *Service) ListRecords(ctx context.Context, req *pb.ListRecordsRequest) (*pb.ListRecordsResponse, error) {
+	// get request
+	connID := req.GetConnectionId()
+	if connID == "" {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] listrecords(): connection id is empty", getPeerAddr(ctx))
+		return nil, s.mapError(tlsutil.ErrBadRequest)
+	}
+	max := int(req.GetMax())
+	if max < 0 {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] listrecords(%s): bad max", getPeerAddr(ctx), connID)
+		return nil, s.mapError(tlsutil.ErrBadRequest)
+	}
+	//do list
+	data, next, err := s.finder.ListRecords(ctx, connID, max, req.GetNext())
+	if err != nil {
+		s.logger.Warnf("service.tlsutil.finder: [peer=%s] listrecords(%s): %v", getPeerAddr(ctx), connID, err)
+		return nil, s.mapError(err)
+	}
+	//prepare response
+	resp := &pb.ListRecordsResponse{
+		Next: next,
+		Data: make([]*tlspb.RecordData, 0, len(data)),
+	}
+	for _, r := range data {
+		resp.Data = append(resp.Data, tlsencoding.RecordDataPB(r))
+	}
+	return resp, nil
+}
+
+//mapping errors
+func (s *Service) mapError(err error) error {
+	switch err {
+	case tlsutil.ErrCanceledRequest:
+		return status.Error(codes.Canceled, err.Error())
+	case tlsutil.ErrBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case tlsutil.ErrNotSupported:
+		return status.Error(codes.Unimplemented, err.Error())
+	case tlsutil.ErrUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, tlsutil.ErrInternal.Error())
+	}
+}
+
+func getPeerAddr(ctx context.Context) (paddr string) {
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		paddr = p.Addr.String()
+	}
+	return
+}
@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# builds the go code of the protos in the schemas dir, or only the protos
+# passed as arguments
+
+# gets script dir
+SRCDIR=$(dirname $(readlink -f "$0"))
+
+# sets path for tools
+GOPATH="${GOPATH:-$HOME/go}"
+PATH=$GOPATH/bin:$PATH
+
+PROTOS=("$@")
+if [ ${#PROTOS[@]} -eq 0 ]; then
+	PROTOS=($SRCDIR/*/*.proto)
+fi
+
+for proto in "${PROTOS[@]}"; do
+	protoc -I $GOPATH/src -I . $proto --go_out=plugins=grpc:$GOPATH/src || exit 1
+done